@@ -0,0 +1,252 @@
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/joint"
+	"github.com/alexander-yu/stream/moment"
+)
+
+// FamilyMetric is the interface for a metric that can be tracked by a Family;
+// this is satisfied by every metric, including ones without a single value
+// (e.g. Quantile and Summary), since the Family reads values with a function.
+type FamilyMetric interface {
+	String() string
+	Clear()
+}
+
+// LabeledValue is the value of a metric in a Family, along with its labels.
+type LabeledValue struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// familyMember is a metric in a Family, along with its label values.
+type familyMember[M FamilyMetric] struct {
+	labelValues []string
+	metric      M
+}
+
+// Family is a set of metrics of the same kind, with one metric for each distinct
+// set of label values (e.g. one metric per endpoint and status code). Metrics
+// are lazily instantiated with a factory the first time their label values are seen.
+type Family[M FamilyMetric] struct {
+	labels         []string
+	factory        func() (M, error)
+	value          func(M) (float64, error)
+	maxCardinality int
+	members        map[string]*familyMember[M]
+	mux            sync.RWMutex
+}
+
+// NewFamily instantiates a Family struct with the provided label names, where
+// new metrics are created by calling the factory, and Values reads the value of
+// each metric by calling value; for metrics with a Value method, this can simply
+// be the method expression, e.g. (*moment.Mean).Value, and otherwise it can pick
+// out a single value, e.g. the 0.99 quantile of a Quantile. Metrics from the
+// moment and joint packages are automatically set up with moment.Init or
+// joint.Init, so the factory does not need to do so. If maxCardinality is positive,
+// then no more than that many metrics are created, so that a label with unbounded
+// values (e.g. a user ID) cannot use up unbounded memory.
+func NewFamily[M FamilyMetric](
+	labels []string,
+	maxCardinality int,
+	factory func() (M, error),
+	value func(M) (float64, error),
+) (*Family[M], error) {
+	if len(labels) == 0 {
+		return nil, errors.New("no labels provided")
+	} else if maxCardinality < 0 {
+		return nil, errors.Errorf("%d is a negative max cardinality", maxCardinality)
+	} else if value == nil {
+		return nil, errors.New("no value function provided")
+	}
+
+	seen := map[string]bool{}
+	for _, label := range labels {
+		if seen[label] {
+			return nil, errors.Errorf("label %s is provided more than once", label)
+		}
+		seen[label] = true
+	}
+
+	return &Family[M]{
+		labels:         append([]string{}, labels...),
+		factory:        factory,
+		value:          value,
+		maxCardinality: maxCardinality,
+		members:        map[string]*familyMember[M]{},
+	}, nil
+}
+
+// With returns the metric for the provided label values, which are in the same
+// order as the labels of the Family; the metric is created if it does not exist yet.
+func (f *Family[M]) With(labelValues ...string) (M, error) {
+	var zero M
+	if len(labelValues) != len(f.labels) {
+		return zero, errors.Errorf(
+			"%d label values provided for %d labels %v",
+			len(labelValues),
+			len(f.labels),
+			f.labels,
+		)
+	}
+
+	key := familyKey(labelValues)
+
+	f.mux.RLock()
+	member, ok := f.members[key]
+	f.mux.RUnlock()
+	if ok {
+		return member.metric, nil
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	// the metric may have been created while waiting for the lock
+	if member, ok := f.members[key]; ok {
+		return member.metric, nil
+	} else if f.maxCardinality > 0 && len(f.members) >= f.maxCardinality {
+		return zero, errors.Errorf("max cardinality of %d reached for label values %v", f.maxCardinality, labelValues)
+	}
+
+	metric, err := f.factory()
+	if err != nil {
+		return zero, errors.Wrapf(err, "error creating metric for label values %v", labelValues)
+	}
+
+	err = initMetric(metric)
+	if err != nil {
+		return zero, errors.Wrapf(err, "error initializing metric for label values %v", labelValues)
+	}
+
+	f.members[key] = &familyMember[M]{
+		labelValues: append([]string{}, labelValues...),
+		metric:      metric,
+	}
+	return metric, nil
+}
+
+// initMetric sets up a metric with a Core if it is a metric from the moment or
+// joint packages whose Core has not been set yet.
+func initMetric(metric interface{}) error {
+	if wrapper, ok := metric.(interface{ IsSetCore() bool }); ok && wrapper.IsSetCore() {
+		return nil
+	}
+
+	switch wrapper := metric.(type) {
+	case moment.CoreWrapper:
+		return moment.Init(wrapper)
+	case joint.CoreWrapper:
+		return joint.Init(wrapper)
+	default:
+		return nil
+	}
+}
+
+// familyKey returns the key of a set of label values; the lengths of the values
+// are included so that distinct sets of values never have the same key.
+func familyKey(labelValues []string) string {
+	var b strings.Builder
+	for _, value := range labelValues {
+		fmt.Fprintf(&b, "%d:%s", len(value), value)
+	}
+	return b.String()
+}
+
+// Delete removes the metric for the provided label values, and returns if it existed.
+func (f *Family[M]) Delete(labelValues ...string) bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	key := familyKey(labelValues)
+	_, ok := f.members[key]
+	delete(f.members, key)
+	return ok
+}
+
+// Labels returns the label names of the Family.
+func (f *Family[M]) Labels() []string {
+	return append([]string{}, f.labels...)
+}
+
+// Len returns the number of metrics in the Family.
+func (f *Family[M]) Len() int {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return len(f.members)
+}
+
+// String returns a string representation of the metric.
+func (f *Family[M]) String() string {
+	name := "aggregate.Family"
+	params := []string{
+		fmt.Sprintf("labels:%s", strings.Join(f.labels, "|")),
+		fmt.Sprintf("maxCardinality:%d", f.maxCardinality),
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Values returns a snapshot of the values of the metrics in the Family, along
+// with their labels, sorted by their label values. If the values of some metrics
+// cannot be retrieved (e.g. a metric that has not seen any values yet), then the
+// values of the remaining metrics are still returned, along with an error that
+// contains the error for each of those metrics' label values.
+func (f *Family[M]) Values() ([]LabeledValue, error) {
+	f.mux.RLock()
+	members := make([]*familyMember[M], 0, len(f.members))
+	for _, member := range f.members {
+		members = append(members, member)
+	}
+	f.mux.RUnlock()
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessLabelValues(members[i].labelValues, members[j].labelValues)
+	})
+
+	var result *multierror.Error
+	values := make([]LabeledValue, 0, len(members))
+	for _, member := range members {
+		val, err := f.value(member.metric)
+		if err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "error retrieving value for label values %v", member.labelValues))
+			continue
+		}
+
+		labels := make(map[string]string, len(f.labels))
+		for i, label := range f.labels {
+			labels[label] = member.labelValues[i]
+		}
+		values = append(values, LabeledValue{Labels: labels, Value: val})
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return values, errors.Wrap(err, "error retrieving values from metrics")
+	}
+
+	return values, nil
+}
+
+func lessLabelValues(a []string, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// Clear removes all metrics from the Family.
+func (f *Family[M]) Clear() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.members = map[string]*familyMember[M]{}
+}
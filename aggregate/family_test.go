@@ -0,0 +1,276 @@
+package aggregate
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream/joint"
+	"github.com/alexander-yu/stream/moment"
+	"github.com/alexander-yu/stream/quantile"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func newMockFamily(t *testing.T, maxCardinality int) *Family[*mockMetric] {
+	family, err := NewFamily([]string{"endpoint", "status"}, maxCardinality, func() (*mockMetric, error) {
+		return &mockMetric{}, nil
+	}, (*mockMetric).Value)
+	require.NoError(t, err)
+	return family
+}
+
+func TestNewFamily(t *testing.T) {
+	t.Run("pass: valid Family is valid", func(t *testing.T) {
+		family := newMockFamily(t, 10)
+		assert.Equal(t, []string{"endpoint", "status"}, family.Labels())
+		assert.Equal(t, 0, family.Len())
+		assert.Equal(t, "aggregate.Family_{labels:endpoint|status,maxCardinality:10}", family.String())
+	})
+
+	t.Run("fail: no labels is invalid", func(t *testing.T) {
+		_, err := NewFamily(nil, 0, func() (*mockMetric, error) { return &mockMetric{}, nil }, (*mockMetric).Value)
+		testutil.ContainsError(t, err, "no labels provided")
+	})
+
+	t.Run("fail: duplicate labels are invalid", func(t *testing.T) {
+		_, err := NewFamily([]string{"a", "a"}, 0, func() (*mockMetric, error) { return &mockMetric{}, nil }, (*mockMetric).Value)
+		testutil.ContainsError(t, err, "label a is provided more than once")
+	})
+
+	t.Run("fail: negative max cardinality is invalid", func(t *testing.T) {
+		_, err := NewFamily([]string{"a"}, -1, func() (*mockMetric, error) { return &mockMetric{}, nil }, (*mockMetric).Value)
+		testutil.ContainsError(t, err, "-1 is a negative max cardinality")
+	})
+
+	t.Run("fail: no value function is invalid", func(t *testing.T) {
+		_, err := NewFamily([]string{"a"}, 0, func() (*mockMetric, error) { return &mockMetric{}, nil }, nil)
+		testutil.ContainsError(t, err, "no value function provided")
+	})
+}
+
+func TestFamilyWith(t *testing.T) {
+	t.Run("pass: metrics are created once per label values", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		metric1, err := family.With("/users", "200")
+		require.NoError(t, err)
+		metric2, err := family.With("/users", "500")
+		require.NoError(t, err)
+		metric3, err := family.With("/users", "200")
+		require.NoError(t, err)
+
+		assert.True(t, metric1 != metric2)
+		assert.True(t, metric1 == metric3)
+		assert.Equal(t, 2, family.Len())
+	})
+
+	t.Run("pass: label values with separators are distinct", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		metric1, err := family.With("a:b", "c")
+		require.NoError(t, err)
+		metric2, err := family.With("a", "b:c")
+		require.NoError(t, err)
+		assert.True(t, metric1 != metric2)
+	})
+
+	t.Run("pass: concurrent calls create a single metric", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		var wg sync.WaitGroup
+		metrics := make([]*mockMetric, 100)
+		for i := range metrics {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				metric, err := family.With("/users", "200")
+				assert.NoError(t, err)
+				metrics[i] = metric
+			}(i)
+		}
+		wg.Wait()
+
+		for _, metric := range metrics {
+			assert.True(t, metrics[0] == metric)
+		}
+	})
+
+	t.Run("pass: moment and joint metrics are initialized", func(t *testing.T) {
+		means, err := NewFamily([]string{"endpoint"}, 0, func() (*moment.Mean, error) {
+			return moment.NewMean(2), nil
+		}, (*moment.Mean).Value)
+		require.NoError(t, err)
+
+		mean, err := means.With("/users")
+		require.NoError(t, err)
+		for _, x := range []float64{1, 2, 3} {
+			err = mean.Push(x)
+			require.NoError(t, err)
+		}
+
+		value, err := mean.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 2.5, value)
+
+		covs, err := NewFamily([]string{"endpoint"}, 0, func() (*joint.Cov, error) {
+			return joint.NewGlobalCov(), nil
+		}, (*joint.Cov).Value)
+		require.NoError(t, err)
+
+		cov, err := covs.With("/users")
+		require.NoError(t, err)
+		for _, x := range []float64{1, 2, 3} {
+			err = cov.Push(x, 2*x)
+			require.NoError(t, err)
+		}
+
+		value, err = cov.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 2, value)
+	})
+
+	t.Run("fail: wrong number of label values returns error", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+		_, err := family.With("/users")
+		testutil.ContainsError(t, err, "1 label values provided for 2 labels [endpoint status]")
+	})
+
+	t.Run("fail: max cardinality is enforced", func(t *testing.T) {
+		family := newMockFamily(t, 2)
+
+		_, err := family.With("/users", "200")
+		require.NoError(t, err)
+		_, err = family.With("/users", "500")
+		require.NoError(t, err)
+
+		_, err = family.With("/items", "200")
+		testutil.ContainsError(t, err, "max cardinality of 2 reached for label values [/items 200]")
+
+		// existing metrics can still be retrieved
+		_, err = family.With("/users", "200")
+		require.NoError(t, err)
+
+		assert.True(t, family.Delete("/users", "500"))
+		assert.False(t, family.Delete("/users", "500"))
+		_, err = family.With("/items", "200")
+		require.NoError(t, err)
+	})
+
+	t.Run("fail: factory error is returned", func(t *testing.T) {
+		family, err := NewFamily([]string{"endpoint"}, 0, func() (*mockMetric, error) {
+			return nil, errors.New("factory error")
+		}, (*mockMetric).Value)
+		require.NoError(t, err)
+
+		_, err = family.With("/users")
+		testutil.ContainsError(t, err, "error creating metric for label values [/users]: factory error")
+		assert.Equal(t, 0, family.Len())
+	})
+}
+
+func TestFamilyValues(t *testing.T) {
+	t.Run("pass: returns labeled values sorted by label values", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		for i, labelValues := range [][]string{{"/users", "500"}, {"/items", "200"}, {"/users", "200"}} {
+			metric, err := family.With(labelValues...)
+			require.NoError(t, err)
+			metric.val = float64(i)
+		}
+
+		values, err := family.Values()
+		require.NoError(t, err)
+		assert.Equal(t, []LabeledValue{
+			{Labels: map[string]string{"endpoint": "/items", "status": "200"}, Value: 1},
+			{Labels: map[string]string{"endpoint": "/users", "status": "200"}, Value: 2},
+			{Labels: map[string]string{"endpoint": "/users", "status": "500"}, Value: 0},
+		}, values)
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		for i := 0; i < 2; i++ {
+			metric, err := family.With(fmt.Sprint(i), "200")
+			require.NoError(t, err)
+			metric.valErr = true
+		}
+
+		values, err := family.Values()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+		testutil.ContainsError(t, err, "2 errors occurred")
+		assert.Empty(t, values)
+	})
+
+	t.Run("fail: returns successful values along with errors for failed label values", func(t *testing.T) {
+		family := newMockFamily(t, 0)
+
+		for i, labelValues := range [][]string{{"/users", "500"}, {"/items", "200"}, {"/users", "200"}} {
+			metric, err := family.With(labelValues...)
+			require.NoError(t, err)
+			metric.val = float64(i)
+			metric.valErr = i == 1
+		}
+
+		values, err := family.Values()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+		testutil.ContainsError(t, err, "1 error occurred")
+		testutil.ContainsError(t, err, "error retrieving value for label values [/items 200]")
+		assert.Equal(t, []LabeledValue{
+			{Labels: map[string]string{"endpoint": "/users", "status": "200"}, Value: 2},
+			{Labels: map[string]string{"endpoint": "/users", "status": "500"}, Value: 0},
+		}, values)
+	})
+}
+
+func TestFamilyQuantile(t *testing.T) {
+	// a Quantile has no single value, so the Family reads the 0.9 quantile of each one
+	family, err := NewFamily([]string{"endpoint"}, 0, func() (*quantile.Quantile, error) {
+		return quantile.NewGlobalQuantile()
+	}, func(q *quantile.Quantile) (float64, error) {
+		return q.Value(0.9)
+	})
+	require.NoError(t, err)
+
+	for _, endpoint := range []string{"/users", "/items"} {
+		q, err := family.With(endpoint)
+		require.NoError(t, err)
+		for i := 0; i <= 10; i++ {
+			x := float64(i)
+			if endpoint == "/items" {
+				x *= 10
+			}
+			err = q.Push(x)
+			require.NoError(t, err)
+		}
+	}
+
+	// lazily created members without values are reported as errors, alongside the rest
+	_, err = family.With("/orders")
+	require.NoError(t, err)
+
+	values, err := family.Values()
+	testutil.ContainsError(t, err, "error retrieving value for label values [/orders]")
+	require.Len(t, values, 2)
+	assert.Equal(t, map[string]string{"endpoint": "/items"}, values[0].Labels)
+	testutil.Approx(t, 90, values[0].Value)
+	assert.Equal(t, map[string]string{"endpoint": "/users"}, values[1].Labels)
+	testutil.Approx(t, 9, values[1].Value)
+}
+
+func TestFamilyClear(t *testing.T) {
+	family := newMockFamily(t, 0)
+	_, err := family.With("/users", "200")
+	require.NoError(t, err)
+
+	family.Clear()
+	assert.Equal(t, 0, family.Len())
+
+	values, err := family.Values()
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
@@ -3,7 +3,6 @@ package aggregate
 import (
 	"sync"
 
-	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
 	"github.com/alexander-yu/stream"
@@ -11,45 +10,83 @@ import (
 
 // SimpleAggregateMetric is a wrapper metric that tracks multiple univariate single-value metrics simultaneously.
 // Note that it simply stores multiple metrics and pushes to all of them; this can be inefficient
-// for metrics that could make use of shared data.
+// for metrics that could make use of shared data (see moment.Aggregate for metrics that can share a Core).
 type SimpleAggregateMetric struct {
-	metrics []stream.SimpleMetric
-	mux     sync.Mutex
+	metrics  []stream.SimpleMetric
+	count    int
+	strategy Strategy
+	mux      sync.Mutex
 }
 
 // NewSimpleAggregateMetric instantiates an SimpleAggregateMetric struct.
 func NewSimpleAggregateMetric(metrics ...stream.SimpleMetric) *SimpleAggregateMetric {
-	return &SimpleAggregateMetric{metrics: metrics}
+	return &SimpleAggregateMetric{metrics: metrics, strategy: Sequential()}
+}
+
+// SetStrategy sets the Strategy used to push values to the metrics, and to retrieve their
+// values; the default is Sequential, which pushes to each metric one at a time.
+func (s *SimpleAggregateMetric) SetStrategy(strategy Strategy) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.strategy = strategy
 }
 
 // Push adds a new value for the metrics to consume.
 func (s *SimpleAggregateMetric) Push(x float64) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	var (
-		result *multierror.Error
-		mux    sync.Mutex
-		wg     sync.WaitGroup
-	)
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleMetric) {
-			defer wg.Done()
-			err := metric.Push(x)
+
+	errs := s.strategy.run(len(s.metrics), func(i int) error {
+		return s.metrics[i].Push(x)
+	})
+
+	// the value is counted if any metric consumed it
+	if len(errs) < len(s.metrics) {
+		s.count++
+	}
+
+	err := combine(errs)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to metrics", x)
+	}
+
+	return nil
+}
+
+// PushBatch adds multiple values for the metrics to consume, in order. The values
+// are pushed to each metric in one go, so the cost of the Strategy is paid once for
+// the whole batch rather than once per value. If pushing a value to a metric fails,
+// then the rest of the batch is not pushed to that metric.
+func (s *SimpleAggregateMetric) PushBatch(xs []float64) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// consumed[i] is the number of values of the batch consumed by the ith metric
+	consumed := make([]int, len(s.metrics))
+	errs := s.strategy.run(len(s.metrics), func(i int) error {
+		for _, x := range xs {
+			err := s.metrics[i].Push(x)
 			if err != nil {
-				mux.Lock()
-				result = multierror.Append(result, err)
-				mux.Unlock()
+				return errors.Wrapf(err, "error pushing %f", x)
 			}
-		}(metric)
+			consumed[i]++
+		}
+		return nil
+	})
+
+	// each value is counted if any metric consumed it, and each metric
+	// consumes a prefix of the batch
+	max := 0
+	for _, n := range consumed {
+		if n > max {
+			max = n
+		}
 	}
+	s.count += max
 
-	wg.Wait()
-
-	err := result.ErrorOrNil()
+	err := combine(errs)
 	if err != nil {
-		return errors.Wrapf(err, "error pushing %f to metrics", x)
+		return errors.Wrapf(err, "error pushing batch of %d values to metrics", len(xs))
 	}
 
 	return nil
@@ -61,53 +98,54 @@ func (s *SimpleAggregateMetric) Push(x float64) error {
 func (s *SimpleAggregateMetric) Values() (map[string]float64, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	values := map[string]float64{}
-	var errs []error
-	var mux sync.Mutex
-	var wg sync.WaitGroup
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleMetric) {
-			defer wg.Done()
-			val, err := metric.Value()
-
-			mux.Lock()
-			if err != nil {
-				errs = append(errs, err)
-			} else {
-				values[metric.String()] = val
-			}
-			mux.Unlock()
-		}(metric)
+	return s.values()
+}
+
+// Snapshot returns a snapshot of the values of the metrics, along with the number
+// of values pushed through the SimpleAggregateMetric (and consumed by at least one of
+// the metrics) since it was last cleared. No values can be pushed through the
+// SimpleAggregateMetric while the snapshot is taken, so all of the values reflect the
+// same set of values, as long as the metrics are only pushed to through it. The
+// metrics themselves are not locked, so values pushed to them directly are neither
+// counted nor guaranteed to be reflected consistently.
+func (s *SimpleAggregateMetric) Snapshot() (*stream.Snapshot, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	values, err := s.values()
+	if err != nil {
+		return nil, err
 	}
 
-	wg.Wait()
+	return &stream.Snapshot{Values: values, Count: s.count}, nil
+}
 
-	if len(errs) != 0 {
-		var result *multierror.Error
-		for _, err := range errs {
-			result = multierror.Append(result, err)
-		}
-		return nil, errors.Wrap(result, "error retrieving values from metrics")
+// values returns the values of the metrics, without locking.
+func (s *SimpleAggregateMetric) values() (map[string]float64, error) {
+	values := make([]float64, len(s.metrics))
+	err := combine(s.strategy.run(len(s.metrics), func(i int) error {
+		val, err := s.metrics[i].Value()
+		values[i] = val
+		return err
+	}))
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving values from metrics")
 	}
 
-	return values, nil
+	result := make(map[string]float64, len(s.metrics))
+	for i, metric := range s.metrics {
+		result[metric.String()] = values[i]
+	}
+	return result, nil
 }
 
 // Clear resets all metrics.
 func (s *SimpleAggregateMetric) Clear() {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	var wg sync.WaitGroup
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleMetric) {
-			defer wg.Done()
-			metric.Clear()
-		}(metric)
-	}
-
-	wg.Wait()
+	s.count = 0
+	s.strategy.run(len(s.metrics), func(i int) error {
+		s.metrics[i].Clear()
+		return nil
+	})
 }
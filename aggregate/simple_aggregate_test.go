@@ -2,6 +2,7 @@ package aggregate
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/counter"
 )
 
 type mockMetric struct {
@@ -79,6 +81,13 @@ func TestSimpleAggregateMetricPush(t *testing.T) {
 			0.,
 			0.,
 		))
+		assert.Equal(t, 1, metric.count)
+
+		// a value that no metric consumed is not counted
+		metric1.pushErr = true
+		err = metric.Push(1.)
+		require.Error(t, err)
+		assert.Equal(t, 1, metric.count)
 	})
 }
 
@@ -111,6 +120,55 @@ func TestSimpleAggregateMetricValue(t *testing.T) {
 	})
 }
 
+func TestSimpleAggregateMetricSnapshot(t *testing.T) {
+	t.Run("pass: values reflect the same pushed values", func(t *testing.T) {
+		count := counter.NewGlobalCount()
+		sum := counter.NewGlobalSum()
+		metric := NewSimpleAggregateMetric(count, sum)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 250; j++ {
+					assert.NoError(t, metric.Push(1))
+				}
+			}()
+		}
+
+		for i := 0; i < 100; i++ {
+			snapshot, err := metric.Snapshot()
+			require.NoError(t, err)
+			assert.Equal(t, float64(snapshot.Count), snapshot.Values[count.String()])
+			assert.Equal(t, float64(snapshot.Count), snapshot.Values[sum.String()])
+		}
+		wg.Wait()
+
+		snapshot, err := metric.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, &stream.Snapshot{
+			Values: map[string]float64{
+				count.String(): 1000,
+				sum.String():   1000,
+			},
+			Count: 1000,
+		}, snapshot)
+
+		metric.Clear()
+		snapshot, err = metric.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 0, snapshot.Count)
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		metric := NewSimpleAggregateMetric(&mockMetric{val: 1}, &mockMetric{valErr: true})
+
+		_, err := metric.Snapshot()
+		assert.EqualError(t, err, "error retrieving values from metrics: 1 error occurred:\n\t* error retrieving value\n\n")
+	})
+}
+
 func TestSimpleAggregateMetricClear(t *testing.T) {
 	metric1 := &mockMetric{}
 	metric2 := &mockMetric{}
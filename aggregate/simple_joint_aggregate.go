@@ -4,51 +4,88 @@ import (
 	"sync"
 
 	"github.com/alexander-yu/stream"
-	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 )
 
 // SimpleJointAggregateMetric is a wrapper metric that tracks multiple multivariate single-value metrics simultaneously.
 // Note that it simply stores multiple metrics and pushes to all of them; this can be inefficient
-// for metrics that could make use of shared data.
+// for metrics that could make use of shared data (see joint.Aggregate for metrics that can share a Core).
 type SimpleJointAggregateMetric struct {
-	metrics []stream.SimpleJointMetric
-	mux     sync.Mutex
+	metrics  []stream.SimpleJointMetric
+	count    int
+	strategy Strategy
+	mux      sync.Mutex
 }
 
 // NewSimpleJointAggregateMetric instantiates an SimpleJointAggregateMetric struct.
 func NewSimpleJointAggregateMetric(metrics ...stream.SimpleJointMetric) *SimpleJointAggregateMetric {
-	return &SimpleJointAggregateMetric{metrics: metrics}
+	return &SimpleJointAggregateMetric{metrics: metrics, strategy: Sequential()}
+}
+
+// SetStrategy sets the Strategy used to push values to the metrics, and to retrieve their
+// values; the default is Sequential, which pushes to each metric one at a time.
+func (s *SimpleJointAggregateMetric) SetStrategy(strategy Strategy) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.strategy = strategy
 }
 
 // Push adds a new value for the metrics to consume.
 func (s *SimpleJointAggregateMetric) Push(xs ...float64) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	var (
-		result *multierror.Error
-		mux    sync.Mutex
-		wg     sync.WaitGroup
-	)
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleJointMetric) {
-			defer wg.Done()
-			err := metric.Push(xs...)
+
+	errs := s.strategy.run(len(s.metrics), func(i int) error {
+		return s.metrics[i].Push(xs...)
+	})
+
+	// the value is counted if any metric consumed it
+	if len(errs) < len(s.metrics) {
+		s.count++
+	}
+
+	err := combine(errs)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %v to metrics", xs)
+	}
+
+	return nil
+}
+
+// PushBatch adds multiple tuples of values for the metrics to consume, in order. The
+// tuples are pushed to each metric in one go, so the cost of the Strategy is paid once
+// for the whole batch rather than once per tuple. If pushing a tuple to a metric fails,
+// then the rest of the batch is not pushed to that metric.
+func (s *SimpleJointAggregateMetric) PushBatch(xss [][]float64) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// consumed[i] is the number of values of the batch consumed by the ith metric
+	consumed := make([]int, len(s.metrics))
+	errs := s.strategy.run(len(s.metrics), func(i int) error {
+		for _, xs := range xss {
+			err := s.metrics[i].Push(xs...)
 			if err != nil {
-				mux.Lock()
-				result = multierror.Append(result, err)
-				mux.Unlock()
+				return errors.Wrapf(err, "error pushing %v", xs)
 			}
-		}(metric)
+			consumed[i]++
+		}
+		return nil
+	})
+
+	// each value is counted if any metric consumed it, and each metric
+	// consumes a prefix of the batch
+	max := 0
+	for _, n := range consumed {
+		if n > max {
+			max = n
+		}
 	}
+	s.count += max
 
-	wg.Wait()
-
-	err := result.ErrorOrNil()
+	err := combine(errs)
 	if err != nil {
-		return errors.Wrapf(err, "error pushing %v to metrics", xs)
+		return errors.Wrapf(err, "error pushing batch of %d values to metrics", len(xss))
 	}
 
 	return nil
@@ -60,53 +97,54 @@ func (s *SimpleJointAggregateMetric) Push(xs ...float64) error {
 func (s *SimpleJointAggregateMetric) Values() (map[string]float64, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	values := map[string]float64{}
-	var errs []error
-	var mux sync.Mutex
-	var wg sync.WaitGroup
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleJointMetric) {
-			defer wg.Done()
-			val, err := metric.Value()
-
-			mux.Lock()
-			if err != nil {
-				errs = append(errs, err)
-			} else {
-				values[metric.String()] = val
-			}
-			mux.Unlock()
-		}(metric)
+	return s.values()
+}
+
+// Snapshot returns a snapshot of the values of the metrics, along with the number
+// of values pushed through the SimpleJointAggregateMetric (and consumed by at least one of
+// the metrics) since it was last cleared. No values can be pushed through the
+// SimpleJointAggregateMetric while the snapshot is taken, so all of the values reflect the
+// same set of values, as long as the metrics are only pushed to through it. The
+// metrics themselves are not locked, so values pushed to them directly are neither
+// counted nor guaranteed to be reflected consistently.
+func (s *SimpleJointAggregateMetric) Snapshot() (*stream.Snapshot, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	values, err := s.values()
+	if err != nil {
+		return nil, err
 	}
 
-	wg.Wait()
+	return &stream.Snapshot{Values: values, Count: s.count}, nil
+}
 
-	if len(errs) != 0 {
-		var result *multierror.Error
-		for _, err := range errs {
-			result = multierror.Append(result, err)
-		}
-		return nil, errors.Wrap(result, "error retrieving values from metrics")
+// values returns the values of the metrics, without locking.
+func (s *SimpleJointAggregateMetric) values() (map[string]float64, error) {
+	values := make([]float64, len(s.metrics))
+	err := combine(s.strategy.run(len(s.metrics), func(i int) error {
+		val, err := s.metrics[i].Value()
+		values[i] = val
+		return err
+	}))
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving values from metrics")
 	}
 
-	return values, nil
+	result := make(map[string]float64, len(s.metrics))
+	for i, metric := range s.metrics {
+		result[metric.String()] = values[i]
+	}
+	return result, nil
 }
 
 // Clear resets all metrics.
 func (s *SimpleJointAggregateMetric) Clear() {
 	s.mux.Lock()
 	defer s.mux.Unlock()
-	var wg sync.WaitGroup
-
-	for _, metric := range s.metrics {
-		wg.Add(1)
-		go func(metric stream.SimpleJointMetric) {
-			defer wg.Done()
-			metric.Clear()
-		}(metric)
-	}
-
-	wg.Wait()
+	s.count = 0
+	s.strategy.run(len(s.metrics), func(i int) error {
+		s.metrics[i].Clear()
+		return nil
+	})
 }
@@ -111,6 +111,47 @@ func TestSimpleJointAggregateMetricValue(t *testing.T) {
 	})
 }
 
+func TestSimpleJointAggregateMetricSnapshot(t *testing.T) {
+	t.Run("pass: retrieves value of each metric and the number of values pushed", func(t *testing.T) {
+		metric1 := &mockJointMetric{val: 1}
+		metric2 := &mockJointMetric{val: 2}
+		metric := NewSimpleJointAggregateMetric(metric1, metric2)
+
+		for i := 0.; i < 5; i++ {
+			err := metric.Push(i, i)
+			require.NoError(t, err)
+		}
+
+		snapshot, err := metric.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, &stream.Snapshot{
+			Values: map[string]float64{
+				metric1.String(): metric1.val,
+				metric2.String(): metric2.val,
+			},
+			Count: 5,
+		}, snapshot)
+	})
+
+	t.Run("pass: failed pushes are not counted", func(t *testing.T) {
+		metric := NewSimpleJointAggregateMetric(&mockJointMetric{pushErr: true})
+
+		err := metric.Push(1, 2)
+		require.Error(t, err)
+
+		snapshot, err := metric.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 0, snapshot.Count)
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		metric := NewSimpleJointAggregateMetric(&mockJointMetric{valErr: true})
+
+		_, err := metric.Snapshot()
+		assert.EqualError(t, err, "error retrieving values from metrics: 1 error occurred:\n\t* error retrieving value\n\n")
+	})
+}
+
 func TestSimpleJointAggregateMetricClear(t *testing.T) {
 	metric1 := &mockJointMetric{}
 	metric2 := &mockJointMetric{}
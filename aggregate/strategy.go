@@ -0,0 +1,161 @@
+package aggregate
+
+import (
+	"sync"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// Strategy is a strategy for executing an operation (e.g. pushing a value) on each of the
+// metrics of an aggregate metric. Handing a metric to another goroutine costs more than
+// updating a cheap metric, so Sequential is the default; see BenchmarkSimpleAggregateMetricPush
+// for measurements of when the other strategies pay off.
+type Strategy interface {
+	// run calls f once for each index in [0, n), and returns the errors returned by f.
+	run(n int, f func(i int) error) []error
+}
+
+type sequential struct{}
+
+// Sequential returns a Strategy that executes the operation on each metric one
+// at a time, in the order that the metrics were provided; this is the default Strategy.
+func Sequential() Strategy {
+	return sequential{}
+}
+
+func (sequential) run(n int, f func(i int) error) []error {
+	var errs []error
+	for i := 0; i < n; i++ {
+		err := f(i)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+type concurrent struct{}
+
+// Concurrent returns a Strategy that executes the operation on each metric in its
+// own goroutine.
+func Concurrent() Strategy {
+	return concurrent{}
+}
+
+func (concurrent) run(n int, f func(i int) error) []error {
+	var (
+		errs []error
+		mux  sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := f(i)
+			if err != nil {
+				mux.Lock()
+				errs = append(errs, err)
+				mux.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// Pool is a Strategy that executes the operation on the metrics with a fixed number
+// of long-lived worker goroutines, which are fed the metrics to handle through a
+// channel; unlike Concurrent, no goroutines are spawned when the operation is executed.
+// A Pool can be shared by multiple aggregate metrics, and Close must be called to stop
+// its workers once it is no longer used.
+type Pool struct {
+	jobs   chan job
+	closed bool
+	mux    sync.RWMutex
+}
+
+// job is a call of an operation on a single metric, whose error is sent on errs.
+type job struct {
+	f    func(i int) error
+	i    int
+	errs chan<- error
+}
+
+// WorkerPool returns a Pool that executes the operation on the metrics with the
+// provided number of worker goroutines, each of which executes the operation on
+// the next metric that has not been handled yet.
+func WorkerPool(workers int) (*Pool, error) {
+	if workers <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive number of workers", workers)
+	}
+
+	p := &Pool{jobs: make(chan job)}
+	for w := 0; w < workers; w++ {
+		go p.work()
+	}
+	return p, nil
+}
+
+// work executes jobs until the Pool is closed.
+func (p *Pool) work() {
+	for j := range p.jobs {
+		j.errs <- j.f(j.i)
+	}
+}
+
+func (p *Pool) run(n int, f func(i int) error) []error {
+	// the buffer ensures that the workers never block on sending errors,
+	// so that all jobs can be sent before any errors are received
+	results := make(chan error, n)
+
+	p.mux.RLock()
+	if p.closed {
+		p.mux.RUnlock()
+		errs := make([]error, n)
+		for i := range errs {
+			errs[i] = errors.New("WorkerPool is closed")
+		}
+		return errs
+	}
+
+	for i := 0; i < n; i++ {
+		p.jobs <- job{f: f, i: i, errs: results}
+	}
+	p.mux.RUnlock()
+
+	var errs []error
+	for i := 0; i < n; i++ {
+		err := <-results
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Close stops the workers of the Pool, once the operations that are already being
+// executed are done; afterwards, executing an operation with the Pool returns an
+// error for each metric. Calling Close more than once is a no-op.
+func (p *Pool) Close() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+}
+
+// combine combines the errors returned by a Strategy into a single error,
+// which is nil if there are no errors.
+func combine(errs []error) error {
+	var result *multierror.Error
+	for _, err := range errs {
+		result = multierror.Append(result, err)
+	}
+	return result.ErrorOrNil()
+}
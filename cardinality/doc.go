@@ -0,0 +1,20 @@
+// Package cardinality provides metrics for estimating the number of distinct
+// values in a stream.
+//
+// HyperLogLog implements HyperLogLog++, which hashes each value to 64 bits and
+// keeps the longest run of leading zeros seen for each of m = 2^p registers;
+// the estimate has a relative standard error of roughly 1.04 / sqrt(m), which is
+// 0.81% for the default precision of 14, using m bytes of memory. Small
+// cardinalities are instead tracked with a sparse representation, which stores
+// only the nonempty registers at a higher precision of 25 as a sorted list of
+// 4-byte packed registers (along with a small buffer of recently added ones) and
+// is nearly exact, until it would use more memory than the dense registers.
+//
+// Rather than the empirical bias correction tables of HyperLogLog++, dense
+// registers are estimated with the improved estimator of Ertl, which corrects
+// for the bias of the raw HyperLogLog estimate at small and large cardinalities
+// without any tables.
+//
+// See https://research.google/pubs/pub40671/ and https://arxiv.org/abs/1702.01284
+// for more details.
+package cardinality
@@ -0,0 +1,168 @@
+package cardinality
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older HyperLogLogs.
+const encodingVersion uint8 = 1
+
+// encodedRegister is the binary representation of a nonempty register
+// of the sparse representation.
+type encodedRegister struct {
+	Index uint32
+	Value uint8
+}
+
+// MarshalBinary encodes the HyperLogLog into a compact binary form, so that it
+// can be shipped elsewhere and restored with UnmarshalBinary (and potentially
+// merged into other HyperLogLogs). If the HyperLogLog uses the sparse
+// representation, only the nonempty registers are encoded.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.flush()
+
+	var sparse uint8
+	if h.registers == nil {
+		sparse = 1
+	}
+
+	buf := &bytes.Buffer{}
+	fields := []interface{}{encodingVersion, uint8(h.precision), sparse}
+	if h.registers == nil {
+		registers := make([]encodedRegister, len(h.sparse))
+		for i, packed := range h.sparse {
+			registers[i].Index, registers[i].Value = unpack(packed)
+		}
+		fields = append(fields, uint32(len(registers)), registers)
+	} else {
+		fields = append(fields, h.registers)
+	}
+
+	for _, field := range fields {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding HyperLogLog")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the HyperLogLog from data produced by MarshalBinary,
+// replacing any state the HyperLogLog currently has.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var precision, sparse uint8
+	for _, field := range []interface{}{&precision, &sparse} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding HyperLogLog")
+		}
+	}
+
+	p := int(precision)
+	if p < MinPrecision || p > MaxPrecision {
+		return errors.Errorf("encoded HyperLogLog has precision %d not in [%d, %d]", p, MinPrecision, MaxPrecision)
+	}
+
+	decoded := &HyperLogLog{precision: p}
+	if sparse != 0 {
+		decoded.sparse, err = decodeSparse(r, p)
+		if err != nil {
+			return errors.Wrap(err, "error decoding sparse registers")
+		}
+	} else {
+		decoded.registers, err = decodeDense(r, p)
+		if err != nil {
+			return errors.Wrap(err, "error decoding registers")
+		}
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded HyperLogLog has %d trailing bytes", r.Len())
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.precision = decoded.precision
+	h.sparse = decoded.sparse
+	h.buffer = nil
+	h.registers = decoded.registers
+
+	return nil
+}
+
+func decodeSparse(r *bytes.Reader, p int) ([]uint32, error) {
+	var numRegisters uint32
+	err := binary.Read(r, binary.BigEndian, &numRegisters)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := (1 << uint(p)) / 4; int(numRegisters) > limit {
+		return nil, errors.Errorf("encoded sparse registers have %d registers for a limit of %d", numRegisters, limit)
+	} else if 5*int(numRegisters) > r.Len() {
+		return nil, errors.Errorf("encoded sparse registers have %d registers but only %d bytes remaining", numRegisters, r.Len())
+	}
+
+	registers := make([]encodedRegister, numRegisters)
+	err = binary.Read(r, binary.BigEndian, registers)
+	if err != nil {
+		return nil, err
+	}
+
+	sparse := make([]uint32, numRegisters)
+	for i, register := range registers {
+		if register.Index >= 1<<sparsePrecision {
+			return nil, errors.Errorf("encoded sparse register has an index %d out of range", register.Index)
+		} else if register.Value == 0 || int(register.Value) > 64-sparsePrecision+1 {
+			return nil, errors.Errorf("encoded sparse register has an invalid value of %d", register.Value)
+		} else if i > 0 && register.Index == registers[i-1].Index {
+			return nil, errors.Errorf("encoded sparse registers have a duplicate register %d", register.Index)
+		} else if i > 0 && register.Index < registers[i-1].Index {
+			return nil, errors.Errorf("encoded sparse registers are not sorted at register %d", register.Index)
+		}
+		sparse[i] = pack(register.Index, register.Value)
+	}
+
+	return sparse, nil
+}
+
+func decodeDense(r *bytes.Reader, p int) ([]uint8, error) {
+	m := 1 << uint(p)
+	if m > r.Len() {
+		return nil, errors.Errorf("encoded registers have %d registers but only %d bytes remaining", m, r.Len())
+	}
+
+	registers := make([]uint8, m)
+	_, err := r.Read(registers)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, rho := range registers {
+		if int(rho) > 64-p+1 {
+			return nil, errors.Errorf("encoded register %d has an invalid value of %d", idx, rho)
+		}
+	}
+
+	return registers, nil
+}
@@ -0,0 +1,84 @@
+package cardinality
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{100, 100000} {
+		t.Run("pass: restored HyperLogLog matches original for "+strconv.Itoa(n)+" values", func(t *testing.T) {
+			h, err := New(12)
+			require.NoError(t, err)
+			for i := 0; i < n; i++ {
+				h.PushString(strconv.Itoa(i))
+			}
+
+			data, err := h.MarshalBinary()
+			require.NoError(t, err)
+
+			restored := &HyperLogLog{}
+			err = restored.UnmarshalBinary(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, h.precision, restored.precision)
+			assert.Equal(t, h.sparse, restored.sparse)
+			assert.Equal(t, h.registers, restored.registers)
+
+			// the restored HyperLogLog can still be merged into HyperLogLogs with the same precision
+			err = h.Merge(restored)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("fail: unsupported version is invalid", func(t *testing.T) {
+		err := (&HyperLogLog{}).UnmarshalBinary([]byte{0})
+		testutil.ContainsError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: invalid precision is invalid", func(t *testing.T) {
+		err := (&HyperLogLog{}).UnmarshalBinary([]byte{encodingVersion, 20, 0})
+		testutil.ContainsError(t, err, "encoded HyperLogLog has precision 20 not in [4, 18]")
+	})
+
+	t.Run("fail: truncated data is invalid", func(t *testing.T) {
+		for _, n := range []int{10, 100000} {
+			h := NewDefault()
+			for i := 0; i < n; i++ {
+				h.PushString(strconv.Itoa(i))
+			}
+
+			data, err := h.MarshalBinary()
+			require.NoError(t, err)
+
+			err = (&HyperLogLog{}).UnmarshalBinary(data[:len(data)-1])
+			testutil.ContainsError(t, err, "error decoding")
+		}
+	})
+
+	t.Run("fail: trailing data is invalid", func(t *testing.T) {
+		data, err := NewDefault().MarshalBinary()
+		require.NoError(t, err)
+
+		err = (&HyperLogLog{}).UnmarshalBinary(append(data, 0))
+		testutil.ContainsError(t, err, "encoded HyperLogLog has 1 trailing bytes")
+	})
+
+	t.Run("fail: invalid registers are invalid", func(t *testing.T) {
+		err := (&HyperLogLog{}).UnmarshalBinary([]byte{encodingVersion, 4, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0})
+		testutil.ContainsError(t, err, "encoded sparse register has an invalid value of 0")
+
+		err = (&HyperLogLog{}).UnmarshalBinary([]byte{encodingVersion, 4, 1, 0, 0, 0, 2, 0, 0, 0, 2, 1, 0, 0, 0, 1, 1})
+		testutil.ContainsError(t, err, "encoded sparse registers are not sorted at register 1")
+
+		data := append([]byte{encodingVersion, 4, 0}, make([]byte, 16)...)
+		data[3] = 100
+		err = (&HyperLogLog{}).UnmarshalBinary(data)
+		testutil.ContainsError(t, err, "encoded register 0 has an invalid value of 100")
+	})
+}
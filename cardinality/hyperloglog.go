@@ -0,0 +1,356 @@
+package cardinality
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/util/hash"
+)
+
+const (
+	// DefaultPrecision is the default precision of a HyperLogLog
+	DefaultPrecision int = 14
+	// MinPrecision is the minimum precision of a HyperLogLog
+	MinPrecision int = 4
+	// MaxPrecision is the maximum precision of a HyperLogLog
+	MaxPrecision int = 18
+	// sparsePrecision is the precision of the sparse representation
+	sparsePrecision = 25
+	// rhoBits is the number of bits that the value of a register takes up in a packed
+	// register of the sparse representation, which holds values up to 64 - 25 + 1 = 40
+	rhoBits = 6
+)
+
+// HyperLogLog keeps track of the approximate number of distinct values in a stream
+// with HyperLogLog++, and satisfies the stream.SimpleMetric interface; see the
+// package documentation for its error bounds. Besides float64 values, it can also
+// track strings and byte slices (e.g. user IDs or IP addresses).
+type HyperLogLog struct {
+	precision int
+	// sparse is the sorted list of the nonempty registers at the sparse precision,
+	// each packed into 4 bytes as index<<rhoBits | value, with one register per index;
+	// buffer holds the packed registers that have been added since sparse was last
+	// merged with them, in no particular order. Both are unused once the HyperLogLog
+	// uses dense registers, i.e. once registers is not nil.
+	sparse    []uint32
+	buffer    []uint32
+	registers []uint8
+	mux       sync.RWMutex
+}
+
+// New instantiates a HyperLogLog struct with m = 2^precision registers;
+// the precision must be between MinPrecision and MaxPrecision.
+func New(precision int) (*HyperLogLog, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, errors.Errorf("precision %d not in [%d, %d]", precision, MinPrecision, MaxPrecision)
+	}
+
+	return &HyperLogLog{precision: precision}, nil
+}
+
+// NewDefault instantiates a HyperLogLog struct with the default precision.
+// This is equivalent to calling New(DefaultPrecision).
+func NewDefault() *HyperLogLog {
+	return &HyperLogLog{precision: DefaultPrecision}
+}
+
+// String returns a string representation of the metric.
+func (h *HyperLogLog) String() string {
+	return fmt.Sprintf("cardinality.HyperLogLog_{precision:%d}", h.precision)
+}
+
+// Precision returns the precision of the HyperLogLog.
+func (h *HyperLogLog) Precision() int {
+	return h.precision
+}
+
+// Push adds a number to the HyperLogLog; 0 and -0 are counted as the same value.
+func (h *HyperLogLog) Push(x float64) error {
+	if math.IsNaN(x) {
+		return errors.New("attempted to push NaN")
+	} else if x == 0 {
+		x = 0
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.add(hash.Mix(math.Float64bits(x)))
+	return nil
+}
+
+// PushString adds a string to the HyperLogLog.
+func (h *HyperLogLog) PushString(s string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.add(hash.Bytes(s))
+}
+
+// PushBytes adds a byte slice to the HyperLogLog; a byte slice is counted as
+// the same value as the string with the same bytes.
+func (h *HyperLogLog) PushBytes(b []byte) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.add(hash.Bytes(b))
+}
+
+// add adds a hashed value to the HyperLogLog, without locking.
+func (h *HyperLogLog) add(x uint64) {
+	if h.registers != nil {
+		idx, rho := split(x, h.precision)
+		if rho > h.registers[idx] {
+			h.registers[idx] = rho
+		}
+		return
+	}
+
+	h.buffer = append(h.buffer, pack(split(x, sparsePrecision)))
+	if len(h.buffer) >= h.bufferLimit() {
+		h.flush()
+	}
+}
+
+// sparseLimit returns the maximum number of packed registers that the sparse
+// representation holds (including its buffer); since each packed register takes
+// up 4 bytes and each dense register takes up 1 byte, this is a quarter of the
+// number of dense registers, beyond which the dense registers take up less memory.
+func (h *HyperLogLog) sparseLimit() int {
+	return (1 << uint(h.precision)) / 4
+}
+
+// bufferLimit returns the number of packed registers that are buffered before
+// they are merged into the sorted list of the sparse representation.
+func (h *HyperLogLog) bufferLimit() int {
+	return h.sparseLimit() / 4
+}
+
+// flush merges the buffered registers of the sparse representation into its sorted
+// list, and converts it into dense registers if the sorted list has grown too large
+// for another full buffer to fit within sparseLimit.
+func (h *HyperLogLog) flush() {
+	if len(h.buffer) == 0 {
+		return
+	}
+
+	sort.Slice(h.buffer, func(i, j int) bool {
+		return h.buffer[i] < h.buffer[j]
+	})
+	h.sparse = mergeSparse(h.sparse, h.buffer)
+	h.buffer = h.buffer[:0]
+
+	if len(h.sparse)+h.bufferLimit() > h.sparseLimit() {
+		h.densify()
+	}
+}
+
+// densify converts the sparse representation into dense registers.
+func (h *HyperLogLog) densify() {
+	h.registers = make([]uint8, 1<<uint(h.precision))
+	for _, list := range [][]uint32{h.sparse, h.buffer} {
+		for _, packed := range list {
+			idx, rho := unpack(packed)
+			denseIdx, denseRho := toDense(idx, rho, h.precision)
+			if denseRho > h.registers[denseIdx] {
+				h.registers[denseIdx] = denseRho
+			}
+		}
+	}
+	h.sparse = nil
+	h.buffer = nil
+}
+
+// pack packs the index and value of a register at the sparse precision into 4 bytes,
+// such that packed registers are sorted by their indices and then by their values.
+func pack(idx uint32, rho uint8) uint32 {
+	return idx<<rhoBits | uint32(rho)
+}
+
+// unpack undoes pack.
+func unpack(packed uint32) (uint32, uint8) {
+	return packed >> rhoBits, uint8(packed & (1<<rhoBits - 1))
+}
+
+// mergeSparse merges two sorted lists of packed registers into a new sorted list,
+// keeping only the register with the largest value for each index.
+func mergeSparse(xs []uint32, ys []uint32) []uint32 {
+	merged := make([]uint32, 0, len(xs)+len(ys))
+	for i, j := 0, 0; i < len(xs) || j < len(ys); {
+		var next uint32
+		if j == len(ys) || (i < len(xs) && xs[i] < ys[j]) {
+			next = xs[i]
+			i++
+		} else {
+			next = ys[j]
+			j++
+		}
+
+		// registers with the same index are adjacent, with the largest value last
+		if n := len(merged); n > 0 && merged[n-1]>>rhoBits == next>>rhoBits {
+			merged[n-1] = next
+		} else {
+			merged = append(merged, next)
+		}
+	}
+	return merged
+}
+
+// split returns the index of the register that the hashed value x belongs to
+// for the given precision, i.e. its first p bits, along with the value of the
+// register for x, i.e. one more than the number of leading zeros of its remaining bits.
+func split(x uint64, p int) (uint32, uint8) {
+	idx := uint32(x >> uint(64-p))
+	rho := bits.LeadingZeros64(x<<uint(p)) + 1
+	if limit := 64 - p + 1; rho > limit {
+		rho = limit
+	}
+	return idx, uint8(rho)
+}
+
+// toDense converts the index and value of a register at the sparse precision
+// into the index and value of the register at precision p.
+func toDense(idx uint32, rho uint8, p int) (uint32, uint8) {
+	shift := uint(sparsePrecision - p)
+	denseIdx := idx >> shift
+	if rest := idx & (1<<shift - 1); rest != 0 {
+		// the leading zeros are within the bits between the two precisions
+		return denseIdx, uint8(bits.LeadingZeros32(rest<<(32-shift)) + 1)
+	}
+	return denseIdx, uint8(shift) + rho
+}
+
+// Value returns the estimated number of distinct values in the HyperLogLog.
+func (h *HyperLogLog) Value() (float64, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.flush()
+	return h.estimate(), nil
+}
+
+// estimate returns the estimated number of distinct values, without locking;
+// the buffer of the sparse representation must be flushed beforehand.
+func (h *HyperLogLog) estimate() float64 {
+	if h.registers == nil {
+		// linear counting on the registers at the sparse precision, which is
+		// nearly exact since there are far more registers than values
+		m := float64(uint64(1) << sparsePrecision)
+		return m * math.Log(m/(m-float64(len(h.sparse))))
+	}
+
+	q := 64 - h.precision
+	counts := make([]int, q+2)
+	for _, rho := range h.registers {
+		counts[rho]++
+	}
+
+	m := float64(len(h.registers))
+	z := m * tau(1-float64(counts[q+1])/m)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + float64(counts[k]))
+	}
+	z += m * sigma(float64(counts[0])/m)
+
+	return m * m / (2 * math.Ln2 * z)
+}
+
+// sigma is the function used by Ertl's estimator to correct for empty registers.
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+
+	y := 1.
+	z := x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+// tau is the function used by Ertl's estimator to correct for saturated registers.
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+
+	y := 1.
+	z := 1 - x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
+
+// Merge merges the values of another HyperLogLog into the HyperLogLog, so that
+// it estimates the number of distinct values across both HyperLogLogs; the other
+// HyperLogLog is unchanged. Both HyperLogLogs must have the same precision.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.precision != other.precision {
+		return errors.Errorf(
+			"cannot merge HyperLogLog with precision %d into HyperLogLog with precision %d",
+			other.precision,
+			h.precision,
+		)
+	}
+
+	// copy the other HyperLogLog's registers first, which allows for a HyperLogLog to be merged with itself
+	other.mux.RLock()
+	sparse := make([]uint32, 0, len(other.sparse)+len(other.buffer))
+	sparse = append(append(sparse, other.sparse...), other.buffer...)
+	registers := append([]uint8(nil), other.registers...)
+	isSparse := other.registers == nil
+	other.mux.RUnlock()
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if isSparse {
+		if h.registers == nil {
+			h.buffer = append(h.buffer, sparse...)
+			h.flush()
+			return nil
+		}
+
+		for _, packed := range sparse {
+			idx, rho := unpack(packed)
+			denseIdx, denseRho := toDense(idx, rho, h.precision)
+			if denseRho > h.registers[denseIdx] {
+				h.registers[denseIdx] = denseRho
+			}
+		}
+		return nil
+	}
+
+	if h.registers == nil {
+		h.densify()
+	}
+	for idx, rho := range registers {
+		if rho > h.registers[idx] {
+			h.registers[idx] = rho
+		}
+	}
+
+	return nil
+}
+
+// Clear resets the metric.
+func (h *HyperLogLog) Clear() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.sparse = nil
+	h.buffer = nil
+	h.registers = nil
+}
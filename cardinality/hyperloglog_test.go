@@ -0,0 +1,261 @@
+package cardinality
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/aggregate"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("pass: valid HyperLogLog is valid", func(t *testing.T) {
+		h, err := New(10)
+		require.NoError(t, err)
+		assert.Equal(t, 10, h.Precision())
+		assert.Empty(t, h.sparse)
+		assert.Nil(t, h.registers)
+		assert.Equal(t, "cardinality.HyperLogLog_{precision:10}", h.String())
+	})
+
+	t.Run("fail: precision out of range is invalid", func(t *testing.T) {
+		_, err := New(3)
+		testutil.ContainsError(t, err, "precision 3 not in [4, 18]")
+
+		_, err = New(19)
+		testutil.ContainsError(t, err, "precision 19 not in [4, 18]")
+	})
+}
+
+func TestNewDefault(t *testing.T) {
+	h, err := New(DefaultPrecision)
+	require.NoError(t, err)
+	assert.Equal(t, h, NewDefault())
+}
+
+func TestValue(t *testing.T) {
+	t.Run("pass: empty HyperLogLog has no values", func(t *testing.T) {
+		h := NewDefault()
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+
+		h.densify()
+		value, err = h.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+	})
+
+	for _, n := range []int{10, 1000, 100000, 1000000} {
+		t.Run(fmt.Sprintf("pass: estimate is accurate for %d values", n), func(t *testing.T) {
+			h := NewDefault()
+			for i := 0; i < n; i++ {
+				h.PushString(strconv.Itoa(i))
+				// duplicates do not affect the estimate
+				h.PushString(strconv.Itoa(i / 2))
+			}
+
+			value, err := h.Value()
+			require.NoError(t, err)
+
+			// allow for 3 standard errors, where the sparse representation is nearly exact
+			tolerance := 3 * 1.04 / math.Sqrt(float64(uint64(1)<<uint(DefaultPrecision)))
+			if h.registers == nil {
+				tolerance = 0.001
+			}
+			assert.InEpsilon(t, float64(n), value, tolerance)
+		})
+	}
+
+	t.Run("pass: estimate is accurate for low precisions", func(t *testing.T) {
+		h, err := New(MinPrecision)
+		require.NoError(t, err)
+
+		for i := 0; i < 10000; i++ {
+			err = h.Push(float64(i))
+			require.NoError(t, err)
+		}
+
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.InEpsilon(t, 10000., value, 3*1.04/4)
+	})
+}
+
+func TestPush(t *testing.T) {
+	t.Run("pass: zeros are counted as the same value", func(t *testing.T) {
+		h := NewDefault()
+		err := h.Push(0)
+		require.NoError(t, err)
+		err = h.Push(math.Copysign(0, -1))
+		require.NoError(t, err)
+
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.InEpsilon(t, 1., value, 1e-6)
+	})
+
+	t.Run("pass: strings and bytes are counted as the same value", func(t *testing.T) {
+		h := NewDefault()
+		h.PushString("192.168.0.1")
+		h.PushBytes([]byte("192.168.0.1"))
+
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.InEpsilon(t, 1., value, 1e-6)
+	})
+
+	t.Run("fail: NaN is invalid", func(t *testing.T) {
+		err := NewDefault().Push(math.NaN())
+		testutil.ContainsError(t, err, "attempted to push NaN")
+	})
+}
+
+func TestDensify(t *testing.T) {
+	// a HyperLogLog that starts out sparse ends up with the same registers
+	// as one that starts out dense
+	h, err := New(10)
+	require.NoError(t, err)
+	dense, err := New(10)
+	require.NoError(t, err)
+	dense.densify()
+
+	for i := 0; i < 10000; i++ {
+		h.PushString(strconv.Itoa(i))
+		dense.PushString(strconv.Itoa(i))
+	}
+
+	assert.Nil(t, h.sparse)
+	assert.Equal(t, dense.registers, h.registers)
+}
+
+func TestFlush(t *testing.T) {
+	t.Run("pass: sparse registers take up no more memory than dense registers", func(t *testing.T) {
+		h, err := New(10)
+		require.NoError(t, err)
+
+		for i := 0; h.registers == nil; i++ {
+			h.PushString(strconv.Itoa(i))
+			// each packed register takes up 4 bytes, and each dense register takes up 1 byte
+			assert.True(t, 4*(len(h.sparse)+len(h.buffer)) <= 1<<10)
+		}
+	})
+
+	t.Run("pass: buffered registers are merged into sorted registers", func(t *testing.T) {
+		h, err := New(10)
+		require.NoError(t, err)
+
+		// by hand, such that the buffer holds a duplicate index with a smaller value
+		h.buffer = []uint32{pack(7, 3), pack(2, 1), pack(7, 5), pack(2, 4), pack(9, 2)}
+		h.sparse = []uint32{pack(2, 2), pack(5, 1), pack(9, 6)}
+		h.flush()
+
+		assert.Equal(t, []uint32{pack(2, 4), pack(5, 1), pack(7, 5), pack(9, 6)}, h.sparse)
+		assert.Empty(t, h.buffer)
+		assert.Nil(t, h.registers)
+	})
+
+	t.Run("pass: estimate includes buffered registers", func(t *testing.T) {
+		h := NewDefault()
+		for i := 0; i < 10; i++ {
+			h.PushString(strconv.Itoa(i))
+		}
+		assert.Len(t, h.buffer, 10)
+
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.InEpsilon(t, 10., value, 1e-3)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	for _, sizes := range [][2]int{{10, 20}, {10, 100000}, {100000, 10}, {100000, 200000}} {
+		t.Run(fmt.Sprintf("pass: merged estimate is accurate for %v values", sizes), func(t *testing.T) {
+			h := NewDefault()
+			other := NewDefault()
+			for i := 0; i < sizes[0]; i++ {
+				h.PushString(strconv.Itoa(i))
+			}
+			// the values of the other HyperLogLog overlap with half of the values
+			offset := sizes[0] / 2
+			for i := 0; i < sizes[1]; i++ {
+				other.PushString(strconv.Itoa(offset + i))
+			}
+
+			otherValue, err := other.Value()
+			require.NoError(t, err)
+
+			err = h.Merge(other)
+			require.NoError(t, err)
+
+			value, err := h.Value()
+			require.NoError(t, err)
+			union := offset + sizes[1]
+			if sizes[0] > union {
+				union = sizes[0]
+			}
+			assert.InEpsilon(t, float64(union), value, 0.03)
+
+			// the other HyperLogLog is unchanged
+			unchanged, err := other.Value()
+			require.NoError(t, err)
+			assert.Equal(t, otherValue, unchanged)
+		})
+	}
+
+	t.Run("pass: HyperLogLog can be merged with itself", func(t *testing.T) {
+		h := NewDefault()
+		for i := 0; i < 100000; i++ {
+			h.PushString(strconv.Itoa(i))
+		}
+
+		expected, err := h.Value()
+		require.NoError(t, err)
+
+		err = h.Merge(h)
+		require.NoError(t, err)
+
+		value, err := h.Value()
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	})
+
+	t.Run("fail: precisions must match", func(t *testing.T) {
+		h, err := New(10)
+		require.NoError(t, err)
+
+		err = h.Merge(NewDefault())
+		testutil.ContainsError(t, err, "cannot merge HyperLogLog with precision 14 into HyperLogLog with precision 10")
+	})
+}
+
+func TestClear(t *testing.T) {
+	h := NewDefault()
+	for i := 0; i < 100000; i++ {
+		h.PushString(strconv.Itoa(i))
+	}
+
+	h.Clear()
+	assert.Equal(t, NewDefault(), h)
+}
+
+func TestAggregate(t *testing.T) {
+	var _ stream.SimpleMetric = NewDefault()
+
+	h := NewDefault()
+	metric := aggregate.NewSimpleAggregateMetric(h)
+	for i := 0.; i < 10; i++ {
+		err := metric.Push(math.Mod(i, 4))
+		require.NoError(t, err)
+	}
+
+	values, err := metric.Values()
+	require.NoError(t, err)
+	assert.InEpsilon(t, 4., values[h.String()], 1e-6)
+}
@@ -0,0 +1,26 @@
+package stream
+
+import "time"
+
+// Clock is the interface for a source of the current time; this is used by
+// metrics that track values over a time-based window, and can be replaced
+// in order to control time (e.g. for testing, or for replaying a stream).
+//
+// Such metrics remove expired values relative to the current time of their
+// Clock when they are read, not only when values are pushed; as a result, the
+// times passed to PushAt must be on the same timeline as the Clock. For example,
+// replaying events from last year against SystemClock would find all of them
+// expired on read, so a Clock that reports the time of the replayed events
+// should be used instead.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is a Clock that reports the current system time.
+var SystemClock Clock = systemClock{}
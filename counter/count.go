@@ -0,0 +1,87 @@
+package counter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexander-yu/stream"
+)
+
+// Count keeps track of the number of values of a stream.
+type Count struct {
+	mux     sync.Mutex
+	tracker *tracker
+}
+
+// NewCount instantiates a Count struct.
+func NewCount(window int) (*Count, error) {
+	if err := validate(window, 0, false); err != nil {
+		return nil, err
+	}
+	return &Count{tracker: newTracker(window, 0, nil)}, nil
+}
+
+// NewTimedCount instantiates a Count struct that tracks values over a
+// time-based window, where values are removed once they are at least
+// the provided duration older than the current time of the provided
+// Clock, which defaults to stream.SystemClock if nil. Values pushed
+// without an explicit time are timestamped with the Clock as well.
+func NewTimedCount(duration time.Duration, clock stream.Clock) (*Count, error) {
+	if err := validate(0, duration, true); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &Count{tracker: newTracker(0, duration, clock)}, nil
+}
+
+// NewGlobalCount instantiates a global Count struct.
+// This is equivalent to calling NewCount(0).
+func NewGlobalCount() *Count {
+	return &Count{tracker: newTracker(0, 0, nil)}
+}
+
+// String returns a string representation of the metric.
+func (c *Count) String() string {
+	name := "counter.Count"
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(c.tracker.params(), ","))
+}
+
+// Push adds a number for counting.
+func (c *Count) Push(x float64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.tracker.push(x, c.tracker.now())
+}
+
+// PushAt adds a number for counting, which was observed at the provided
+// time. This is only meaningful if the Count tracks values over a time-based
+// window (see NewTimedCount), in which case values at or before t - duration
+// are removed from the window; otherwise the time is ignored. Values must be
+// pushed in chronological order, on the timeline of the Clock (see stream.Clock).
+func (c *Count) PushAt(x float64, t time.Time) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.tracker.push(x, t)
+}
+
+// Value returns the number of values seen (or in the window). If the Count
+// tracks values over a time-based window, expired values are removed first.
+func (c *Count) Value() (float64, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.tracker.expire(c.tracker.now())
+	return float64(c.tracker.count), nil
+}
+
+// Clear resets the metric.
+func (c *Count) Clear() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.tracker.clear()
+}
@@ -0,0 +1,112 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewCount(t *testing.T) {
+	t.Run("pass: valid Count is valid", func(t *testing.T) {
+		count, err := NewCount(3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count.tracker.window)
+		assert.Equal(t, "counter.Count_{window:3}", count.String())
+	})
+
+	t.Run("fail: negative window returns error", func(t *testing.T) {
+		_, err := NewCount(-1)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+}
+
+func TestNewTimedCount(t *testing.T) {
+	t.Run("pass: valid Count is valid", func(t *testing.T) {
+		count, err := NewTimedCount(time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "counter.Count_{window:0,duration:1m0s}", count.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedCount(-time.Second, nil)
+		testutil.ContainsError(t, err, "-1s is a nonpositive duration")
+	})
+}
+
+func TestNewGlobalCount(t *testing.T) {
+	count, err := NewCount(0)
+	require.NoError(t, err)
+	assert.Equal(t, count, NewGlobalCount())
+}
+
+func TestCountValue(t *testing.T) {
+	t.Run("pass: global Count counts all values", func(t *testing.T) {
+		count := NewGlobalCount()
+		for _, x := range []float64{1, 2, 3, 4, 5} {
+			err := count.Push(x)
+			require.NoError(t, err)
+		}
+
+		value, err := count.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 5., value)
+	})
+
+	t.Run("pass: windowed Count counts values in the window", func(t *testing.T) {
+		count, err := NewCount(3)
+		require.NoError(t, err)
+
+		expected := []float64{1, 2, 3, 3, 3}
+		for i, x := range []float64{1, 2, 3, 4, 5} {
+			err := count.Push(x)
+			require.NoError(t, err)
+
+			value, err := count.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+	})
+
+	t.Run("pass: timed Count counts values in the window", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start)
+		count, err := NewTimedCount(10*time.Second, clock)
+		require.NoError(t, err)
+
+		expected := []float64{1, 2, 3, 3, 3, 3}
+		offsets := []int{0, 2, 4, 10, 12, 14}
+		for i, offset := range offsets {
+			at := start.Add(time.Duration(offset) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := count.PushAt(1, at)
+			require.NoError(t, err)
+
+			value, err := count.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+
+		// values are also removed as time passes without any values being pushed
+		clock.Advance(time.Hour)
+		value, err := count.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+	})
+}
+
+func TestCountClear(t *testing.T) {
+	count := NewGlobalCount()
+	for _, x := range []float64{1, 2, 3} {
+		err := count.Push(x)
+		require.NoError(t, err)
+	}
+
+	count.Clear()
+	value, err := count.Value()
+	require.NoError(t, err)
+	assert.Equal(t, 0., value)
+}
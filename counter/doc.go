@@ -0,0 +1,8 @@
+// Package counter provides a library of metrics for calculating the online
+// sum, count, or per-second rate of a stream of data, either globally, over
+// a window of the most recent values, or over a time-based window.
+//
+// Sums are calculated with Neumaier's variant of Kahan summation, which keeps
+// track of the rounding error of each addition, so that long-running sums
+// do not drift; see KahanSum for more details.
+package counter
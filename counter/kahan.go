@@ -0,0 +1,39 @@
+package counter
+
+import "math"
+
+// KahanSum is a running sum that is calculated with Neumaier's variant of Kahan
+// summation, which compensates for the rounding error of each addition. The error
+// of the sum is independent of the number of values added, whereas the error of
+// naive summation grows with the number of values. Its zero value is an empty sum.
+type KahanSum struct {
+	sum          float64
+	compensation float64
+}
+
+// Add adds a value to the sum.
+func (k *KahanSum) Add(x float64) {
+	t := k.sum + x
+	if math.Abs(k.sum) >= math.Abs(x) {
+		k.compensation += (k.sum - t) + x
+	} else {
+		k.compensation += (x - t) + k.sum
+	}
+	k.sum = t
+}
+
+// Value returns the value of the sum.
+func (k *KahanSum) Value() float64 {
+	// the compensation is NaN once an infinite value is added, in which case
+	// the uncompensated sum is already correct
+	if math.IsInf(k.sum, 0) {
+		return k.sum
+	}
+	return k.sum + k.compensation
+}
+
+// Reset resets the sum to 0.
+func (k *KahanSum) Reset() {
+	k.sum = 0
+	k.compensation = 0
+}
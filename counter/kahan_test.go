@@ -0,0 +1,44 @@
+package counter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKahanSum(t *testing.T) {
+	t.Run("pass: sum does not drift", func(t *testing.T) {
+		var k KahanSum
+		naive := 0.
+		for i := 0; i < 10000000; i++ {
+			k.Add(0.1)
+			naive += 0.1
+		}
+
+		assert.Equal(t, 1e6, k.Value())
+		assert.NotEqual(t, 1e6, naive)
+	})
+
+	t.Run("pass: small values are not lost when added to large values", func(t *testing.T) {
+		var k KahanSum
+		for _, x := range []float64{1, 1e100, 1, -1e100} {
+			k.Add(x)
+		}
+		assert.Equal(t, 2., k.Value())
+	})
+
+	t.Run("pass: infinite values propagate", func(t *testing.T) {
+		var k KahanSum
+		k.Add(1)
+		k.Add(math.Inf(1))
+		assert.Equal(t, math.Inf(1), k.Value())
+	})
+
+	t.Run("pass: reset sum is empty", func(t *testing.T) {
+		var k KahanSum
+		k.Add(1)
+		k.Reset()
+		assert.Equal(t, 0., k.Value())
+	})
+}
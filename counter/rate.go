@@ -0,0 +1,112 @@
+package counter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// Rate keeps track of the per-second rate of a stream, i.e. the sum of its
+// values per second; pushing 1 for each event gives the number of events per second.
+type Rate struct {
+	mux     sync.Mutex
+	tracker *tracker
+}
+
+// NewRate instantiates a Rate struct. If the window is full, the rate is the
+// sum of the values in the window over the time since the most recently removed
+// value was pushed; otherwise, it is the sum of the values over the time since
+// the Rate was instantiated (or cleared). Times are provided by the Clock, which
+// defaults to stream.SystemClock if nil.
+func NewRate(window int, clock stream.Clock) (*Rate, error) {
+	if err := validate(window, 0, false); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &Rate{tracker: newTracker(window, 0, clock)}, nil
+}
+
+// NewTimedRate instantiates a Rate struct that tracks values over a time-based
+// window, where the rate is the sum of the values pushed within the provided
+// duration of the current time, over that duration (or over the time since the
+// Rate was instantiated, if shorter). Values pushed without an explicit time are
+// timestamped with the provided Clock, which defaults to stream.SystemClock if nil.
+func NewTimedRate(duration time.Duration, clock stream.Clock) (*Rate, error) {
+	if err := validate(0, duration, true); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &Rate{tracker: newTracker(0, duration, clock)}, nil
+}
+
+// NewGlobalRate instantiates a global Rate struct, whose rate is the sum of
+// the values over the time since the Rate was instantiated (or cleared).
+// This is equivalent to calling NewRate(0, clock).
+func NewGlobalRate(clock stream.Clock) *Rate {
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &Rate{tracker: newTracker(0, 0, clock)}
+}
+
+// String returns a string representation of the metric.
+func (r *Rate) String() string {
+	name := "counter.Rate"
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(r.tracker.params(), ","))
+}
+
+// Push adds a number for calculating the rate.
+func (r *Rate) Push(x float64) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.tracker.push(x, r.tracker.now())
+}
+
+// PushAt adds a number for calculating the rate, which was observed at
+// the provided time. Values must be pushed in chronological order, on the
+// timeline of the Clock (see stream.Clock).
+func (r *Rate) PushAt(x float64, t time.Time) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.tracker.push(x, t)
+}
+
+// Value returns the value of the rate, as of the current time of the Clock.
+func (r *Rate) Value() (float64, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	now := r.tracker.clock.Now()
+	elapsed := now.Sub(r.tracker.start)
+	r.tracker.expire(now)
+	if r.tracker.duration != 0 && elapsed > r.tracker.duration {
+		elapsed = r.tracker.duration
+	}
+
+	if elapsed <= 0 {
+		return 0, errors.New("no time has elapsed yet")
+	}
+
+	return r.tracker.sum.Value() / elapsed.Seconds(), nil
+}
+
+// Clear resets the metric.
+func (r *Rate) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.tracker.clear()
+}
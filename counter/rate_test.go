@@ -0,0 +1,147 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewRate(t *testing.T) {
+	t.Run("pass: valid Rate is valid", func(t *testing.T) {
+		rate, err := NewRate(3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, rate.tracker.window)
+		assert.Equal(t, "counter.Rate_{window:3}", rate.String())
+	})
+
+	t.Run("fail: negative window returns error", func(t *testing.T) {
+		_, err := NewRate(-1, nil)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+}
+
+func TestNewTimedRate(t *testing.T) {
+	t.Run("pass: valid Rate is valid", func(t *testing.T) {
+		rate, err := NewTimedRate(time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "counter.Rate_{window:0,duration:1m0s}", rate.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedRate(0, nil)
+		testutil.ContainsError(t, err, "0s is a nonpositive duration")
+	})
+}
+
+func TestNewGlobalRate(t *testing.T) {
+	clock := testutil.NewClock(time.Unix(1000, 0))
+	rate, err := NewRate(0, clock)
+	require.NoError(t, err)
+	assert.Equal(t, rate, NewGlobalRate(clock))
+}
+
+func TestRateValue(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: global Rate is the sum over the elapsed time", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		rate := NewGlobalRate(clock)
+
+		for i := 0; i < 10; i++ {
+			clock.Advance(time.Second)
+			err := rate.Push(3)
+			require.NoError(t, err)
+		}
+
+		value, err := rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 3., value)
+
+		clock.Advance(20 * time.Second)
+		value, err = rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 1., value)
+	})
+
+	t.Run("pass: windowed Rate is the sum over the time since the last removed value", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		rate, err := NewRate(4, clock)
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			clock.Advance(time.Second)
+			err := rate.Push(1)
+			require.NoError(t, err)
+		}
+		// speed up to 2 values per second
+		for i := 0; i < 4; i++ {
+			clock.Advance(500 * time.Millisecond)
+			err := rate.Push(1)
+			require.NoError(t, err)
+		}
+
+		value, err := rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 2., value)
+	})
+
+	t.Run("pass: timed Rate removes values as time passes", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		rate, err := NewTimedRate(10*time.Second, clock)
+		require.NoError(t, err)
+
+		// the rate is over the elapsed time until the duration has passed
+		clock.Advance(5 * time.Second)
+		err = rate.Push(10)
+		require.NoError(t, err)
+
+		value, err := rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 2., value)
+
+		clock.Advance(5 * time.Second)
+		err = rate.Push(10)
+		require.NoError(t, err)
+
+		value, err = rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 2., value)
+
+		clock.Advance(5 * time.Second)
+		value, err = rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 1., value)
+
+		clock.Advance(5 * time.Second)
+		value, err = rate.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+	})
+
+	t.Run("fail: Rate with no elapsed time returns error", func(t *testing.T) {
+		rate := NewGlobalRate(testutil.NewClock(start))
+		_, err := rate.Value()
+		testutil.ContainsError(t, err, "no time has elapsed yet")
+	})
+}
+
+func TestRateClear(t *testing.T) {
+	clock := testutil.NewClock(time.Unix(1000, 0))
+	rate := NewGlobalRate(clock)
+
+	clock.Advance(time.Second)
+	err := rate.Push(1)
+	require.NoError(t, err)
+
+	rate.Clear()
+	assert.Equal(t, clock.Now(), rate.tracker.start)
+
+	clock.Advance(time.Second)
+	value, err := rate.Value()
+	require.NoError(t, err)
+	assert.Equal(t, 0., value)
+}
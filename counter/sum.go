@@ -0,0 +1,88 @@
+package counter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexander-yu/stream"
+)
+
+// Sum keeps track of the sum of a stream.
+type Sum struct {
+	mux     sync.Mutex
+	tracker *tracker
+}
+
+// NewSum instantiates a Sum struct.
+func NewSum(window int) (*Sum, error) {
+	if err := validate(window, 0, false); err != nil {
+		return nil, err
+	}
+	return &Sum{tracker: newTracker(window, 0, nil)}, nil
+}
+
+// NewTimedSum instantiates a Sum struct that tracks values over a
+// time-based window, where values are removed once they are at least
+// the provided duration older than the current time of the provided
+// Clock, which defaults to stream.SystemClock if nil. Values pushed
+// without an explicit time are timestamped with the Clock as well.
+func NewTimedSum(duration time.Duration, clock stream.Clock) (*Sum, error) {
+	if err := validate(0, duration, true); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &Sum{tracker: newTracker(0, duration, clock)}, nil
+}
+
+// NewGlobalSum instantiates a global Sum struct.
+// This is equivalent to calling NewSum(0).
+func NewGlobalSum() *Sum {
+	return &Sum{tracker: newTracker(0, 0, nil)}
+}
+
+// String returns a string representation of the metric.
+func (s *Sum) String() string {
+	name := "counter.Sum"
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(s.tracker.params(), ","))
+}
+
+// Push adds a number for calculating the sum.
+func (s *Sum) Push(x float64) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.tracker.push(x, s.tracker.now())
+}
+
+// PushAt adds a number for calculating the sum, which was observed at
+// the provided time. This is only meaningful if the Sum tracks values over
+// a time-based window (see NewTimedSum), in which case values at or before
+// t - duration are removed from the window; otherwise the time is ignored.
+// Values must be pushed in chronological order, on the timeline of the Clock
+// (see stream.Clock).
+func (s *Sum) PushAt(x float64, t time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.tracker.push(x, t)
+}
+
+// Value returns the value of the sum, which is 0 if no values have been seen.
+// If the Sum tracks values over a time-based window, expired values are removed first.
+func (s *Sum) Value() (float64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.tracker.expire(s.tracker.now())
+	return s.tracker.sum.Value(), nil
+}
+
+// Clear resets the metric.
+func (s *Sum) Clear() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.tracker.clear()
+}
@@ -0,0 +1,207 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/aggregate"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewSum(t *testing.T) {
+	t.Run("pass: valid Sum is valid", func(t *testing.T) {
+		sum, err := NewSum(3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, sum.tracker.window)
+		assert.Equal(t, uint64(0), sum.tracker.queue.Len())
+		assert.Equal(t, "counter.Sum_{window:3}", sum.String())
+	})
+
+	t.Run("fail: negative window returns error", func(t *testing.T) {
+		_, err := NewSum(-1)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+}
+
+func TestNewTimedSum(t *testing.T) {
+	t.Run("pass: valid Sum is valid", func(t *testing.T) {
+		sum, err := NewTimedSum(time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, sum.tracker.duration)
+		assert.Equal(t, stream.SystemClock, sum.tracker.clock)
+		assert.Equal(t, "counter.Sum_{window:0,duration:1m0s}", sum.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedSum(0, nil)
+		testutil.ContainsError(t, err, "0s is a nonpositive duration")
+	})
+}
+
+func TestNewGlobalSum(t *testing.T) {
+	sum, err := NewSum(0)
+	require.NoError(t, err)
+	assert.Equal(t, sum, NewGlobalSum())
+}
+
+func TestSumValue(t *testing.T) {
+	t.Run("pass: empty Sum is 0", func(t *testing.T) {
+		value, err := NewGlobalSum().Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+	})
+
+	t.Run("pass: global Sum sums all values", func(t *testing.T) {
+		sum := NewGlobalSum()
+		for _, x := range []float64{1, 2, 3, 4, 5} {
+			err := sum.Push(x)
+			require.NoError(t, err)
+		}
+
+		value, err := sum.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 15., value)
+	})
+
+	t.Run("pass: windowed Sum sums values in the window", func(t *testing.T) {
+		sum, err := NewSum(3)
+		require.NoError(t, err)
+
+		expected := []float64{1, 3, 6, 9, 12}
+		for i, x := range []float64{1, 2, 3, 4, 5} {
+			err := sum.Push(x)
+			require.NoError(t, err)
+
+			value, err := sum.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+	})
+
+	t.Run("pass: windowed Sum does not drift", func(t *testing.T) {
+		sum, err := NewSum(10)
+		require.NoError(t, err)
+
+		for i := 0; i < 1000000; i++ {
+			err := sum.Push(0.1)
+			require.NoError(t, err)
+		}
+
+		value, err := sum.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 1., value)
+	})
+}
+
+func TestSumPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		sum, err := NewTimedSum(10*time.Second, clock)
+		require.NoError(t, err)
+
+		vals := []float64{1, 6, 4, 9, 2, 8}
+		expected := []float64{1, 7, 11, 19, 15, 19}
+		offsets := []int{0, 2, 4, 10, 12, 14}
+		for i, val := range vals {
+			at := start.Add(time.Duration(offsets[i]) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := sum.PushAt(val, at)
+			require.NoError(t, err)
+
+			value, err := sum.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		sum, err := NewTimedSum(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, val := range []float64{1, 6, 4} {
+			err := sum.Push(val)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		value, err := sum.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4., value)
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		sum, err := NewTimedSum(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, val := range []float64{1, 6, 4} {
+			err := sum.Push(val)
+			require.NoError(t, err)
+		}
+
+		value, err := sum.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 11., value)
+
+		clock.Advance(time.Hour)
+		value, err = sum.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 0., value)
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		sum, err := NewTimedSum(time.Minute, nil)
+		require.NoError(t, err)
+
+		err = sum.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = sum.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
+func TestSumAggregate(t *testing.T) {
+	sum, err := NewSum(2)
+	require.NoError(t, err)
+	count, err := NewCount(2)
+	require.NoError(t, err)
+
+	metric := aggregate.NewSimpleAggregateMetric(sum, count)
+
+	for _, x := range []float64{1, 2, 3} {
+		err := metric.Push(x)
+		require.NoError(t, err)
+	}
+
+	values, err := metric.Values()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"counter.Sum_{window:2}":   5.,
+		"counter.Count_{window:2}": 2.,
+	}, values)
+}
+
+func TestSumClear(t *testing.T) {
+	sum, err := NewSum(3)
+	require.NoError(t, err)
+
+	for _, x := range []float64{1, 2, 3, 4} {
+		err := sum.Push(x)
+		require.NoError(t, err)
+	}
+
+	sum.Clear()
+	value, err := sum.Value()
+	require.NoError(t, err)
+	assert.Equal(t, 0., value)
+	assert.Equal(t, 0, sum.tracker.count)
+	assert.Equal(t, uint64(0), sum.tracker.queue.Len())
+}
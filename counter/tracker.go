@@ -0,0 +1,147 @@
+package counter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gammazero/deque"
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
+)
+
+// timedValue is a value in a window, along with the time it was pushed at.
+type timedValue struct {
+	x float64
+	t time.Time
+}
+
+// tracker keeps track of the sum and count of the values of a stream, either
+// globally, over a window of the most recent values, or over a time-based window.
+// It is not thread-safe, and is shared by the metrics of this package.
+type tracker struct {
+	window int
+	// Used if window > 0
+	queue *queue.RingBuffer
+	// Used if duration > 0
+	duration time.Duration
+	timed    *deque.Deque[timedValue]
+	latest   time.Time
+	// Used if the times of values are needed, i.e. if the window is time-based,
+	// or if a rate is calculated; values are otherwise pushed with no time
+	clock stream.Clock
+	start time.Time
+	sum   KahanSum
+	count int
+}
+
+func newTracker(window int, duration time.Duration, clock stream.Clock) *tracker {
+	t := &tracker{
+		window:   window,
+		queue:    queue.NewRingBuffer(uint64(window)),
+		duration: duration,
+		timed:    new(deque.Deque[timedValue]),
+		clock:    clock,
+	}
+	if clock != nil {
+		t.start = clock.Now()
+	}
+	return t
+}
+
+// now returns the time to push a value at.
+func (t *tracker) now() time.Time {
+	if t.clock == nil {
+		return time.Time{}
+	}
+	return t.clock.Now()
+}
+
+func (t *tracker) push(x float64, at time.Time) error {
+	if t.duration != 0 {
+		if at.Before(t.latest) {
+			return errors.Errorf("time %v is before the latest time %v", at, t.latest)
+		}
+
+		t.evict(at.Add(-t.duration))
+		t.timed.PushBack(timedValue{x: x, t: at})
+		t.latest = at
+	} else if t.window != 0 {
+		if t.queue.Len() == uint64(t.window) {
+			val, err := t.queue.Get()
+			if err != nil {
+				return errors.Wrap(err, "error popping item from queue")
+			}
+
+			// the values in the window have all been pushed since the evicted value
+			evicted := val.(timedValue)
+			t.remove(evicted.x)
+			t.start = evicted.t
+		}
+
+		err := t.queue.Put(timedValue{x: x, t: at})
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %f to queue", x)
+		}
+	}
+
+	t.sum.Add(x)
+	t.count++
+	return nil
+}
+
+// expire removes the values of a time-based window that have fallen out of the
+// window as of now, so that reads do not include stale values after a period
+// without pushes; this is a no-op for other windows.
+func (t *tracker) expire(now time.Time) {
+	if t.duration != 0 {
+		t.evict(now.Add(-t.duration))
+	}
+}
+
+// evict removes the values of a time-based window that were pushed at or before the cutoff.
+func (t *tracker) evict(cutoff time.Time) {
+	for t.timed.Len() > 0 && !t.timed.Front().t.After(cutoff) {
+		t.remove(t.timed.PopFront().x)
+	}
+}
+
+func (t *tracker) remove(x float64) {
+	t.count--
+	if t.count == 0 {
+		// reset the sum, so that no rounding error is carried over
+		t.sum.Reset()
+	} else {
+		t.sum.Add(-x)
+	}
+}
+
+func (t *tracker) clear() {
+	t.queue.Dispose()
+	t.queue = queue.NewRingBuffer(uint64(t.window))
+	t.timed.Clear()
+	t.latest = time.Time{}
+	t.start = t.now()
+	t.sum.Reset()
+	t.count = 0
+}
+
+// validate returns an error if a window or duration is invalid.
+func validate(window int, duration time.Duration, timed bool) error {
+	if timed && duration <= 0 {
+		return errors.Errorf("%v is a nonpositive duration", duration)
+	} else if window < 0 {
+		return errors.Errorf("%d is a negative window", window)
+	}
+	return nil
+}
+
+// params returns the parameters of a metric for its string representation.
+func (t *tracker) params() []string {
+	params := []string{fmt.Sprintf("window:%v", t.window)}
+	if t.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", t.duration))
+	}
+	return params
+}
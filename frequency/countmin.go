@@ -0,0 +1,196 @@
+package frequency
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream/util/hash"
+)
+
+// CountMin keeps track of the approximate counts of the values of a stream with
+// a Count-Min sketch; see the package documentation for its error bounds.
+type CountMin struct {
+	window  int
+	epsilon float64
+	delta   float64
+	width   int
+	table   [][]int
+	queue   *queue.RingBuffer
+	count   int
+	mux     sync.RWMutex
+}
+
+// NewCountMin instantiates a CountMin struct, whose estimated counts overestimate
+// the true counts by at most epsilon times the number of values with probability
+// at least 1 - delta; both must be in (0, 1).
+func NewCountMin(window int, epsilon float64, delta float64) (*CountMin, error) {
+	if window < 0 {
+		return nil, errors.Errorf("%d is a negative window", window)
+	} else if epsilon <= 0 || epsilon >= 1 {
+		return nil, errors.Errorf("epsilon %f not in (0, 1)", epsilon)
+	} else if delta <= 0 || delta >= 1 {
+		return nil, errors.Errorf("delta %f not in (0, 1)", delta)
+	}
+
+	width := int(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	table := make([][]int, depth)
+	for i := range table {
+		table[i] = make([]int, width)
+	}
+
+	return &CountMin{
+		window:  window,
+		epsilon: epsilon,
+		delta:   delta,
+		width:   width,
+		table:   table,
+		queue:   queue.NewRingBuffer(uint64(window)),
+	}, nil
+}
+
+// NewGlobalCountMin instantiates a global CountMin struct.
+// This is equivalent to calling NewCountMin(0, epsilon, delta).
+func NewGlobalCountMin(epsilon float64, delta float64) (*CountMin, error) {
+	return NewCountMin(0, epsilon, delta)
+}
+
+// String returns a string representation of the metric.
+func (c *CountMin) String() string {
+	name := "frequency.CountMin"
+	params := []string{
+		fmt.Sprintf("window:%d", c.window),
+		fmt.Sprintf("epsilon:%v", c.epsilon),
+		fmt.Sprintf("delta:%v", c.delta),
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Width returns the number of counters in each row of the sketch.
+func (c *CountMin) Width() int {
+	return c.width
+}
+
+// Depth returns the number of rows of the sketch.
+func (c *CountMin) Depth() int {
+	return len(c.table)
+}
+
+// Push adds a value to the sketch.
+func (c *CountMin) Push(x string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.window != 0 {
+		if c.queue.Len() == uint64(c.window) {
+			val, err := c.queue.Get()
+			if err != nil {
+				return errors.Wrap(err, "error popping item from queue")
+			}
+
+			c.add(val.(string), -1)
+		}
+
+		err := c.queue.Put(x)
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %s to queue", x)
+		}
+	}
+
+	c.add(x, 1)
+	return nil
+}
+
+// add adds n to the counters of a value, without locking.
+func (c *CountMin) add(x string, n int) {
+	c.count += n
+	h := hash.Bytes(x)
+	for i, row := range c.table {
+		row[c.index(h, i)] += n
+	}
+}
+
+// index returns the index of the counter of the row i for a hashed value,
+// where the hash of each row is derived from two halves of the hash.
+func (c *CountMin) index(h uint64, i int) int {
+	lo, hi := h&math.MaxUint32, h>>32
+	return int((lo + uint64(i)*hi) % uint64(c.width))
+}
+
+// Estimate returns the estimated count of a value, which is never less than its true count.
+func (c *CountMin) Estimate(x string) int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	h := hash.Bytes(x)
+	estimate := math.MaxInt64
+	for i, row := range c.table {
+		if n := row[c.index(h, i)]; n < estimate {
+			estimate = n
+		}
+	}
+	return estimate
+}
+
+// Count returns the number of values seen by the sketch (or in its window).
+func (c *CountMin) Count() int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.count
+}
+
+// Merge merges the counts of another global sketch into the sketch, so that it
+// estimates the counts of the values of both sketches; the other sketch is unchanged.
+// Both sketches must be global, with the same epsilon and delta.
+func (c *CountMin) Merge(other *CountMin) error {
+	if c.window != 0 || other.window != 0 {
+		return errors.New("cannot merge CountMin with a window")
+	} else if c.width != other.width || len(c.table) != len(other.table) {
+		return errors.Errorf(
+			"cannot merge CountMin with dimensions %dx%d into CountMin with dimensions %dx%d",
+			len(other.table),
+			other.width,
+			len(c.table),
+			c.width,
+		)
+	}
+
+	// copy the other sketch's counters first, which allows for a sketch to be merged with itself
+	other.mux.RLock()
+	table := make([][]int, len(other.table))
+	for i, row := range other.table {
+		table[i] = append([]int{}, row...)
+	}
+	count := other.count
+	other.mux.RUnlock()
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for i, row := range table {
+		for j, n := range row {
+			c.table[i][j] += n
+		}
+	}
+	c.count += count
+
+	return nil
+}
+
+// Clear resets the metric.
+func (c *CountMin) Clear() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.queue.Dispose()
+	c.queue = queue.NewRingBuffer(uint64(c.window))
+	for _, row := range c.table {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	c.count = 0
+}
@@ -0,0 +1,161 @@
+package frequency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewCountMin(t *testing.T) {
+	t.Run("pass: valid CountMin is valid", func(t *testing.T) {
+		c, err := NewCountMin(3, 0.01, 0.05)
+		require.NoError(t, err)
+		assert.Equal(t, 272, c.Width())
+		assert.Equal(t, 3, c.Depth())
+		assert.Equal(t, "frequency.CountMin_{window:3,epsilon:0.01,delta:0.05}", c.String())
+	})
+
+	t.Run("fail: negative window is invalid", func(t *testing.T) {
+		_, err := NewCountMin(-1, 0.01, 0.05)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+
+	t.Run("fail: epsilon out of range is invalid", func(t *testing.T) {
+		_, err := NewCountMin(3, 0, 0.05)
+		testutil.ContainsError(t, err, "epsilon 0.000000 not in (0, 1)")
+	})
+
+	t.Run("fail: delta out of range is invalid", func(t *testing.T) {
+		_, err := NewCountMin(3, 0.01, 1)
+		testutil.ContainsError(t, err, "delta 1.000000 not in (0, 1)")
+	})
+}
+
+func TestNewGlobalCountMin(t *testing.T) {
+	c, err := NewGlobalCountMin(0.01, 0.05)
+	require.NoError(t, err)
+
+	expected, err := NewCountMin(0, 0.01, 0.05)
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), c.String())
+}
+
+func TestCountMinEstimate(t *testing.T) {
+	t.Run("pass: estimates are within error bounds", func(t *testing.T) {
+		epsilon := 0.001
+		c, err := NewGlobalCountMin(epsilon, 0.01)
+		require.NoError(t, err)
+
+		values, counts := zipf(100000, 1)
+		for _, x := range values {
+			err = c.Push(x)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, len(values), c.Count())
+
+		bound := int(epsilon * float64(len(values)))
+		exceeded := 0
+		for key, count := range counts {
+			estimate := c.Estimate(key)
+			assert.True(t, estimate >= count)
+			if estimate > count+bound {
+				exceeded++
+			}
+		}
+		assert.True(t, exceeded <= len(counts)/100)
+	})
+
+	t.Run("pass: unseen values have small estimates", func(t *testing.T) {
+		c, err := NewGlobalCountMin(0.01, 0.01)
+		require.NoError(t, err)
+
+		err = c.Push("a")
+		require.NoError(t, err)
+		assert.Equal(t, 1, c.Estimate("a"))
+		assert.Equal(t, 0, c.Estimate("b"))
+	})
+
+	t.Run("pass: values leave the window", func(t *testing.T) {
+		c, err := NewCountMin(3, 0.01, 0.01)
+		require.NoError(t, err)
+
+		for _, x := range []string{"a", "a", "b", "c", "c"} {
+			err = c.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 0, c.Estimate("a"))
+		assert.Equal(t, 1, c.Estimate("b"))
+		assert.Equal(t, 2, c.Estimate("c"))
+		assert.Equal(t, 3, c.Count())
+	})
+}
+
+func TestCountMinMerge(t *testing.T) {
+	t.Run("pass: merged estimates are sums", func(t *testing.T) {
+		c1, err := NewGlobalCountMin(0.01, 0.01)
+		require.NoError(t, err)
+		c2, err := NewGlobalCountMin(0.01, 0.01)
+		require.NoError(t, err)
+
+		for _, x := range []string{"a", "b", "a"} {
+			err = c1.Push(x)
+			require.NoError(t, err)
+		}
+		for _, x := range []string{"b", "c", "b"} {
+			err = c2.Push(x)
+			require.NoError(t, err)
+		}
+
+		err = c1.Merge(c2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, c1.Estimate("a"))
+		assert.Equal(t, 3, c1.Estimate("b"))
+		assert.Equal(t, 1, c1.Estimate("c"))
+		assert.Equal(t, 6, c1.Count())
+		assert.Equal(t, 0, c2.Estimate("a"))
+
+		err = c1.Merge(c1)
+		require.NoError(t, err)
+		assert.Equal(t, 4, c1.Estimate("a"))
+		assert.Equal(t, 12, c1.Count())
+	})
+
+	t.Run("fail: CountMin with a window cannot be merged", func(t *testing.T) {
+		global, err := NewGlobalCountMin(0.01, 0.01)
+		require.NoError(t, err)
+		windowed, err := NewCountMin(3, 0.01, 0.01)
+		require.NoError(t, err)
+
+		err = global.Merge(windowed)
+		testutil.ContainsError(t, err, "cannot merge CountMin with a window")
+	})
+
+	t.Run("fail: CountMin with different dimensions cannot be merged", func(t *testing.T) {
+		c1, err := NewGlobalCountMin(0.01, 0.01)
+		require.NoError(t, err)
+		c2, err := NewGlobalCountMin(0.1, 0.01)
+		require.NoError(t, err)
+
+		err = c1.Merge(c2)
+		testutil.ContainsError(t, err, "cannot merge CountMin with dimensions 5x28 into CountMin with dimensions 5x272")
+	})
+}
+
+func TestCountMinClear(t *testing.T) {
+	c, err := NewCountMin(3, 0.01, 0.01)
+	require.NoError(t, err)
+
+	for _, x := range []string{"a", "b", "c", "d"} {
+		err = c.Push(x)
+		require.NoError(t, err)
+	}
+
+	c.Clear()
+	assert.Equal(t, 0, c.Count())
+	assert.Equal(t, 0, c.Estimate("d"))
+	assert.Equal(t, uint64(0), c.queue.Len())
+}
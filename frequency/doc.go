@@ -0,0 +1,20 @@
+// Package frequency provides metrics for tracking the frequencies of the
+// values in a stream, such as the most frequent values (heavy hitters).
+//
+// TopK implements the Space-Saving algorithm of Metwally et al. (the
+// counter-based variant of Misra-Gries), which keeps c counters for the
+// most frequent values seen so far. For a stream of n values, the count of
+// each tracked value overestimates its true count by at most n/c, and any
+// value whose true count exceeds n/c is guaranteed to be tracked. Over a
+// window, TopK instead keeps exact counts of the values in the window, since
+// the window needs to be kept anyway in order to evict values.
+//
+// CountMin implements the Count-Min sketch of Cormode and Muthukrishnan, which
+// estimates the count of any value with d rows of w counters each, where
+// w = ceil(e/epsilon) and d = ceil(ln(1/delta)). For a stream (or window) of
+// n values, the estimated count of a value is never less than its true count,
+// and with probability at least 1 - delta, overestimates it by at most epsilon * n.
+//
+// See https://www.cs.ucsb.edu/sites/default/files/documents/2005-23.pdf and
+// http://dimacs.rutgers.edu/~graham/pubs/papers/cm-full.pdf for more details.
+package frequency
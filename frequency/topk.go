@@ -0,0 +1,270 @@
+package frequency
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+)
+
+// Item is a value tracked by a TopK, along with its count; the true count of
+// the value lies in [Count - Error, Count].
+type Item[K comparable] struct {
+	Key   K
+	Count int
+	Error int
+}
+
+// counter is the counter of a value tracked by a TopK, along with its index in the heap.
+type counter[K comparable] struct {
+	Item[K]
+	index int
+}
+
+// counterHeap is a min-heap of counters, ordered by their counts.
+type counterHeap[K comparable] []*counter[K]
+
+func (h counterHeap[K]) Len() int           { return len(h) }
+func (h counterHeap[K]) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h counterHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *counterHeap[K]) Push(x interface{}) {
+	c := x.(*counter[K])
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+func (h *counterHeap[K]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[:n-1]
+	return c
+}
+
+// TopK keeps track of the most frequent values of a stream; see the package
+// documentation for its error bounds.
+type TopK[K comparable] struct {
+	window   int
+	capacity int
+	counters map[K]*counter[K]
+	heap     counterHeap[K]
+	queue    *queue.RingBuffer
+	count    int
+	mux      sync.RWMutex
+}
+
+// NewTopK instantiates a TopK struct that keeps the provided number of counters,
+// which is the largest k that can be queried. Over a window, the counts are exact.
+func NewTopK[K comparable](window int, capacity int) (*TopK[K], error) {
+	if window < 0 {
+		return nil, errors.Errorf("%d is a negative window", window)
+	} else if capacity <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive capacity", capacity)
+	}
+
+	return &TopK[K]{
+		window:   window,
+		capacity: capacity,
+		counters: map[K]*counter[K]{},
+		queue:    queue.NewRingBuffer(uint64(window)),
+	}, nil
+}
+
+// NewGlobalTopK instantiates a global TopK struct.
+// This is equivalent to calling NewTopK[K](0, capacity).
+func NewGlobalTopK[K comparable](capacity int) (*TopK[K], error) {
+	return NewTopK[K](0, capacity)
+}
+
+// String returns a string representation of the metric.
+func (t *TopK[K]) String() string {
+	name := "frequency.TopK"
+	params := []string{
+		fmt.Sprintf("window:%d", t.window),
+		fmt.Sprintf("capacity:%d", t.capacity),
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Push adds a value to the TopK.
+func (t *TopK[K]) Push(x K) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.window != 0 {
+		if t.queue.Len() == uint64(t.window) {
+			val, err := t.queue.Get()
+			if err != nil {
+				return errors.Wrap(err, "error popping item from queue")
+			}
+
+			t.decrement(val.(K))
+		}
+
+		err := t.queue.Put(x)
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %v to queue", x)
+		}
+	}
+
+	t.count++
+	if c, ok := t.counters[x]; ok {
+		c.Count++
+		heap.Fix(&t.heap, c.index)
+	} else if t.window != 0 || len(t.heap) < t.capacity {
+		c := &counter[K]{Item: Item[K]{Key: x, Count: 1}}
+		t.counters[x] = c
+		heap.Push(&t.heap, c)
+	} else {
+		// replace the value with the smallest count, whose count is
+		// an upper bound on the number of times x has been seen
+		c := t.heap[0]
+		delete(t.counters, c.Key)
+		c.Key = x
+		c.Error = c.Count
+		c.Count++
+		t.counters[x] = c
+		heap.Fix(&t.heap, 0)
+	}
+
+	return nil
+}
+
+// decrement removes a value that has left the window from the counts.
+func (t *TopK[K]) decrement(x K) {
+	t.count--
+	c := t.counters[x]
+	c.Count--
+	if c.Count == 0 {
+		heap.Remove(&t.heap, c.index)
+		delete(t.counters, x)
+	} else {
+		heap.Fix(&t.heap, c.index)
+	}
+}
+
+// Top returns the k most frequent values, in descending order of their counts;
+// values with the same counts are in no particular order. Fewer than k values
+// are returned if fewer than k distinct values are tracked.
+func (t *TopK[K]) Top(k int) ([]Item[K], error) {
+	if k <= 0 || k > t.capacity {
+		return nil, errors.Errorf("k of %d not in [1, %d]", k, t.capacity)
+	}
+
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	items := make([]Item[K], len(t.heap))
+	for i, c := range t.heap {
+		items[i] = c.Item
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Error < items[j].Error
+	})
+
+	if len(items) > k {
+		items = items[:k]
+	}
+	return items, nil
+}
+
+// Count returns the number of values seen by the TopK (or in its window).
+func (t *TopK[K]) Count() int {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+	return t.count
+}
+
+// Merge merges the counts of another global TopK into the TopK, so that it tracks
+// the most frequent values of both TopKs; the other TopK is unchanged. Both TopKs
+// must be global, and the merged TopK keeps its own capacity. The error bound of
+// the merged TopK is the same as if it had seen both streams.
+func (t *TopK[K]) Merge(other *TopK[K]) error {
+	if t.window != 0 || other.window != 0 {
+		return errors.New("cannot merge TopK with a window")
+	}
+
+	// copy the other TopK's counters first, which allows for a TopK to be merged with itself
+	other.mux.RLock()
+	otherItems := make(map[K]Item[K], len(other.counters))
+	for key, c := range other.counters {
+		otherItems[key] = c.Item
+	}
+	otherMin := other.min()
+	otherCount := other.count
+	other.mux.RUnlock()
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	// a value that is not tracked by a full TopK may have been seen as many
+	// times as the smallest count of that TopK
+	ownMin := t.min()
+	merged := make([]Item[K], 0, len(t.counters)+len(otherItems))
+	for key, c := range t.counters {
+		item := c.Item
+		if otherItem, ok := otherItems[key]; ok {
+			item.Count += otherItem.Count
+			item.Error += otherItem.Error
+		} else {
+			item.Count += otherMin
+			item.Error += otherMin
+		}
+		merged = append(merged, item)
+	}
+	for key, item := range otherItems {
+		if _, ok := t.counters[key]; !ok {
+			item.Count += ownMin
+			item.Error += ownMin
+			merged = append(merged, item)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Count > merged[j].Count
+	})
+	if len(merged) > t.capacity {
+		merged = merged[:t.capacity]
+	}
+
+	t.counters = make(map[K]*counter[K], len(merged))
+	t.heap = make(counterHeap[K], 0, len(merged))
+	for _, item := range merged {
+		c := &counter[K]{Item: item}
+		t.counters[item.Key] = c
+		heap.Push(&t.heap, c)
+	}
+	t.count += otherCount
+
+	return nil
+}
+
+// min returns the smallest count if the TopK is full, and 0 otherwise.
+func (t *TopK[K]) min() int {
+	if len(t.heap) < t.capacity {
+		return 0
+	}
+	return t.heap[0].Count
+}
+
+// Clear resets the metric.
+func (t *TopK[K]) Clear() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.queue.Dispose()
+	t.queue = queue.NewRingBuffer(uint64(t.window))
+	t.counters = map[K]*counter[K]{}
+	t.heap = nil
+	t.count = 0
+}
@@ -0,0 +1,238 @@
+package frequency
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+// zipf returns a stream of n values following a Zipf distribution,
+// along with the true counts of each value.
+func zipf(n int, seed int64) ([]string, map[string]int) {
+	z := rand.NewZipf(rand.New(rand.NewSource(seed)), 1.2, 1, 1000)
+	values := make([]string, n)
+	counts := map[string]int{}
+	for i := range values {
+		values[i] = strconv.FormatUint(z.Uint64(), 10)
+		counts[values[i]]++
+	}
+	return values, counts
+}
+
+func TestNewTopK(t *testing.T) {
+	t.Run("pass: valid TopK is valid", func(t *testing.T) {
+		topk, err := NewTopK[string](3, 5)
+		require.NoError(t, err)
+		assert.Equal(t, "frequency.TopK_{window:3,capacity:5}", topk.String())
+	})
+
+	t.Run("fail: negative window is invalid", func(t *testing.T) {
+		_, err := NewTopK[string](-1, 5)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+
+	t.Run("fail: nonpositive capacity is invalid", func(t *testing.T) {
+		_, err := NewTopK[string](3, 0)
+		testutil.ContainsError(t, err, "0 is a nonpositive capacity")
+	})
+}
+
+func TestNewGlobalTopK(t *testing.T) {
+	topk, err := NewGlobalTopK[int](5)
+	require.NoError(t, err)
+
+	expected, err := NewTopK[int](0, 5)
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), topk.String())
+}
+
+func TestTopKTop(t *testing.T) {
+	t.Run("pass: counts are exact when there are enough counters", func(t *testing.T) {
+		topk, err := NewGlobalTopK[string](5)
+		require.NoError(t, err)
+
+		for _, x := range []string{"a", "b", "a", "c", "a", "b"} {
+			err = topk.Push(x)
+			require.NoError(t, err)
+		}
+
+		items, err := topk.Top(2)
+		require.NoError(t, err)
+		assert.Equal(t, []Item[string]{
+			{Key: "a", Count: 3},
+			{Key: "b", Count: 2},
+		}, items)
+
+		items, err = topk.Top(5)
+		require.NoError(t, err)
+		assert.Len(t, items, 3)
+		assert.Equal(t, 6, topk.Count())
+	})
+
+	t.Run("pass: counts are within error bounds", func(t *testing.T) {
+		capacity := 50
+		topk, err := NewGlobalTopK[string](capacity)
+		require.NoError(t, err)
+
+		values, counts := zipf(10000, 1)
+		for _, x := range values {
+			err = topk.Push(x)
+			require.NoError(t, err)
+		}
+
+		items, err := topk.Top(capacity)
+		require.NoError(t, err)
+		bound := len(values) / capacity
+		tracked := map[string]bool{}
+		for _, item := range items {
+			tracked[item.Key] = true
+			assert.True(t, item.Count >= counts[item.Key])
+			assert.True(t, item.Count-item.Error <= counts[item.Key])
+			assert.True(t, item.Error <= bound)
+		}
+
+		// every value more frequent than the bound is tracked
+		for key, count := range counts {
+			if count > bound {
+				assert.True(t, tracked[key], key)
+			}
+		}
+
+		// the most frequent value of a Zipf distribution is found exactly
+		assert.Equal(t, "0", items[0].Key)
+		assert.Equal(t, counts["0"], items[0].Count)
+	})
+
+	t.Run("pass: counts are exact over a window", func(t *testing.T) {
+		topk, err := NewTopK[string](3, 2)
+		require.NoError(t, err)
+
+		for _, x := range []string{"a", "a", "b", "c", "c"} {
+			err = topk.Push(x)
+			require.NoError(t, err)
+		}
+
+		items, err := topk.Top(2)
+		require.NoError(t, err)
+		assert.Equal(t, []Item[string]{
+			{Key: "c", Count: 2},
+			{Key: "b", Count: 1},
+		}, items)
+		assert.Equal(t, 3, topk.Count())
+		assert.Len(t, topk.counters, 2)
+	})
+
+	t.Run("fail: k out of range is invalid", func(t *testing.T) {
+		topk, err := NewGlobalTopK[string](5)
+		require.NoError(t, err)
+
+		_, err = topk.Top(0)
+		testutil.ContainsError(t, err, "k of 0 not in [1, 5]")
+
+		_, err = topk.Top(6)
+		testutil.ContainsError(t, err, "k of 6 not in [1, 5]")
+	})
+}
+
+func TestTopKMerge(t *testing.T) {
+	t.Run("pass: merged counts are exact when there are enough counters", func(t *testing.T) {
+		topk1, err := NewGlobalTopK[string](5)
+		require.NoError(t, err)
+		topk2, err := NewGlobalTopK[string](5)
+		require.NoError(t, err)
+
+		for _, x := range []string{"a", "b", "a"} {
+			err = topk1.Push(x)
+			require.NoError(t, err)
+		}
+		for _, x := range []string{"b", "c", "b"} {
+			err = topk2.Push(x)
+			require.NoError(t, err)
+		}
+
+		err = topk1.Merge(topk2)
+		require.NoError(t, err)
+
+		items, err := topk1.Top(5)
+		require.NoError(t, err)
+		assert.Equal(t, []Item[string]{
+			{Key: "b", Count: 3},
+			{Key: "a", Count: 2},
+			{Key: "c", Count: 1},
+		}, items)
+		assert.Equal(t, 6, topk1.Count())
+		assert.Equal(t, 3, topk2.Count())
+	})
+
+	t.Run("pass: merged counts are within error bounds", func(t *testing.T) {
+		capacity := 50
+		topk1, err := NewGlobalTopK[string](capacity)
+		require.NoError(t, err)
+		topk2, err := NewGlobalTopK[string](capacity)
+		require.NoError(t, err)
+
+		values1, counts := zipf(10000, 1)
+		values2, counts2 := zipf(10000, 2)
+		for key, count := range counts2 {
+			counts[key] += count
+		}
+		for _, x := range values1 {
+			err = topk1.Push(x)
+			require.NoError(t, err)
+		}
+		for _, x := range values2 {
+			err = topk2.Push(x)
+			require.NoError(t, err)
+		}
+
+		err = topk1.Merge(topk2)
+		require.NoError(t, err)
+
+		items, err := topk1.Top(capacity)
+		require.NoError(t, err)
+		assert.Len(t, items, capacity)
+		bound := (len(values1) + len(values2)) / capacity
+		for _, item := range items {
+			assert.True(t, item.Count >= counts[item.Key])
+			assert.True(t, item.Count-item.Error <= counts[item.Key])
+			assert.True(t, item.Error <= bound)
+		}
+		assert.Equal(t, "0", items[0].Key)
+		assert.Equal(t, 20000, topk1.Count())
+	})
+
+	t.Run("fail: TopK with a window cannot be merged", func(t *testing.T) {
+		global, err := NewGlobalTopK[string](5)
+		require.NoError(t, err)
+		windowed, err := NewTopK[string](3, 5)
+		require.NoError(t, err)
+
+		err = global.Merge(windowed)
+		testutil.ContainsError(t, err, "cannot merge TopK with a window")
+
+		err = windowed.Merge(global)
+		testutil.ContainsError(t, err, "cannot merge TopK with a window")
+	})
+}
+
+func TestTopKClear(t *testing.T) {
+	topk, err := NewTopK[string](3, 5)
+	require.NoError(t, err)
+
+	for _, x := range []string{"a", "b", "c", "d"} {
+		err = topk.Push(x)
+		require.NoError(t, err)
+	}
+
+	topk.Clear()
+	assert.Equal(t, 0, topk.Count())
+	assert.Equal(t, uint64(0), topk.queue.Len())
+	items, err := topk.Top(5)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
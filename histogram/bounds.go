@@ -0,0 +1,58 @@
+package histogram
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// LinearBuckets returns count bucket upper bounds, where the lowest bound is
+// start and each subsequent bound is width greater than the previous one.
+func LinearBuckets(start float64, width float64, count int) ([]float64, error) {
+	if count < 1 {
+		return nil, errors.Errorf("attempted to create %d buckets, which is less than 1", count)
+	} else if width <= 0 {
+		return nil, errors.Errorf("attempted to create buckets with nonpositive width %f", width)
+	}
+
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + float64(i)*width
+	}
+	return bounds, nil
+}
+
+// ExponentialBuckets returns count bucket upper bounds, where the lowest bound
+// is start and each subsequent bound is factor times the previous one.
+func ExponentialBuckets(start float64, factor float64, count int) ([]float64, error) {
+	if count < 1 {
+		return nil, errors.Errorf("attempted to create %d buckets, which is less than 1", count)
+	} else if start <= 0 {
+		return nil, errors.Errorf("attempted to create buckets with nonpositive start %f", start)
+	} else if factor <= 1 {
+		return nil, errors.Errorf("attempted to create buckets with factor %f, which is not greater than 1", factor)
+	}
+
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start * math.Pow(factor, float64(i))
+	}
+	return bounds, nil
+}
+
+// validateBounds checks that the bucket upper bounds are finite and strictly increasing.
+func validateBounds(bounds []float64) error {
+	if len(bounds) == 0 {
+		return errors.New("no bucket bounds provided")
+	}
+
+	for i, bound := range bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return errors.Errorf("bucket bound %f is not finite", bound)
+		} else if i > 0 && bound <= bounds[i-1] {
+			return errors.Errorf("bucket bounds are not strictly increasing: %f follows %f", bound, bounds[i-1])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package histogram
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestLinearBuckets(t *testing.T) {
+	t.Run("pass: returns evenly spaced bounds", func(t *testing.T) {
+		bounds, err := LinearBuckets(-1, 0.5, 5)
+		require.NoError(t, err)
+		testutil.ApproxSlice(t, []float64{-1, -0.5, 0, 0.5, 1}, bounds)
+	})
+
+	t.Run("fail: count < 1 is invalid", func(t *testing.T) {
+		_, err := LinearBuckets(0, 1, 0)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to create %d buckets, which is less than 1", 0))
+	})
+
+	t.Run("fail: nonpositive width is invalid", func(t *testing.T) {
+		_, err := LinearBuckets(0, 0, 3)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to create buckets with nonpositive width %f", 0.))
+	})
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	t.Run("pass: returns geometrically spaced bounds", func(t *testing.T) {
+		bounds, err := ExponentialBuckets(0.5, 2, 4)
+		require.NoError(t, err)
+		testutil.ApproxSlice(t, []float64{0.5, 1, 2, 4}, bounds)
+	})
+
+	t.Run("fail: count < 1 is invalid", func(t *testing.T) {
+		_, err := ExponentialBuckets(1, 2, -1)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to create %d buckets, which is less than 1", -1))
+	})
+
+	t.Run("fail: nonpositive start is invalid", func(t *testing.T) {
+		_, err := ExponentialBuckets(0, 2, 3)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to create buckets with nonpositive start %f", 0.))
+	})
+
+	t.Run("fail: factor <= 1 is invalid", func(t *testing.T) {
+		_, err := ExponentialBuckets(1, 1, 3)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to create buckets with factor %f, which is not greater than 1", 1.))
+	})
+}
+
+func TestValidateBounds(t *testing.T) {
+	t.Run("pass: strictly increasing bounds are valid", func(t *testing.T) {
+		err := validateBounds([]float64{-1, 0, 2.5})
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail: no bounds are invalid", func(t *testing.T) {
+		err := validateBounds(nil)
+		testutil.ContainsError(t, err, "no bucket bounds provided")
+	})
+
+	t.Run("fail: infinite bounds are invalid", func(t *testing.T) {
+		err := validateBounds([]float64{0, math.Inf(1)})
+		testutil.ContainsError(t, err, fmt.Sprintf("bucket bound %f is not finite", math.Inf(1)))
+	})
+
+	t.Run("fail: non-increasing bounds are invalid", func(t *testing.T) {
+		err := validateBounds([]float64{0, 1, 1})
+		testutil.ContainsError(t, err, fmt.Sprintf("bucket bounds are not strictly increasing: %f follows %f", 1., 1.))
+	})
+}
@@ -0,0 +1,3 @@
+// Package histogram provides a library of data structures/algorithms
+// for calculating online histograms from a stream of data.
+package histogram
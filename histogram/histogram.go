@@ -0,0 +1,205 @@
+package histogram
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream/counter"
+)
+
+// Histogram keeps track of the counts of a stream in a fixed set of buckets.
+// Each bucket is defined by an upper bound, and counts the values that are
+// at most its upper bound but greater than the upper bound of the previous
+// bucket; values greater than every upper bound are counted in an implicit
+// final bucket with an upper bound of +Inf.
+type Histogram struct {
+	window int
+	bounds []float64
+	counts []int
+	sum    counter.KahanSum
+	queue  *queue.RingBuffer
+	mux    sync.RWMutex
+}
+
+// New instantiates a Histogram struct with the provided bucket upper bounds, which
+// must be finite and strictly increasing; see LinearBuckets and ExponentialBuckets
+// for common layouts.
+func New(window int, bounds []float64) (*Histogram, error) {
+	if window < 0 {
+		return nil, errors.Errorf("attempted to set negative window of %d", window)
+	}
+
+	err := validateBounds(bounds)
+	if err != nil {
+		return nil, errors.Wrap(err, "error validating bucket bounds")
+	}
+
+	return &Histogram{
+		window: window,
+		bounds: append([]float64{}, bounds...),
+		counts: make([]int, len(bounds)+1),
+		queue:  queue.NewRingBuffer(uint64(window)),
+	}, nil
+}
+
+// NewGlobalHistogram instantiates a global Histogram struct.
+// This is equivalent to calling New(0, bounds).
+func NewGlobalHistogram(bounds []float64) (*Histogram, error) {
+	return New(0, bounds)
+}
+
+// String returns a string representation of the metric.
+func (h *Histogram) String() string {
+	name := "histogram.Histogram"
+	params := []string{
+		fmt.Sprintf("window:%v", h.window),
+		fmt.Sprintf("bounds:%v", h.bounds),
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Push adds a number to the histogram; infinite values are rejected, since
+// their sum could not be undone once they are evicted from the window.
+func (h *Histogram) Push(x float64) error {
+	if math.IsNaN(x) {
+		return errors.New("attempted to push NaN")
+	} else if math.IsInf(x, 0) {
+		return errors.Errorf("attempted to push infinite value %f", x)
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.window != 0 {
+		if h.queue.Len() == uint64(h.window) {
+			val, err := h.queue.Get()
+			if err != nil {
+				return errors.Wrap(err, "error popping item from queue")
+			}
+
+			y := val.(float64)
+			h.counts[h.bucket(y)]--
+			h.sum.Add(-y)
+		}
+
+		err := h.queue.Put(x)
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %f to queue", x)
+		}
+	}
+
+	h.counts[h.bucket(x)]++
+	h.sum.Add(x)
+	return nil
+}
+
+// bucket returns the index of the bucket that x belongs to.
+func (h *Histogram) bucket(x float64) int {
+	return sort.SearchFloat64s(h.bounds, x)
+}
+
+// Bounds returns the finite bucket upper bounds of the histogram.
+func (h *Histogram) Bounds() []float64 {
+	return append([]float64{}, h.bounds...)
+}
+
+// Counts returns the number of values in each bucket, in the same order as
+// Bounds; the last count is of the implicit +Inf bucket.
+func (h *Histogram) Counts() []int {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return append([]int{}, h.counts...)
+}
+
+// CumulativeCounts returns the number of values that are at most the upper bound
+// of each bucket, in the same order as Bounds; the last count is of the implicit
+// +Inf bucket, which is the total number of values.
+func (h *Histogram) CumulativeCounts() []int {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return h.cumulativeCounts()
+}
+
+func (h *Histogram) cumulativeCounts() []int {
+	cumulative := make([]int, len(h.counts))
+	total := 0
+	for i, count := range h.counts {
+		total += count
+		cumulative[i] = total
+	}
+	return cumulative
+}
+
+// Count returns the total number of values in the histogram.
+func (h *Histogram) Count() int {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	total := 0
+	for _, count := range h.counts {
+		total += count
+	}
+	return total
+}
+
+// Sum returns the sum of the values in the histogram.
+func (h *Histogram) Sum() float64 {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return h.sum.Value()
+}
+
+// PrometheusBuckets converts the histogram into Prometheus-style buckets; it returns
+// a map of each finite upper bound ("le") to the cumulative count of values at most
+// that bound, along with the total count (i.e. the count of the "+Inf" bucket) and
+// the sum of the values. These are the arguments expected by prometheus.NewConstHistogram.
+func (h *Histogram) PrometheusBuckets() (buckets map[float64]uint64, count uint64, sum float64) {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	cumulative := h.cumulativeCounts()
+	buckets = make(map[float64]uint64, len(h.bounds))
+	for i, bound := range h.bounds {
+		buckets[bound] = uint64(cumulative[i])
+	}
+
+	return buckets, uint64(cumulative[len(cumulative)-1]), h.sum.Value()
+}
+
+// Values returns the cumulative counts of the buckets; in particular, it returns a
+// map of strings to counts, where the strings are the upper bounds of the buckets
+// formatted in the same way as Prometheus "le" labels (e.g. "0.5" or "+Inf").
+// This satisfies the stream.AggregateMetric interface.
+func (h *Histogram) Values() (map[string]interface{}, error) {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	cumulative := h.cumulativeCounts()
+	values := make(map[string]interface{}, len(cumulative))
+	for i, bound := range h.bounds {
+		values[strconv.FormatFloat(bound, 'g', -1, 64)] = cumulative[i]
+	}
+	values["+Inf"] = cumulative[len(cumulative)-1]
+
+	return values, nil
+}
+
+// Clear resets the metric.
+func (h *Histogram) Clear() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.queue.Dispose()
+	h.queue = queue.NewRingBuffer(uint64(h.window))
+	h.counts = make([]int, len(h.bounds)+1)
+	h.sum.Reset()
+}
@@ -0,0 +1,191 @@
+package histogram
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("pass: valid bounds are set", func(t *testing.T) {
+		bounds := []float64{1, 2, 3}
+		histogram, err := New(3, bounds)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, histogram.window)
+		assert.Equal(t, []float64{1, 2, 3}, histogram.Bounds())
+		assert.Equal(t, []int{0, 0, 0, 0}, histogram.Counts())
+
+		// the bounds are copied
+		bounds[0] = 0
+		assert.Equal(t, []float64{1, 2, 3}, histogram.Bounds())
+	})
+
+	t.Run("fail: negative window is invalid", func(t *testing.T) {
+		_, err := New(-1, []float64{1})
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set negative window of %d", -1))
+	})
+
+	t.Run("fail: invalid bounds are invalid", func(t *testing.T) {
+		_, err := New(3, []float64{2, 1})
+		testutil.ContainsError(t, err, "error validating bucket bounds")
+	})
+}
+
+func TestNewGlobalHistogram(t *testing.T) {
+	histogram, err := New(0, []float64{1})
+	require.NoError(t, err)
+
+	globalHistogram, err := NewGlobalHistogram([]float64{1})
+	require.NoError(t, err)
+
+	assert.Equal(t, histogram, globalHistogram)
+}
+
+func TestString(t *testing.T) {
+	histogram, err := New(3, []float64{0.5, 1, 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, "histogram.Histogram_{window:3,bounds:[0.5 1 2]}", histogram.String())
+}
+
+func TestPush(t *testing.T) {
+	t.Run("pass: counts global values", func(t *testing.T) {
+		histogram, err := NewGlobalHistogram([]float64{1, 2, 4})
+		require.NoError(t, err)
+
+		for _, x := range []float64{-1, 0.5, 1, 1.5, 3, 4, 4.5, 10} {
+			err := histogram.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []int{3, 1, 2, 2}, histogram.Counts())
+		assert.Equal(t, []int{3, 4, 6, 8}, histogram.CumulativeCounts())
+		assert.Equal(t, 8, histogram.Count())
+		testutil.Approx(t, 23.5, histogram.Sum())
+	})
+
+	t.Run("pass: evicts values outside the window", func(t *testing.T) {
+		histogram, err := New(3, []float64{1, 2, 4})
+		require.NoError(t, err)
+
+		for _, x := range []float64{0, 1.5, 3, 5, 5} {
+			err := histogram.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []int{0, 0, 1, 2}, histogram.Counts())
+		assert.Equal(t, 3, histogram.Count())
+		testutil.Approx(t, 13, histogram.Sum())
+	})
+
+	t.Run("pass: evicting a large value keeps the sum exact", func(t *testing.T) {
+		histogram, err := New(2, []float64{1})
+		require.NoError(t, err)
+
+		for _, x := range []float64{1e20, 1, 1} {
+			err := histogram.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 2., histogram.Sum())
+	})
+
+	t.Run("fail: NaN is invalid", func(t *testing.T) {
+		histogram, err := NewGlobalHistogram([]float64{1})
+		require.NoError(t, err)
+
+		err = histogram.Push(math.NaN())
+		testutil.ContainsError(t, err, "attempted to push NaN")
+	})
+
+	t.Run("fail: infinite values are invalid", func(t *testing.T) {
+		histogram, err := New(2, []float64{1})
+		require.NoError(t, err)
+
+		err = histogram.Push(math.Inf(1))
+		testutil.ContainsError(t, err, "attempted to push infinite value")
+
+		err = histogram.Push(math.Inf(-1))
+		testutil.ContainsError(t, err, "attempted to push infinite value")
+	})
+
+	t.Run("fail: if queue retrieval fails, return error", func(t *testing.T) {
+		histogram, err := New(3, []float64{1})
+		require.NoError(t, err)
+
+		for i := 0.; i < 3; i++ {
+			err = histogram.Push(i)
+			require.NoError(t, err)
+		}
+
+		// dispose the queue to simulate an error when we try to retrieve from the queue
+		histogram.queue.Dispose()
+		err = histogram.Push(3.)
+		testutil.ContainsError(t, err, "error popping item from queue")
+	})
+}
+
+func TestPrometheusBuckets(t *testing.T) {
+	histogram, err := NewGlobalHistogram([]float64{0.25, 0.5, 1})
+	require.NoError(t, err)
+
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.7, 2} {
+		err := histogram.Push(x)
+		require.NoError(t, err)
+	}
+
+	buckets, count, sum := histogram.PrometheusBuckets()
+	assert.Equal(t, map[float64]uint64{0.25: 1, 0.5: 3, 1: 4}, buckets)
+	assert.Equal(t, uint64(5), count)
+	testutil.Approx(t, 3.6, sum)
+}
+
+func TestValues(t *testing.T) {
+	histogram, err := NewGlobalHistogram([]float64{0.25, 0.5, 1})
+	require.NoError(t, err)
+	assert.Implements(t, (*stream.Metric)(nil), histogram)
+	assert.Implements(t, (*stream.AggregateMetric)(nil), histogram)
+
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.7, 2} {
+		err := histogram.Push(x)
+		require.NoError(t, err)
+	}
+
+	values, err := histogram.Values()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"0.25": 1,
+		"0.5":  3,
+		"1":    4,
+		"+Inf": 5,
+	}, values)
+}
+
+func TestClear(t *testing.T) {
+	histogram, err := New(3, []float64{1})
+	require.NoError(t, err)
+
+	for i := 0.; i < 10; i++ {
+		err = histogram.Push(i)
+		require.NoError(t, err)
+	}
+
+	histogram.Clear()
+	assert.Equal(t, uint64(0), histogram.queue.Len())
+	assert.Equal(t, []int{0, 0}, histogram.Counts())
+	assert.Equal(t, 0., histogram.Sum())
+
+	// the window should still work after clearing
+	for i := 0.; i < 5; i++ {
+		err = histogram.Push(i)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{0, 3}, histogram.Counts())
+}
@@ -0,0 +1,162 @@
+package joint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewAggregate(t *testing.T) {
+	t.Run("pass: metrics share a single Core", func(t *testing.T) {
+		cov := NewCov(3)
+		corr := NewCorr(3)
+
+		aggregate, err := NewAggregate(cov, corr)
+		require.NoError(t, err)
+
+		assert.True(t, cov.core == aggregate.Core())
+		assert.True(t, corr.core == aggregate.Core())
+		assert.Equal(t, "joint.Aggregate_{metrics:[joint.Cov_{window:3},joint.Corr_{window:3}]}", aggregate.String())
+	})
+
+	t.Run("fail: no metrics is invalid", func(t *testing.T) {
+		_, err := NewAggregate()
+		testutil.ContainsError(t, err, "no metrics provided")
+	})
+
+	t.Run("fail: incompatible configs are invalid", func(t *testing.T) {
+		_, err := NewAggregate(NewCov(3), NewCorr(4))
+		testutil.ContainsError(t, err, "configs have differing windows")
+	})
+
+	t.Run("fail: undecayed and decayed metrics are invalid", func(t *testing.T) {
+		_, err := NewAggregate(NewGlobalCov(), NewEWMCorr(0.5))
+		testutil.ContainsError(t, err, "metrics have differing decays")
+
+		_, err = NewAggregate(NewGlobalCorr(), NewHalfLifeEWMCov(time.Minute))
+		testutil.ContainsError(t, err, "metrics have differing half-lives")
+	})
+}
+
+func TestAggregateValues(t *testing.T) {
+	t.Run("pass: values match separately initialized metrics", func(t *testing.T) {
+		metrics := []Metric{NewCov(3), NewCorr(3)}
+		expectedMetrics := []Metric{NewCov(3), NewCorr(3)}
+		for _, metric := range expectedMetrics {
+			err := Init(metric)
+			require.NoError(t, err)
+		}
+
+		aggregate, err := NewAggregate(metrics...)
+		require.NoError(t, err)
+
+		xs := []float64{1, 2, 3, 4, 8}
+		ys := []float64{3, 1, 4, 1, 5}
+		for i := range xs {
+			err := aggregate.Push(xs[i], ys[i])
+			require.NoError(t, err)
+			for _, metric := range expectedMetrics {
+				err := metric.Push(xs[i], ys[i])
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Equal(t, 3, aggregate.Core().Count())
+
+		values, err := aggregate.Values()
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+		for _, metric := range expectedMetrics {
+			expected, err := metric.Value()
+			require.NoError(t, err)
+			testutil.Approx(t, expected, values[metric.String()])
+		}
+	})
+
+	t.Run("pass: timed values are pushed to the Core", func(t *testing.T) {
+		cov := NewGlobalCov()
+		aggregate, err := NewAggregate(cov)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err = aggregate.PushAt(time.Now(), x, 2*x)
+			require.NoError(t, err)
+		}
+
+		values, err := aggregate.Values()
+		require.NoError(t, err)
+		testutil.Approx(t, 2, values[cov.String()])
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		aggregate, err := NewAggregate(NewCov(3), NewCorr(3))
+		require.NoError(t, err)
+
+		_, err = aggregate.Values()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+	})
+}
+
+func TestAggregateSnapshot(t *testing.T) {
+	t.Run("pass: values and count reflect the same tuples", func(t *testing.T) {
+		cov := NewGlobalCov()
+		aggregate, err := NewAggregate(cov, NewGlobalCorr())
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := aggregate.Push(x, 2*x)
+			require.NoError(t, err)
+		}
+		err = aggregate.PushAt(time.Now(), 4, 8)
+		require.NoError(t, err)
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 4, snapshot.Count)
+		assert.Len(t, snapshot.Values, 2)
+		testutil.Approx(t, 10./3, snapshot.Values[cov.String()])
+	})
+
+	t.Run("pass: count is the number of tuples in the window", func(t *testing.T) {
+		cov := NewCov(2)
+		aggregate, err := NewAggregate(cov, NewCorr(2))
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := aggregate.Push(x, 2*x)
+			require.NoError(t, err)
+		}
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 2, snapshot.Count)
+		testutil.Approx(t, 1., snapshot.Values[cov.String()])
+	})
+
+	t.Run("fail: metrics from other packages cannot be read", func(t *testing.T) {
+		// embedding the interface hides the unexported methods of the Cov
+		aggregate, err := NewAggregate(struct{ Metric }{NewGlobalCov()})
+		require.NoError(t, err)
+
+		err = aggregate.Push(1, 2)
+		require.NoError(t, err)
+
+		_, err = aggregate.Snapshot()
+		testutil.ContainsError(t, err, "cannot be read in a snapshot")
+	})
+}
+
+func TestAggregateClear(t *testing.T) {
+	aggregate, err := NewAggregate(NewCov(3), NewCorr(3))
+	require.NoError(t, err)
+
+	err = aggregate.Push(1, 2)
+	require.NoError(t, err)
+
+	aggregate.Clear()
+	assert.Equal(t, 0, aggregate.Core().Count())
+}
@@ -108,14 +108,27 @@ func (a *Autocorr) Push(x float64) error {
 func (a *Autocorr) Value() (float64, error) {
 	if !a.IsSetCore() {
 		return 0, errors.New("Core is not set")
-	} else if a.corr.core.Count() == 0 {
+	}
+
+	err := a.corr.core.rlock()
+	defer a.corr.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return a.value()
+}
+
+// value returns the value of the sample autocorrelation, but does not lock the Core.
+func (a *Autocorr) value() (float64, error) {
+	if a.corr.core.UnsafeCount() == 0 {
 		return 0, errors.Errorf(
 			"Not enough values seen; at least %d observations must be made",
 			a.lag+1,
 		)
 	}
 
-	return a.corr.Value()
+	return a.corr.value()
 }
 
 // Clear resets the metric.
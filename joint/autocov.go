@@ -108,14 +108,27 @@ func (a *Autocov) Push(x float64) error {
 func (a *Autocov) Value() (float64, error) {
 	if !a.IsSetCore() {
 		return 0, errors.New("Core is not set")
-	} else if a.cov.core.Count() == 0 {
+	}
+
+	err := a.cov.core.rlock()
+	defer a.cov.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return a.value()
+}
+
+// value returns the value of the sample autocovariance, but does not lock the Core.
+func (a *Autocov) value() (float64, error) {
+	if a.cov.core.UnsafeCount() == 0 {
 		return 0, errors.Errorf(
 			"Not enough values seen; at least %d observations must be made",
 			a.lag+1,
 		)
 	}
 
-	return a.cov.Value()
+	return a.cov.value()
 }
 
 // Clear resets the metric.
@@ -1,7 +1,11 @@
 package joint
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
 )
 
 // SumsConfig is an alias for a slice of Tuples; this configures
@@ -17,17 +21,23 @@ func (s1 *SumsConfig) add(s2 SumsConfig) {
 // CoreConfig is the struct containing configuration options for
 // instantiating a Core object.
 type CoreConfig struct {
-	Sums   SumsConfig // sums tracked must be positive, and must track > 1 variables
-	Window *int       // must be 0 if decay is set, must be nonnegative in general
-	Vars   *int       // must be inferrable from Sums if not set; otherwise must be > 1
-	Decay  *float64   // optional, must lie in the interval (0, 1)
+	Sums     SumsConfig     // sums tracked must be positive, and must track > 1 variables
+	Window   *int           // must be 0 if decay, a half-life or a duration is set, must be nonnegative in general
+	Vars     *int           // must be inferrable from Sums if not set; otherwise must be > 1
+	Decay    *float64       // optional, must lie in the interval (0, 1)
+	HalfLife *time.Duration // optional, decays values by elapsed time instead of by push; must be positive
+	Duration *time.Duration // optional, tracks values over a time-based window; must be nonnegative
+	Clock    stream.Clock   // optional, used to timestamp and expire values if a half-life or duration is set; defaults to stream.SystemClock
 }
 
 var defaultConfig = &CoreConfig{
-	Sums:   SumsConfig{},
-	Window: nil,
-	Vars:   nil,
-	Decay:  nil,
+	Sums:     SumsConfig{},
+	Window:   nil,
+	Vars:     nil,
+	Decay:    nil,
+	HalfLife: nil,
+	Duration: nil,
+	Clock:    nil,
 }
 
 // MergeConfigs merges CoreConfig objects.
@@ -39,9 +49,12 @@ func MergeConfigs(configs ...*CoreConfig) (*CoreConfig, error) {
 		return configs[0], nil
 	default:
 		var (
-			window *int
-			vars   *int
-			decay  *float64
+			window   *int
+			vars     *int
+			decay    *float64
+			halfLife *time.Duration
+			duration *time.Duration
+			clock    stream.Clock
 		)
 		mergedConfig := &CoreConfig{
 			Sums: SumsConfig{},
@@ -75,12 +88,39 @@ func MergeConfigs(configs ...*CoreConfig) (*CoreConfig, error) {
 					return nil, errors.New("configs have differing decays")
 				}
 			}
+
+			if config.HalfLife != nil {
+				if halfLife == nil {
+					halfLife = config.HalfLife
+				} else if *halfLife != *config.HalfLife {
+					return nil, errors.New("configs have differing half-lives")
+				}
+			}
+
+			if config.Duration != nil {
+				if duration == nil {
+					duration = config.Duration
+				} else if *duration != *config.Duration {
+					return nil, errors.New("configs have differing durations")
+				}
+			}
+
+			if config.Clock != nil {
+				if clock == nil {
+					clock = config.Clock
+				} else if clock != config.Clock {
+					return nil, errors.New("configs have differing clocks")
+				}
+			}
 		}
 
 		mergedConfig.Sums = simplifySums(mergedConfig.Sums)
 		mergedConfig.Window = window
 		mergedConfig.Vars = vars
 		mergedConfig.Decay = decay
+		mergedConfig.HalfLife = halfLife
+		mergedConfig.Duration = duration
+		mergedConfig.Clock = clock
 		return mergedConfig, nil
 	}
 }
@@ -146,6 +186,28 @@ func validateConfig(config *CoreConfig) error {
 		}
 	}
 
+	if config.HalfLife != nil {
+		if *config.HalfLife <= 0 {
+			return errors.Errorf("config has a nonpositive half-life of %v", *config.HalfLife)
+		} else if *config.Window > 0 {
+			return errors.New("config cannot have HalfLife set with a nonzero window")
+		} else if config.Decay != nil {
+			return errors.New("config cannot have both Decay and HalfLife set")
+		} else if config.Duration != nil && *config.Duration > 0 {
+			return errors.New("config cannot have both HalfLife and Duration set")
+		}
+	}
+
+	if config.Duration != nil {
+		if *config.Duration < 0 {
+			return errors.Errorf("config has a negative duration of %v", *config.Duration)
+		} else if *config.Duration > 0 && *config.Window > 0 {
+			return errors.New("config cannot have Duration set with a nonzero window")
+		} else if *config.Duration > 0 && config.Decay != nil {
+			return errors.New("config cannot have both Decay and Duration set")
+		}
+	}
+
 	for _, tuple := range config.Sums {
 		err := validateTuple(tuple, config)
 		if err != nil {
@@ -213,5 +275,17 @@ func setConfigDefaults(config *CoreConfig) *CoreConfig {
 		config.Decay = defaultConfig.Decay
 	}
 
+	if config.HalfLife == nil {
+		config.HalfLife = defaultConfig.HalfLife
+	}
+
+	if config.Duration == nil {
+		config.Duration = defaultConfig.Duration
+	}
+
+	if config.Clock == nil {
+		config.Clock = defaultConfig.Clock
+	}
+
 	return config
 }
@@ -3,6 +3,7 @@ package joint
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -48,6 +49,68 @@ func TestValidateConfig(t *testing.T) {
 		assert.EqualError(t, err, "config cannot have Decay set with a nonzero window")
 	})
 
+	t.Run("fail: config with a nonpositive half-life is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Vars:     stream.IntPtr(2),
+			HalfLife: stream.DurationPtr(-time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config has a nonpositive half-life of -1s")
+	})
+
+	t.Run("fail: config with a set half-life and nonzero window is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(3),
+			Vars:     stream.IntPtr(2),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have HalfLife set with a nonzero window")
+	})
+
+	t.Run("fail: config with a set half-life and decay is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Vars:     stream.IntPtr(2),
+			Decay:    stream.FloatPtr(0.3),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have both Decay and HalfLife set")
+	})
+
+	t.Run("fail: config with a negative duration is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Vars:     stream.IntPtr(2),
+			Duration: stream.DurationPtr(-time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config has a negative duration of -1s")
+	})
+
+	t.Run("fail: config with a set duration and nonzero window is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(3),
+			Vars:     stream.IntPtr(2),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have Duration set with a nonzero window")
+	})
+
+	t.Run("fail: config with a set duration and decay is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Vars:     stream.IntPtr(2),
+			Decay:    stream.FloatPtr(0.3),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have both Decay and Duration set")
+	})
+
 	t.Run("fail: config with less than 2 vars is invalid", func(t *testing.T) {
 		config := &CoreConfig{
 			Window: stream.IntPtr(3),
@@ -258,6 +321,42 @@ func TestMergeConfigs(t *testing.T) {
 		assert.EqualError(t, err, "configs have differing windows")
 	})
 
+	t.Run("fail: multiple configs passed fails if half-lives are not compatible", func(t *testing.T) {
+		config1 := &CoreConfig{
+			Sums:     SumsConfig{{1, 2}},
+			Vars:     stream.IntPtr(2),
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		config2 := &CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Vars:     stream.IntPtr(2),
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(time.Minute),
+		}
+
+		_, err := MergeConfigs(config1, config2)
+		assert.EqualError(t, err, "configs have differing half-lives")
+	})
+
+	t.Run("fail: multiple configs passed fails if durations are not compatible", func(t *testing.T) {
+		config1 := &CoreConfig{
+			Sums:     SumsConfig{{1, 2}},
+			Vars:     stream.IntPtr(2),
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		config2 := &CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Vars:     stream.IntPtr(2),
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+		}
+
+		_, err := MergeConfigs(config1, config2)
+		assert.EqualError(t, err, "configs have differing durations")
+	})
+
 	t.Run("fail: multiple configs passed fails if vars are not compatible", func(t *testing.T) {
 		config1 := &CoreConfig{
 			Sums:   SumsConfig{{1, 2}, {2, 1}},
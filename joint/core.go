@@ -3,7 +3,9 @@ package joint
 import (
 	"math"
 	"sync"
+	"time"
 
+	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
 
@@ -22,6 +24,21 @@ type Core struct {
 	window  int
 	decay   *float64
 	queue   *queue.RingBuffer
+	// Used if halfLife > 0
+	halfLife    time.Duration
+	decayWeight float64
+	// Used if duration > 0
+	duration time.Duration
+	timed    *deque.Deque[timedValues]
+	// Used if halfLife > 0 or duration > 0
+	clock  stream.Clock
+	latest time.Time
+}
+
+// timedValues are the values in a time-based window, along with the time they were pushed at.
+type timedValues struct {
+	xs []float64
+	t  time.Time
 }
 
 // Init sets a CoreWrapper up with a core for consuming.
@@ -73,6 +90,18 @@ func NewCore(config *CoreConfig) (*Core, error) {
 	c.means = make([]float64, *config.Vars)
 	c.queue = queue.NewRingBuffer(uint64(c.window))
 
+	if config.HalfLife != nil {
+		c.halfLife = *config.HalfLife
+	}
+	if config.Duration != nil {
+		c.duration = *config.Duration
+	}
+	c.clock = config.Clock
+	if c.clock == nil {
+		c.clock = stream.SystemClock
+	}
+	c.timed = new(deque.Deque[timedValues])
+
 	return c, nil
 }
 
@@ -87,6 +116,32 @@ func (c *Core) Push(xs ...float64) error {
 // but does not lock. This should only be used if the user
 // plans to make use of the Lock()/Unlock() Core methods.
 func (c *Core) UnsafePush(xs ...float64) error {
+	var t time.Time
+	if c.duration != 0 || c.halfLife != 0 {
+		t = c.clock.Now()
+	}
+	return c.UnsafePushAt(t, xs...)
+}
+
+// PushAt adds a new value for a Core object to consume, which was observed
+// at the provided time. This is only meaningful if the Core tracks values over
+// a time-based window (i.e. has a Duration set), in which case values at or
+// before t - Duration are removed from the window, or if the Core decays values
+// by a HalfLife, in which case values are decayed by the time elapsed since the
+// previous push; otherwise the time is ignored. Values must be pushed in
+// chronological order. With a Duration, t must also be on the timeline of the
+// Core's Clock, against which Expire and the values of metrics remove values
+// (see stream.Clock).
+func (c *Core) PushAt(t time.Time, xs ...float64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.UnsafePushAt(t, xs...)
+}
+
+// UnsafePushAt adds a new value for a Core object to consume, which was observed
+// at the provided time, but does not lock. This should only be used if the user
+// plans to make use of the Lock()/Unlock() Core methods.
+func (c *Core) UnsafePushAt(t time.Time, xs ...float64) error {
 	if len(xs) != len(c.means) {
 		return errors.Errorf(
 			"tried to push %d values when core is tracking %d variables",
@@ -95,7 +150,18 @@ func (c *Core) UnsafePush(xs ...float64) error {
 		)
 	}
 
-	if c.window != 0 {
+	if (c.duration != 0 || c.halfLife != 0) && t.Before(c.latest) {
+		return errors.Errorf("time %v is before the latest time %v", t, c.latest)
+	}
+
+	if c.duration != 0 {
+		err := c.expire(t)
+		if err != nil {
+			return err
+		}
+
+		c.timed.PushBack(timedValues{xs: xs, t: t})
+	} else if c.window != 0 {
 		if c.queue.Len() == uint64(c.window) {
 			tail, err := c.queue.Get()
 			if err != nil {
@@ -115,18 +181,59 @@ func (c *Core) UnsafePush(xs ...float64) error {
 	}
 
 	var err error
-	if c.decay == nil {
+	if c.decay == nil && c.halfLife == 0 {
 		err = c.add(xs...)
 	} else {
-		err = c.addDecay(xs...)
+		err = c.addDecay(t, xs...)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "error adding %v to sums", xs)
 	}
 
+	if c.duration != 0 || c.halfLife != 0 {
+		c.latest = t
+	}
 	return nil
 }
 
+// expire removes all values at or before t - duration from a time-based window.
+func (c *Core) expire(t time.Time) error {
+	cutoff := t.Add(-c.duration)
+	for c.timed.Len() > 0 && !c.timed.Front().t.After(cutoff) {
+		tail := c.timed.PopFront()
+		err := c.remove(tail.xs...)
+		if err != nil {
+			return errors.Wrapf(err, "error removing %v from sums", tail.xs)
+		}
+	}
+	return nil
+}
+
+// Expire removes any values that have fallen out of a time-based window as of
+// the current time of the Core's Clock, so that the stats do not include stale
+// values after a period without pushes; this is a no-op unless the Core has a
+// Duration set. Otherwise, this locks the Core for writing, so it must not be
+// called while holding RLock.
+func (c *Core) Expire() error {
+	if c.duration == 0 {
+		return nil
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.UnsafeExpire()
+}
+
+// UnsafeExpire removes any values that have fallen out of a time-based window
+// as of the current time of the Core's Clock, but does not lock. This should
+// only be used if the user plans to make use of the Lock()/Unlock() Core methods.
+func (c *Core) UnsafeExpire() error {
+	if c.duration == 0 {
+		return nil
+	}
+	return c.expire(c.clock.Now())
+}
+
 // add updates the mean, count, and joint centralized power sums in an efficient
 // and stable (numerically speaking) way, which allows for more accurate reporting
 // of moments. See the following paper for details on the algorithm used:
@@ -199,15 +306,10 @@ func (c *Core) add(xs ...float64) error {
 // P. Pebay, T. B. Terriberry, H. Kolla, J. Bennett, Numerically stable, scalable
 // formulas for parallel and online computation of higher-order multivariate central
 // moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
-func (c *Core) addDecay(xs ...float64) error {
+func (c *Core) addDecay(t time.Time, xs ...float64) error {
 	c.count++
 
-	var decay float64
-	if c.count == 1 {
-		decay = 1
-	} else {
-		decay = *c.decay
-	}
+	decay := c.decayAt(t)
 
 	delta := make([]float64, len(c.means))
 	for i, x := range xs {
@@ -265,6 +367,26 @@ func (c *Core) addDecay(xs ...float64) error {
 	return nil
 }
 
+// decayAt returns the weight given to a new value pushed at time t, relative
+// to the total (decayed) weight of all values seen so far; see the univariate
+// Core's equivalent for details.
+func (c *Core) decayAt(t time.Time) float64 {
+	if c.halfLife == 0 {
+		if c.count == 1 {
+			return 1
+		}
+		return *c.decay
+	}
+
+	if c.count == 1 {
+		c.decayWeight = 1
+	} else {
+		elapsed := float64(t.Sub(c.latest)) / float64(c.halfLife)
+		c.decayWeight = c.decayWeight*math.Exp2(-elapsed) + 1
+	}
+	return 1 / c.decayWeight
+}
+
 // remove simply undoes the result of an add() call, and clears out the stats
 // if we remove the last item of a window (only needed in the case where the
 // window size is 1).
@@ -340,7 +462,150 @@ func (c *Core) remove(xs ...float64) error {
 	return nil
 }
 
-// Count returns the number of values seen seen globally.
+// Merge combines the stats of another Core into this one, so that the
+// Core reflects the union of the values seen by both. This allows for values
+// to be consumed in parallel (or on separate hosts) and combined afterwards.
+// Both Cores must be global (i.e. have a window of 0 and no duration), must not be
+// exponentially weighted, and must track the same number of variables;
+// moreover, the other Core must track every sum that this Core tracks.
+// See the following paper for details on the algorithm used:
+// P. Pebay, T. B. Terriberry, H. Kolla, J. Bennett, Numerically stable, scalable
+// formulas for parallel and online computation of higher-order multivariate central
+// moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
+func (c *Core) Merge(other *Core) error {
+	// copy the other Core's stats first, so that we never hold both locks
+	// at once (which also allows for a Core to be merged with itself)
+	other.mux.RLock()
+	otherWindow := other.window
+	otherDuration := other.duration
+	otherDecay := other.decay
+	otherHalfLife := other.halfLife
+	otherCount := other.count
+	otherMeans := make([]float64, len(other.means))
+	copy(otherMeans, other.means)
+	otherSums := make(map[uint64]float64, len(other.sums))
+	for hash, sum := range other.sums {
+		otherSums[hash] = sum
+	}
+	other.mux.RUnlock()
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.window != 0 || otherWindow != 0 || c.duration != 0 || otherDuration != 0 {
+		return errors.New("cannot merge Cores with nonzero windows")
+	}
+
+	if c.decay != nil || otherDecay != nil || c.halfLife != 0 || otherHalfLife != 0 {
+		return errors.New("cannot merge Cores with decay set")
+	}
+
+	if len(otherMeans) != len(c.means) {
+		return errors.Errorf(
+			"cannot merge Core tracking %d variables into Core tracking %d variables",
+			len(otherMeans),
+			len(c.means),
+		)
+	}
+
+	for hash := range c.sums {
+		if _, ok := otherSums[hash]; !ok {
+			return errors.New("cannot merge Core that does not track all sums of this Core")
+		}
+	}
+
+	if otherCount == 0 {
+		return nil
+	}
+
+	countA := float64(c.count)
+	countB := float64(otherCount)
+	count := countA + countB
+
+	// shiftA and shiftB are the differences between the merged mean
+	// and the means of each partition
+	shiftA := make([]float64, len(c.means))
+	shiftB := make([]float64, len(c.means))
+	for i := range c.means {
+		delta := otherMeans[i] - c.means[i]
+		shiftA[i] = -countB * delta / count
+		shiftB[i] = countA * delta / count
+	}
+
+	// the joint power sum of a partition relative to its own means; note that
+	// the sum for the zero Tuple is just the count, and any sum for a Tuple
+	// with a single exponent of 1 is always 0
+	sum := func(sums map[uint64]float64, n float64, m Tuple) float64 {
+		switch m.abs() {
+		case 0:
+			return n
+		case 1:
+			return 0
+		default:
+			return sums[m.hash()]
+		}
+	}
+
+	// recenter each partition's joint power sums around the merged means,
+	// using the multinomial expansion of ((x - μ_A) + (μ_A - μ))^m
+	for _, tuple := range c.tuples {
+		err := iter(tuple, false, func(xs ...int) error {
+			a := Tuple(xs)
+			hash := a.hash()
+			c.newSums[hash] = 0
+			// sums for the zero Tuple and for Tuples with a single exponent
+			// of 1 are always kept at 0
+			if a.abs() < 2 {
+				return nil
+			}
+
+			return iter(a, false, func(xs ...int) error {
+				b := Tuple(xs)
+
+				multinomial, err := multinom(a, b)
+				if err != nil {
+					return err
+				}
+
+				diff, err := sub(a, b)
+				if err != nil {
+					return err
+				}
+
+				powA, err := pow(shiftA, b)
+				if err != nil {
+					return err
+				}
+
+				powB, err := pow(shiftB, b)
+				if err != nil {
+					return err
+				}
+
+				c.newSums[hash] += float64(multinomial) * (powA*sum(c.sums, countA, diff) + powB*sum(otherSums, countB, diff))
+				return nil
+			})
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error merging sums for tuple %v", tuple)
+		}
+	}
+
+	for hash, sum := range c.newSums {
+		c.sums[hash] = sum
+	}
+
+	c.count += otherCount
+	for i := range c.means {
+		c.means[i] -= shiftA[i]
+	}
+
+	return nil
+}
+
+// Count returns the number of values seen seen globally. If the Core tracks
+// values over a time-based window, this does not remove values that have expired
+// since the latest push; call Expire beforehand to do so.
 func (c *Core) Count() int {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -354,7 +619,8 @@ func (c *Core) UnsafeCount() int {
 	return c.count
 }
 
-// Mean returns the mean of values seen for a given variable.
+// Mean returns the mean of values seen for a given variable. As with
+// Count, call Expire beforehand to remove expired values.
 func (c *Core) Mean(i int) (float64, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -379,7 +645,8 @@ func (c *Core) UnsafeMean(i int) (float64, error) {
 // Sum returns the joint centralized sum of values seen for a provided
 // exponent Tuple. In other words, for a Tuple m = (m_1, ..., m_k),
 // this returns the sum of (x_i1 - μ_1)^m_1 * ... * (x_ik - μ_k)^m_k over
-// all joint data points (x_i1, ..., x_ik).
+// all joint data points (x_i1, ..., x_ik). As with Count, call Expire
+// beforehand to remove expired values.
 func (c *Core) Sum(xs ...int) (float64, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -424,8 +691,21 @@ func (c *Core) UnsafeClear() {
 	}
 
 	c.count = 0
+	c.decayWeight = 0
 	c.queue.Dispose()
 	c.queue = queue.NewRingBuffer(uint64(c.window))
+	c.timed.Clear()
+	c.latest = time.Time{}
+}
+
+// rlock removes any values that have fallen out of a time-based window under the
+// write lock, and then locks the Core for reading; the Core is locked for reading
+// even if an error is returned. This is used by the values of metrics, rather than
+// by the accessors of the Core, which are safe under RLock.
+func (c *Core) rlock() error {
+	err := c.Expire()
+	c.mux.RLock()
+	return err
 }
 
 // RLock locks the Core internals for reading.
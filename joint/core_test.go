@@ -2,7 +2,9 @@ package joint
 
 import (
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -478,3 +480,302 @@ func TestLock(t *testing.T) {
 	require.NoError(t, err)
 	testutil.Approx(t, 26./3., sum)
 }
+
+func TestMerge(t *testing.T) {
+	t.Run("pass: merged Core matches Core that saw all values", func(t *testing.T) {
+		expected := &mockWrapper{window: stream.IntPtr(0)}
+		left := &mockWrapper{window: stream.IntPtr(0)}
+		right := &mockWrapper{window: stream.IntPtr(0)}
+		for _, wrapper := range []*mockWrapper{expected, left, right} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		xs := []float64{1, 2, 3, 4, 8, -5, 10.5}
+		for i, x := range xs {
+			err := expected.core.Push(x, x*x-x)
+			require.NoError(t, err)
+
+			if i < 4 {
+				err = left.core.Push(x, x*x-x)
+			} else {
+				err = right.core.Push(x, x*x-x)
+			}
+			require.NoError(t, err)
+		}
+
+		err := left.core.Merge(right.core)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected.core.count, left.core.count)
+		testutil.ApproxSlice(t, expected.core.means, left.core.means)
+		require.Equal(t, len(expected.core.sums), len(left.core.sums))
+		for hash, sum := range expected.core.sums {
+			assert.InEpsilon(t, 1+sum, 1+left.core.sums[hash], 1e-9)
+		}
+
+		// the merged Core should keep consuming values as usual
+		err = expected.core.Push(7, 42)
+		require.NoError(t, err)
+		err = left.core.Push(7, 42)
+		require.NoError(t, err)
+
+		testutil.ApproxSlice(t, expected.core.means, left.core.means)
+		for hash, sum := range expected.core.sums {
+			assert.InEpsilon(t, 1+sum, 1+left.core.sums[hash], 1e-9)
+		}
+	})
+
+	t.Run("fail: windowed Cores cannot be merged", func(t *testing.T) {
+		global := &mockWrapper{window: stream.IntPtr(0)}
+		windowed := &mockWrapper{window: stream.IntPtr(3)}
+		for _, wrapper := range []*mockWrapper{global, windowed} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		err := global.core.Merge(windowed.core)
+		assert.EqualError(t, err, "cannot merge Cores with nonzero windows")
+	})
+
+	t.Run("fail: decayed Cores cannot be merged", func(t *testing.T) {
+		global := &mockWrapper{window: stream.IntPtr(0)}
+		decayed := &mockWrapper{window: stream.IntPtr(0), decay: stream.FloatPtr(0.3)}
+		for _, wrapper := range []*mockWrapper{global, decayed} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		err := decayed.core.Merge(global.core)
+		assert.EqualError(t, err, "cannot merge Cores with decay set")
+	})
+
+	t.Run("fail: Cores must track the same number of variables", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(0)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		core, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{{2, 2, 2}},
+			Window: stream.IntPtr(0),
+		})
+		require.NoError(t, err)
+
+		err = wrapper.core.Merge(core)
+		assert.EqualError(t, err, "cannot merge Core tracking 3 variables into Core tracking 2 variables")
+	})
+
+	t.Run("fail: Core must track all sums", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(0)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		core, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{{1, 1}},
+			Window: stream.IntPtr(0),
+		})
+		require.NoError(t, err)
+
+		err = wrapper.core.Merge(core)
+		assert.EqualError(t, err, "cannot merge Core that does not track all sums of this Core")
+	})
+}
+
+func TestPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		timed, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{{2, 2}},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(10 * time.Second),
+			Clock:    testutil.NewClock(start.Add(12 * time.Second)),
+		})
+		require.NoError(t, err)
+
+		expected, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{{2, 2}},
+			Window: stream.IntPtr(0),
+		})
+		require.NoError(t, err)
+
+		offsets := []int{0, 2, 3, 6, 12}
+		xs := []float64{1, 2, 3, 4, 8}
+		for i, x := range xs {
+			err := timed.PushAt(start.Add(time.Duration(offsets[i])*time.Second), x, x*x)
+			require.NoError(t, err)
+		}
+
+		// the values at 0s and 2s are at or before the cutoff of 12s - 10s
+		for _, x := range xs[2:] {
+			err := expected.Push(x, x*x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 3, timed.Count())
+		testutil.ApproxSlice(t, expected.means, timed.means)
+		for hash, sum := range expected.sums {
+			testutil.Approx(t, sum, timed.sums[hash])
+		}
+	})
+
+	t.Run("pass: Push uses the configured clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		core, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+			Clock:    clock,
+		})
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := core.Push(x, -x)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		// only the values pushed at 60s remain as of 90s
+		err = core.Expire()
+		require.NoError(t, err)
+		assert.Equal(t, 1, core.Count())
+		testutil.ApproxSlice(t, []float64{3, -3}, core.means)
+	})
+
+	t.Run("pass: expired values are removed by Expire", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		core, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+			Clock:    clock,
+		})
+		require.NoError(t, err)
+
+		for _, x := range []float64{100, 4} {
+			err := core.Push(x, -x)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		// the accessors do not remove values on their own, so they can be called under RLock
+		core.RLock()
+		assert.Equal(t, 2, core.Count())
+		core.RUnlock()
+
+		// only the values pushed at 30s are left, without any further pushes
+		err = core.Expire()
+		require.NoError(t, err)
+		assert.Equal(t, 1, core.Count())
+		mean, err := core.Mean(1)
+		require.NoError(t, err)
+		testutil.Approx(t, -4., mean)
+
+		clock.Advance(time.Hour)
+		err = core.Expire()
+		require.NoError(t, err)
+		assert.Equal(t, 0, core.Count())
+		_, err = core.Mean(0)
+		assert.EqualError(t, err, "no values seen yet")
+		_, err = core.Sum(1, 1)
+		assert.EqualError(t, err, "no values seen yet")
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+		})
+		require.NoError(t, err)
+
+		err = core.PushAt(start, 1, 2)
+		require.NoError(t, err)
+
+		err = core.PushAt(start.Add(-time.Second), 2, 3)
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
+func TestHalfLife(t *testing.T) {
+	start := time.Unix(1000, 0)
+	config := func() *CoreConfig {
+		return &CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(10 * time.Second),
+		}
+	}
+
+	t.Run("pass: values are weighted by the time elapsed since they were pushed", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		offsets := []float64{0, 1, 1, 7, 30, 32.5, 60}
+		xs := []float64{1, 2, 3, 4, 8, -5, 10.5}
+		ys := []float64{2, -1, 4, 4, 9, 0, 3}
+		for i := range xs {
+			err := core.PushAt(start.Add(time.Duration(offsets[i]*float64(time.Second))), xs[i], ys[i])
+			require.NoError(t, err)
+		}
+
+		// the weight of each value is halved every 10 seconds after it was pushed
+		last := offsets[len(offsets)-1]
+		weights := make([]float64, len(xs))
+		var total, meanX, meanY float64
+		for i := range xs {
+			weights[i] = math.Exp2(-(last - offsets[i]) / 10)
+			total += weights[i]
+			meanX += weights[i] * xs[i]
+			meanY += weights[i] * ys[i]
+		}
+		meanX /= total
+		meanY /= total
+
+		var cov float64
+		for i := range xs {
+			cov += weights[i] * (xs[i] - meanX) * (ys[i] - meanY) / total
+		}
+
+		testutil.ApproxSlice(t, []float64{meanX, meanY}, core.means)
+		testutil.Approx(t, cov, core.sums[Tuple{1, 1}.hash()])
+	})
+
+	t.Run("pass: Push uses the configured clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		c := config()
+		c.Clock = clock
+		core, err := NewCore(c)
+		require.NoError(t, err)
+
+		err = core.Push(0, 3)
+		require.NoError(t, err)
+		clock.Advance(10 * time.Second)
+		err = core.Push(3, 0)
+		require.NoError(t, err)
+
+		// the first value has half the weight of the second
+		testutil.ApproxSlice(t, []float64{2, 1}, core.means)
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		err = core.PushAt(start, 1, 2)
+		require.NoError(t, err)
+
+		err = core.PushAt(start.Add(-time.Second), 2, 3)
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+
+	t.Run("fail: Cores with a half-life cannot be merged", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+		global, err := NewCore(&CoreConfig{Sums: SumsConfig{{1, 1}}, Window: stream.IntPtr(0)})
+		require.NoError(t, err)
+
+		err = global.Merge(core)
+		assert.EqualError(t, err, "cannot merge Cores with decay set")
+	})
+}
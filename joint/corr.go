@@ -3,6 +3,7 @@ package joint
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -73,31 +74,62 @@ func (corr *Corr) Push(xs ...float64) error {
 	return nil
 }
 
+// PushAt adds a new pair of values for Corr to consume, which was observed at the
+// provided time; this is only meaningful if the Core tracks values over a time-based window.
+func (corr *Corr) PushAt(t time.Time, xs ...float64) error {
+	if !corr.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	if len(xs) != 2 {
+		return errors.Errorf(
+			"Corr expected 2 arguments: got %d (%v)",
+			len(xs),
+			xs,
+		)
+	}
+
+	err := corr.core.PushAt(t, xs...)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample Pearson correlation coefficient.
 func (corr *Corr) Value() (float64, error) {
 	if !corr.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	corr.core.RLock()
+	err := corr.core.rlock()
 	defer corr.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return corr.value()
+}
 
+// value returns the value of the sample Pearson correlation coefficient,
+// but does not lock the Core.
+func (corr *Corr) value() (float64, error) {
 	// this is technically not the covariance, as it is not normalized by
 	// the sample size (minus 1), but the denominator is cancelled out
 	// when dividing by the sqrt of the variances, so we can avoid extra
 	// float ops here
-	cov, err := corr.core.Sum(1, 1)
+	cov, err := corr.core.UnsafeSum(1, 1)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {1, 1}")
 	}
 
 	// ditto with the "variance" variables here, as with above
-	xVar, err := corr.core.Sum(2, 0)
+	xVar, err := corr.core.UnsafeSum(2, 0)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {2, 0}")
 	}
 
-	yVar, err := corr.core.Sum(0, 2)
+	yVar, err := corr.core.UnsafeSum(0, 2)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {0, 2}")
 	}
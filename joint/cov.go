@@ -2,6 +2,7 @@ package joint
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -68,21 +69,51 @@ func (cov *Cov) Push(xs ...float64) error {
 	return nil
 }
 
+// PushAt adds a new pair of values for Cov to consume, which was observed at the
+// provided time; this is only meaningful if the Core tracks values over a time-based window.
+func (cov *Cov) PushAt(t time.Time, xs ...float64) error {
+	if !cov.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	if len(xs) != 2 {
+		return errors.Errorf(
+			"Cov expected 2 arguments: got %d (%v)",
+			len(xs),
+			xs,
+		)
+	}
+
+	err := cov.core.PushAt(t, xs...)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample covariance.
 func (cov *Cov) Value() (float64, error) {
 	if !cov.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	cov.core.RLock()
+	err := cov.core.rlock()
 	defer cov.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return cov.value()
+}
 
-	covariance, err := cov.core.Sum(1, 1)
+// value returns the value of the sample covariance, but does not lock the Core.
+func (cov *Cov) value() (float64, error) {
+	covariance, err := cov.core.UnsafeSum(1, 1)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
 
-	count := cov.core.Count()
+	count := cov.core.UnsafeCount()
 	covariance /= (float64(count) - 1.)
 
 	return covariance, nil
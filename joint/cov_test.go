@@ -3,11 +3,13 @@ package joint
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alexander-yu/stream"
 	testutil "github.com/alexander-yu/stream/util/test"
 )
 
@@ -160,3 +162,39 @@ func TestCovString(t *testing.T) {
 	expectedString := "joint.Cov_{window:3}"
 	assert.Equal(t, expectedString, cov.String())
 }
+
+func TestCovPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := testutil.NewClock(start.Add(12 * time.Second))
+	core, err := NewCore(&CoreConfig{
+		Sums:     SumsConfig{{1, 1}},
+		Window:   stream.IntPtr(0),
+		Duration: stream.DurationPtr(10 * time.Second),
+		Clock:    clock,
+	})
+	require.NoError(t, err)
+
+	cov := NewGlobalCov()
+	cov.SetCore(core)
+
+	for i, x := range []float64{100, 1, 2, 3} {
+		err := cov.PushAt(start.Add(time.Duration(4*i)*time.Second), x, -2*x)
+		require.NoError(t, err)
+	}
+
+	// only 1, 2, 3 remain, with a sample covariance of -2 * var(1, 2, 3)
+	value, err := cov.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, -2., value)
+
+	// the values expire without any further pushes
+	clock.Advance(time.Hour)
+	_, err = cov.Value()
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	err = cov.PushAt(start.Add(time.Minute), 1)
+	testutil.ContainsError(t, err, "Cov expected 2 arguments: got 1 ([1])")
+
+	err = NewGlobalCov().PushAt(start, 1, 2)
+	testutil.ContainsError(t, err, "Core is not set")
+}
@@ -0,0 +1,346 @@
+package joint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/gammazero/deque"
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older snapshots.
+const encodingVersion uint8 = 1
+
+// MarshalBinary encodes the state of the Core (including any values
+// currently in its window) into a binary form, so that it can be
+// checkpointed and later restored with UnmarshalBinary. The Clock of
+// the Core is not encoded.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (c *Core) MarshalBinary() ([]byte, error) {
+	// reading the window contents requires mutating the queue,
+	// so we need to hold the write lock
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	window, err := c.windowValues()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading window values")
+	}
+
+	var hasDecay uint8
+	var decay float64
+	if c.decay != nil {
+		hasDecay = 1
+		decay = *c.decay
+	}
+
+	fields := []interface{}{
+		encodingVersion,
+		int64(c.count),
+		int64(c.window),
+		int64(c.halfLife),
+		c.decayWeight,
+		int64(c.duration),
+		encodeTime(c.latest),
+		hasDecay,
+		decay,
+		uint32(len(c.means)),
+		c.means,
+		uint32(len(c.tuples)),
+	}
+
+	for _, tuple := range c.tuples {
+		exponents := make([]int64, len(tuple))
+		for i, k := range tuple {
+			exponents[i] = int64(k)
+		}
+		fields = append(fields, uint32(len(tuple)), exponents)
+	}
+
+	// sort the hashes so that the encoding is deterministic
+	hashes := make([]uint64, 0, len(c.sums))
+	for hash := range c.sums {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	fields = append(fields, uint32(len(hashes)))
+	for _, hash := range hashes {
+		fields = append(fields, hash, c.sums[hash])
+	}
+
+	// values in a time-based window are followed by the time they were pushed at
+	fields = append(fields, uint32(len(window)))
+	for i, xs := range window {
+		fields = append(fields, xs)
+		if c.duration != 0 {
+			fields = append(fields, c.timed.At(i).t.UnixNano())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	for _, field := range fields {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding Core")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the Core from data produced by
+// MarshalBinary, replacing any state the Core currently has. If the Core
+// does not have a Clock set already, stream.SystemClock is used.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (c *Core) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var (
+		count       int64
+		window      int64
+		halfLife    int64
+		decayWeight float64
+		duration    int64
+		latest      int64
+		hasDecay    uint8
+		decay       float64
+		numVars     uint32
+	)
+	for _, field := range []interface{}{
+		&count,
+		&window,
+		&halfLife,
+		&decayWeight,
+		&duration,
+		&latest,
+		&hasDecay,
+		&decay,
+		&numVars,
+	} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding Core")
+		}
+	}
+
+	if window < 0 {
+		return errors.Errorf("encoded Core has a negative window of %d", window)
+	} else if duration < 0 {
+		return errors.Errorf("encoded Core has a negative duration of %v", time.Duration(duration))
+	} else if halfLife < 0 {
+		return errors.Errorf("encoded Core has a negative half-life of %v", time.Duration(halfLife))
+	}
+
+	means, err := readFloats(r, numVars)
+	if err != nil {
+		return errors.Wrap(err, "error decoding means")
+	}
+
+	var numTuples uint32
+	err = binary.Read(r, binary.BigEndian, &numTuples)
+	if err != nil {
+		return errors.Wrap(err, "error decoding number of tuples")
+	}
+
+	tuples := []Tuple{}
+	for i := uint32(0); i < numTuples; i++ {
+		var length uint32
+		err := binary.Read(r, binary.BigEndian, &length)
+		if err != nil {
+			return errors.Wrap(err, "error decoding tuple length")
+		}
+
+		if 8*int(length) > r.Len() {
+			return errors.Errorf("encoded Core has a tuple of length %d but only %d bytes remaining", length, r.Len())
+		}
+
+		exponents := make([]int64, length)
+		err = binary.Read(r, binary.BigEndian, exponents)
+		if err != nil {
+			return errors.Wrap(err, "error decoding tuple")
+		}
+
+		tuple := make(Tuple, length)
+		for j, k := range exponents {
+			tuple[j] = int(k)
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	var numSums uint32
+	err = binary.Read(r, binary.BigEndian, &numSums)
+	if err != nil {
+		return errors.Wrap(err, "error decoding number of sums")
+	}
+
+	if 16*int(numSums) > r.Len() {
+		return errors.Errorf("encoded Core has %d sums but only %d bytes remaining", numSums, r.Len())
+	}
+
+	sums := map[uint64]float64{}
+	newSums := map[uint64]float64{}
+	for i := uint32(0); i < numSums; i++ {
+		var (
+			hash uint64
+			sum  float64
+		)
+		for _, field := range []interface{}{&hash, &sum} {
+			err := binary.Read(r, binary.BigEndian, field)
+			if err != nil {
+				return errors.Wrap(err, "error decoding sums")
+			}
+		}
+		sums[hash] = sum
+		newSums[hash] = 0
+	}
+
+	var numValues uint32
+	err = binary.Read(r, binary.BigEndian, &numValues)
+	if err != nil {
+		return errors.Wrap(err, "error decoding window size")
+	}
+
+	if duration == 0 && int64(numValues) > window {
+		return errors.Errorf("encoded Core has %d window values for a window of %d", numValues, window)
+	}
+
+	q := queue.NewRingBuffer(uint64(window))
+	timed := new(deque.Deque[timedValues])
+	for i := uint32(0); i < numValues; i++ {
+		xs, err := readFloats(r, numVars)
+		if err != nil {
+			return errors.Wrap(err, "error decoding window values")
+		}
+
+		if duration != 0 {
+			var t int64
+			err := binary.Read(r, binary.BigEndian, &t)
+			if err != nil {
+				return errors.Wrap(err, "error decoding window times")
+			}
+
+			timed.PushBack(timedValues{xs: xs, t: time.Unix(0, t)})
+			continue
+		}
+
+		err = q.Put(xs)
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %v to queue", xs)
+		}
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded Core has %d trailing bytes", r.Len())
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.queue != nil {
+		c.queue.Dispose()
+	}
+
+	if c.clock == nil {
+		c.clock = stream.SystemClock
+	}
+
+	c.count = int(count)
+	c.window = int(window)
+	c.halfLife = time.Duration(halfLife)
+	c.decayWeight = decayWeight
+	c.duration = time.Duration(duration)
+	c.decay = nil
+	if hasDecay != 0 {
+		c.decay = &decay
+	}
+	c.means = means
+	c.tuples = tuples
+	c.sums = sums
+	c.newSums = newSums
+	c.queue = q
+	c.timed = timed
+	c.latest = decodeTime(latest)
+
+	return nil
+}
+
+// windowValues returns the values currently in the window, from oldest
+// to newest. The queue is drained and refilled in the same order, so this
+// must be called while holding the write lock.
+func (c *Core) windowValues() ([][]float64, error) {
+	if c.duration != 0 {
+		values := make([][]float64, 0, c.timed.Len())
+		for i := 0; i < c.timed.Len(); i++ {
+			values = append(values, c.timed.At(i).xs)
+		}
+		return values, nil
+	} else if c.window == 0 {
+		return [][]float64{}, nil
+	}
+
+	n := c.queue.Len()
+	values := make([][]float64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		val, err := c.queue.Get()
+		if err != nil {
+			return nil, errors.Wrap(err, "error popping item from queue")
+		}
+
+		values = append(values, val.([]float64))
+	}
+
+	for _, xs := range values {
+		err := c.queue.Put(xs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error pushing %v to queue", xs)
+		}
+	}
+
+	return values, nil
+}
+
+func readFloats(r *bytes.Reader, n uint32) ([]float64, error) {
+	if 8*int(n) > r.Len() {
+		return nil, errors.Errorf("expected %d floats but only %d bytes remaining", n, r.Len())
+	}
+
+	xs := make([]float64, n)
+	err := binary.Read(r, binary.BigEndian, xs)
+	if err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+// encodeTime encodes a time as nanoseconds since the Unix epoch,
+// where the zero time.Time is encoded as 0.
+func encodeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func decodeTime(t int64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
@@ -0,0 +1,205 @@
+package joint
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func assertCoresApprox(t *testing.T, expected *Core, actual *Core) {
+	assert.Equal(t, expected.count, actual.count)
+	assert.Equal(t, expected.window, actual.window)
+	assert.Equal(t, expected.tuples, actual.tuples)
+	testutil.ApproxSlice(t, expected.means, actual.means)
+
+	require.Equal(t, len(expected.sums), len(actual.sums))
+	for hash, sum := range expected.sums {
+		testutil.Approx(t, sum, actual.sums[hash])
+	}
+
+	require.Equal(t, len(expected.newSums), len(actual.newSums))
+	for hash := range expected.newSums {
+		_, ok := actual.newSums[hash]
+		assert.True(t, ok)
+	}
+}
+
+func TestCoreMarshalBinary(t *testing.T) {
+	t.Run("pass: restored windowed Core matches original", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(3)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4, 8} {
+			err := wrapper.core.Push(x, x*x)
+			require.NoError(t, err)
+		}
+
+		data, err := wrapper.core.MarshalBinary()
+		require.NoError(t, err)
+
+		core := &Core{}
+		err = core.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assertCoresApprox(t, wrapper.core, core)
+		assert.Nil(t, core.decay)
+		assert.Equal(t, uint64(3), core.queue.Len())
+
+		// marshaling should not disturb the original window
+		assert.Equal(t, uint64(3), wrapper.core.queue.Len())
+
+		// both Cores should evict the same values going forward
+		for _, x := range []float64{5, 6} {
+			err := wrapper.core.Push(x, x*x)
+			require.NoError(t, err)
+			err = core.Push(x, x*x)
+			require.NoError(t, err)
+		}
+
+		assertCoresApprox(t, wrapper.core, core)
+	})
+
+	t.Run("pass: restored decayed Core matches original", func(t *testing.T) {
+		wrapper := &mockWrapper{
+			decay:  stream.FloatPtr(0.3),
+			window: stream.IntPtr(0),
+		}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		for _, x := range []float64{3, 4, 8} {
+			err := wrapper.core.Push(x, x*x)
+			require.NoError(t, err)
+		}
+
+		data, err := wrapper.core.MarshalBinary()
+		require.NoError(t, err)
+
+		core := &Core{}
+		err = core.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.3, *core.decay)
+
+		err = wrapper.core.Push(2, 4)
+		require.NoError(t, err)
+		err = core.Push(2, 4)
+		require.NoError(t, err)
+
+		assertCoresApprox(t, wrapper.core, core)
+	})
+
+	t.Run("pass: restored Core keeps time-based window values", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		config := func() *CoreConfig {
+			return &CoreConfig{
+				Sums:     SumsConfig{{1, 1}},
+				Window:   stream.IntPtr(0),
+				Duration: stream.DurationPtr(10 * time.Second),
+			}
+		}
+
+		src, err := NewCore(config())
+		require.NoError(t, err)
+		for i, x := range []float64{1, 2, 3} {
+			err := src.PushAt(start.Add(time.Duration(4*i)*time.Second), x, x*x)
+			require.NoError(t, err)
+		}
+
+		data, err := src.MarshalBinary()
+		require.NoError(t, err)
+
+		dst := &Core{}
+		err = dst.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10*time.Second, dst.duration)
+		assert.Equal(t, 3, dst.timed.Len())
+
+		// the value at 4s should be removed along with the value at 0s
+		err = src.PushAt(start.Add(14*time.Second), 4, 16)
+		require.NoError(t, err)
+		err = dst.PushAt(start.Add(14*time.Second), 4, 16)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, dst.count)
+		assertCoresApprox(t, src, dst)
+	})
+}
+
+func TestCoreMarshalBinaryHalfLife(t *testing.T) {
+	start := time.Unix(1000, 0)
+	src, err := NewCore(&CoreConfig{
+		Sums:     SumsConfig{{1, 1}},
+		Window:   stream.IntPtr(0),
+		HalfLife: stream.DurationPtr(10 * time.Second),
+	})
+	require.NoError(t, err)
+	for i, x := range []float64{1, 2, 3} {
+		err := src.PushAt(start.Add(time.Duration(4*i)*time.Second), x, x*x)
+		require.NoError(t, err)
+	}
+
+	data, err := src.MarshalBinary()
+	require.NoError(t, err)
+
+	dst := &Core{}
+	err = dst.UnmarshalBinary(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, dst.halfLife)
+	assert.Equal(t, src.latest, dst.latest)
+
+	// both Cores should decay the same amount going forward
+	err = src.PushAt(start.Add(20*time.Second), 4, 16)
+	require.NoError(t, err)
+	err = dst.PushAt(start.Add(20*time.Second), 4, 16)
+	require.NoError(t, err)
+
+	testutil.Approx(t, src.decayWeight, dst.decayWeight)
+	assertCoresApprox(t, src, dst)
+}
+
+func TestCoreUnmarshalBinary(t *testing.T) {
+	core, err := NewCore(&CoreConfig{
+		Sums:   SumsConfig{{1, 1}},
+		Window: stream.IntPtr(3),
+	})
+	require.NoError(t, err)
+	err = core.Push(1, 2)
+	require.NoError(t, err)
+
+	data, err := core.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("fail: unsupported version returns error", func(t *testing.T) {
+		corrupt := append([]byte{}, data...)
+		corrupt[0] = 0
+		err := (&Core{}).UnmarshalBinary(corrupt)
+		assert.EqualError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: newer version returns error", func(t *testing.T) {
+		corrupt := append([]byte{}, data...)
+		corrupt[0] = encodingVersion + 1
+		err := (&Core{}).UnmarshalBinary(corrupt)
+		assert.EqualError(t, err, fmt.Sprintf("unsupported encoding version %d", encodingVersion+1))
+	})
+
+	t.Run("fail: truncated data returns error", func(t *testing.T) {
+		err := (&Core{}).UnmarshalBinary(data[:len(data)-1])
+		testutil.ContainsError(t, err, "error decoding window values")
+	})
+
+	t.Run("fail: trailing data returns error", func(t *testing.T) {
+		err := (&Core{}).UnmarshalBinary(append(data, 0))
+		assert.EqualError(t, err, "encoded Core has 1 trailing bytes")
+	})
+}
@@ -3,6 +3,7 @@ package joint
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,8 +12,9 @@ import (
 
 // EWMCorr is a metric that tracks the sample Pearson correlation coefficient.
 type EWMCorr struct {
-	decay float64
-	core  *Core
+	decay    float64
+	halfLife time.Duration
+	core     *Core
 }
 
 // NewEWMCorr instantiates a EWMCorr struct.
@@ -20,6 +22,13 @@ func NewEWMCorr(decay float64) *EWMCorr {
 	return &EWMCorr{decay: decay}
 }
 
+// NewHalfLifeEWMCorr instantiates a EWMCorr struct that decays values by the time
+// elapsed between pushes instead of by a constant decay per push; the weight
+// of each value is halved for every half-life that passes after it is pushed.
+func NewHalfLifeEWMCorr(halfLife time.Duration) *EWMCorr {
+	return &EWMCorr{halfLife: halfLife}
+}
+
 // SetCore sets the Core.
 func (corr *EWMCorr) SetCore(c *Core) {
 	corr.core = c
@@ -32,6 +41,18 @@ func (corr *EWMCorr) IsSetCore() bool {
 
 // Config returns the CoreConfig needed.
 func (corr *EWMCorr) Config() *CoreConfig {
+	if corr.halfLife != 0 {
+		return &CoreConfig{
+			Sums: SumsConfig{
+				{1, 1},
+				{2, 0},
+				{0, 2},
+			},
+			Window:   stream.IntPtr(0),
+			HalfLife: &corr.halfLife,
+		}
+	}
+
 	return &CoreConfig{
 		Sums: SumsConfig{
 			{1, 1},
@@ -46,6 +67,9 @@ func (corr *EWMCorr) Config() *CoreConfig {
 // String returns a string representation of the metric.
 func (corr *EWMCorr) String() string {
 	name := "joint.EWMCorr"
+	if corr.halfLife != 0 {
+		return fmt.Sprintf("%s_{halfLife:%v}", name, corr.halfLife)
+	}
 	return fmt.Sprintf("%s_{decay:%v}", name, corr.decay)
 }
 
@@ -70,31 +94,62 @@ func (corr *EWMCorr) Push(xs ...float64) error {
 	return nil
 }
 
+// PushAt adds a new pair of values for EWMCorr to consume, which was observed at the
+// provided time; this is only meaningful if the EWMCorr decays values by a half-life.
+func (corr *EWMCorr) PushAt(t time.Time, xs ...float64) error {
+	if !corr.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	if len(xs) != 2 {
+		return errors.Errorf(
+			"EWMCorr expected 2 arguments: got %d (%v)",
+			len(xs),
+			xs,
+		)
+	}
+
+	err := corr.core.PushAt(t, xs...)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample Pearson correlation coefficient.
 func (corr *EWMCorr) Value() (float64, error) {
 	if !corr.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	corr.core.RLock()
+	err := corr.core.rlock()
 	defer corr.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return corr.value()
+}
 
+// value returns the value of the sample Pearson correlation coefficient,
+// but does not lock the Core.
+func (corr *EWMCorr) value() (float64, error) {
 	// this is technically not the covariance, as it is not normalized by
 	// the sample size (minus 1), but the denominator is cancelled out
 	// when dividing by the sqrt of the variances, so we can avoid extra
 	// float ops here
-	cov, err := corr.core.Sum(1, 1)
+	cov, err := corr.core.UnsafeSum(1, 1)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {1, 1}")
 	}
 
 	// ditto with the "variance" variables here, as with above
-	xVar, err := corr.core.Sum(2, 0)
+	xVar, err := corr.core.UnsafeSum(2, 0)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {2, 0}")
 	}
 
-	yVar, err := corr.core.Sum(0, 2)
+	yVar, err := corr.core.UnsafeSum(0, 2)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum for {0, 2}")
 	}
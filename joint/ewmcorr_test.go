@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -138,3 +139,9 @@ func TestEWMCorrString(t *testing.T) {
 	expectedString := "joint.EWMCorr_{decay:0.3}"
 	assert.Equal(t, expectedString, corr.String())
 }
+
+func TestHalfLifeEWMCorrString(t *testing.T) {
+	corr := NewHalfLifeEWMCorr(time.Second)
+	expectedString := "joint.EWMCorr_{halfLife:1s}"
+	assert.Equal(t, expectedString, corr.String())
+}
@@ -2,6 +2,7 @@ package joint
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -10,8 +11,9 @@ import (
 
 // EWMCov is a metric that tracks the sample exponentially weighted covariance.
 type EWMCov struct {
-	decay float64
-	core  *Core
+	decay    float64
+	halfLife time.Duration
+	core     *Core
 }
 
 // NewEWMCov instantiates a EWMCov struct.
@@ -19,6 +21,13 @@ func NewEWMCov(decay float64) *EWMCov {
 	return &EWMCov{decay: decay}
 }
 
+// NewHalfLifeEWMCov instantiates a EWMCov struct that decays values by the time
+// elapsed between pushes instead of by a constant decay per push; the weight
+// of each value is halved for every half-life that passes after it is pushed.
+func NewHalfLifeEWMCov(halfLife time.Duration) *EWMCov {
+	return &EWMCov{halfLife: halfLife}
+}
+
 // SetCore sets the Core.
 func (cov *EWMCov) SetCore(c *Core) {
 	cov.core = c
@@ -31,6 +40,14 @@ func (cov *EWMCov) IsSetCore() bool {
 
 // Config returns the CoreConfig needed.
 func (cov *EWMCov) Config() *CoreConfig {
+	if cov.halfLife != 0 {
+		return &CoreConfig{
+			Sums:     SumsConfig{{1, 1}},
+			Window:   stream.IntPtr(0),
+			HalfLife: &cov.halfLife,
+		}
+	}
+
 	return &CoreConfig{
 		Sums:   SumsConfig{{1, 1}},
 		Window: stream.IntPtr(0),
@@ -41,6 +58,9 @@ func (cov *EWMCov) Config() *CoreConfig {
 // String returns a string representation of the metric.
 func (cov *EWMCov) String() string {
 	name := "joint.EWMCov"
+	if cov.halfLife != 0 {
+		return fmt.Sprintf("%s_{halfLife:%v}", name, cov.halfLife)
+	}
 	return fmt.Sprintf("%s_{decay:%v}", name, cov.decay)
 }
 
@@ -65,13 +85,47 @@ func (cov *EWMCov) Push(xs ...float64) error {
 	return nil
 }
 
+// PushAt adds a new pair of values for EWMCov to consume, which was observed at the
+// provided time; this is only meaningful if the EWMCov decays values by a half-life.
+func (cov *EWMCov) PushAt(t time.Time, xs ...float64) error {
+	if !cov.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	if len(xs) != 2 {
+		return errors.Errorf(
+			"EWMCov expected 2 arguments: got %d (%v)",
+			len(xs),
+			xs,
+		)
+	}
+
+	err := cov.core.PushAt(t, xs...)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample exponentially weighted covariance.
 func (cov *EWMCov) Value() (float64, error) {
 	if !cov.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	covariance, err := cov.core.Sum(1, 1)
+	err := cov.core.rlock()
+	defer cov.core.RUnlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "error removing expired values")
+	}
+
+	return cov.value()
+}
+
+// value returns the value of the sample exponentially weighted covariance,
+// but does not lock the Core.
+func (cov *EWMCov) value() (float64, error) {
+	covariance, err := cov.core.UnsafeSum(1, 1)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
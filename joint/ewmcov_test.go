@@ -3,6 +3,7 @@ package joint
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -131,3 +132,30 @@ func TestEWMCovString(t *testing.T) {
 	expectedString := "joint.EWMCov_{decay:0.3}"
 	assert.Equal(t, expectedString, cov.String())
 }
+
+func TestHalfLifeEWMCov(t *testing.T) {
+	start := time.Unix(1000, 0)
+	cov := NewHalfLifeEWMCov(time.Minute)
+	assert.Equal(t, "joint.EWMCov_{halfLife:1m0s}", cov.String())
+
+	err := cov.PushAt(start, 1, 2)
+	testutil.ContainsError(t, err, "Core is not set")
+
+	err = Init(cov)
+	require.NoError(t, err)
+	assert.Nil(t, cov.core.decay)
+	assert.Equal(t, time.Minute, cov.core.halfLife)
+
+	err = cov.PushAt(start, 1)
+	testutil.ContainsError(t, err, "EWMCov expected 2 arguments: got 1 ([1])")
+
+	for i, x := range []float64{1, 7} {
+		err := cov.PushAt(start.Add(time.Duration(i)*time.Minute), x, -x)
+		require.NoError(t, err)
+	}
+
+	// weights of 1/3 and 2/3 around means of 5 and -5
+	value, err := cov.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, -8., value)
+}
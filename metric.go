@@ -7,16 +7,24 @@ package stream
 // for those that consume single numeric values at a time. There is no
 // Value method for this interface, allowing implementations to roll
 // custom value methods.
-type Metric interface {
-	Push(float64) error
+type Metric = TypedMetric[float64]
+
+// SimpleMetric is the interface for a Metric that returns a singular value.
+type SimpleMetric = SimpleTypedMetric[float64]
+
+// TypedMetric is the generic counterpart of Metric, for metrics that consume
+// values of type T from a stream (e.g. int64 or time.Duration), so that values
+// do not need to be converted to float64.
+type TypedMetric[T any] interface {
+	Push(T) error
 	String() string
 	Clear()
 }
 
-// SimpleMetric is the interface for a Metric that returns a singular value.
-type SimpleMetric interface {
-	Metric
-	Value() (float64, error)
+// SimpleTypedMetric is the interface for a TypedMetric that returns a singular value.
+type SimpleTypedMetric[T any] interface {
+	TypedMetric[T]
+	Value() (T, error)
 }
 
 // AggregateMetric is the interface for a metric that tracks multiple univariate single-value metrics simultaneously.
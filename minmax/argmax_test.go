@@ -0,0 +1,133 @@
+package minmax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewArgMax(t *testing.T) {
+	t.Run("pass: valid ArgMax is valid", func(t *testing.T) {
+		max, err := NewArgMax[float64, string](3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, max.window)
+		assert.Equal(t, "minmax.ArgMax_{window:3}", max.String())
+	})
+
+	t.Run("fail: negative window returns error", func(t *testing.T) {
+		_, err := NewArgMax[float64, string](-1)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+}
+
+func TestNewTimedArgMax(t *testing.T) {
+	t.Run("pass: valid ArgMax is valid", func(t *testing.T) {
+		max, err := NewTimedArgMax[float64, string](time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "minmax.ArgMax_{window:0,duration:1m0s}", max.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedArgMax[float64, string](-time.Second, nil)
+		testutil.ContainsError(t, err, "-1s is a nonpositive duration")
+	})
+}
+
+func TestNewGlobalArgMax(t *testing.T) {
+	max, err := NewArgMax[float64, string](0)
+	require.NoError(t, err)
+	assert.Equal(t, max, NewGlobalArgMax[float64, string]())
+}
+
+func TestArgMaxValue(t *testing.T) {
+	t.Run("pass: global ArgMax returns the payload of the maximum", func(t *testing.T) {
+		max := NewGlobalArgMax[float64, string]()
+		_, _, err := max.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		vals := []float64{1, 6, 4, 6, 9, 9}
+		expected := []string{"a", "b", "b", "b", "e", "e"}
+		for i, val := range vals {
+			err := max.Push(val, payloadOf(i))
+			require.NoError(t, err)
+
+			_, payload, err := max.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], payload)
+		}
+	})
+
+	t.Run("pass: windowed ArgMax returns the payload of the maximum", func(t *testing.T) {
+		max, err := NewArgMax[float64, string](3)
+		require.NoError(t, err)
+
+		vals := []float64{1, 6, 4, 0, 2, 3}
+		expectedVals := []float64{1, 6, 6, 6, 4, 3}
+		expectedPayloads := []string{"a", "b", "b", "b", "c", "f"}
+		for i, val := range vals {
+			err := max.Push(val, payloadOf(i))
+			require.NoError(t, err)
+
+			value, payload, err := max.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expectedVals[i], value)
+			assert.Equal(t, expectedPayloads[i], payload)
+		}
+	})
+
+	t.Run("pass: ties return the earliest payload in the window", func(t *testing.T) {
+		max, err := NewArgMax[int, string](3)
+		require.NoError(t, err)
+
+		vals := []int{5, 5, 3, 5, 1, 0}
+		expected := []string{"a", "a", "a", "b", "d", "d"}
+		for i, val := range vals {
+			err := max.Push(val, payloadOf(i))
+			require.NoError(t, err)
+
+			value, payload, err := max.Value()
+			require.NoError(t, err)
+			assert.Equal(t, 5, value)
+			assert.Equal(t, expected[i], payload)
+		}
+	})
+
+	t.Run("pass: timed ArgMax removes values older than duration", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start)
+		max, err := NewTimedArgMax[float64, string](10*time.Second, clock)
+		require.NoError(t, err)
+
+		vals := []float64{9, 6, 9, 1, 8, 2}
+		offsets := []int{0, 2, 4, 10, 12, 16}
+		expected := []string{"a", "a", "a", "c", "c", "e"}
+		for i, val := range vals {
+			at := start.Add(time.Duration(offsets[i]) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := max.PushAt(val, payloadOf(i), at)
+			require.NoError(t, err)
+
+			_, payload, err := max.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], payload)
+		}
+
+		clock.Advance(time.Hour)
+		_, _, err = max.Value()
+		assert.EqualError(t, err, "no values seen yet")
+	})
+}
+
+func TestArgMaxClear(t *testing.T) {
+	max := NewGlobalArgMax[float64, string]()
+	err := max.Push(1, "a")
+	require.NoError(t, err)
+
+	max.Clear()
+	_, _, err = max.Value()
+	assert.EqualError(t, err, "no values seen yet")
+}
@@ -0,0 +1,186 @@
+package minmax
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gammazero/deque"
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// ArgMin keeps track of the minimum of a stream of values of any ordered type T,
+// along with the payload of type P that was pushed with it (e.g. a trace ID).
+// If the minimum is tied, the payload of the earliest of the tied values
+// (that is still in the window) is returned.
+type ArgMin[T stream.Ordered, P any] struct {
+	window int
+	mux    sync.Mutex
+	count  int
+	// Used if window > 0 or duration > 0
+	deque *deque.Deque[argValue[T, P]]
+	// Used if duration > 0
+	duration time.Duration
+	clock    stream.Clock
+	latest   time.Time
+	// Used if window == 0 and duration == 0
+	min argValue[T, P]
+}
+
+// NewArgMin instantiates an ArgMin struct.
+func NewArgMin[T stream.Ordered, P any](window int) (*ArgMin[T, P], error) {
+	if window < 0 {
+		return nil, errors.Errorf("%d is a negative window", window)
+	}
+
+	return &ArgMin[T, P]{
+		deque:  new(deque.Deque[argValue[T, P]]),
+		window: window,
+	}, nil
+}
+
+// NewTimedArgMin instantiates an ArgMin struct that tracks values over a
+// time-based window, where values are removed once they are at least
+// the provided duration older than the current time of the provided
+// Clock, which defaults to stream.SystemClock if nil. Values pushed
+// without an explicit time are timestamped with the Clock as well.
+func NewTimedArgMin[T stream.Ordered, P any](duration time.Duration, clock stream.Clock) (*ArgMin[T, P], error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive duration", duration)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &ArgMin[T, P]{
+		deque:    new(deque.Deque[argValue[T, P]]),
+		duration: duration,
+		clock:    clock,
+	}, nil
+}
+
+// NewGlobalArgMin instantiates a global ArgMin struct.
+// This is equivalent to calling NewArgMin[T, P](0).
+func NewGlobalArgMin[T stream.Ordered, P any]() *ArgMin[T, P] {
+	return &ArgMin[T, P]{
+		deque:  new(deque.Deque[argValue[T, P]]),
+		window: 0,
+	}
+}
+
+// String returns a string representation of the metric.
+func (m *ArgMin[T, P]) String() string {
+	name := "minmax.ArgMin"
+	params := []string{fmt.Sprintf("window:%v", m.window)}
+	if m.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", m.duration))
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Push adds a number for calculating the minimum, along with its payload.
+func (m *ArgMin[T, P]) Push(x T, payload P) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var t time.Time
+	if m.duration != 0 {
+		t = m.clock.Now()
+	}
+	return m.push(x, payload, t)
+}
+
+// PushAt adds a number for calculating the minimum, along with its payload,
+// which was observed at the provided time. This is only meaningful if the
+// ArgMin tracks values over a time-based window (see NewTimedArgMin), in which
+// case values at or before t - duration are removed from the window; otherwise
+// the time is ignored. Values must be pushed in chronological order, on the
+// timeline of the Clock (see stream.Clock).
+func (m *ArgMin[T, P]) PushAt(x T, payload P, t time.Time) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.push(x, payload, t)
+}
+
+func (m *ArgMin[T, P]) push(x T, payload P, t time.Time) error {
+	if m.duration != 0 && t.Before(m.latest) {
+		return errors.Errorf("time %v is before the latest time %v", t, m.latest)
+	}
+
+	val := argValue[T, P]{x: x, payload: payload, seq: m.count, t: t}
+	m.count++
+
+	if m.duration != 0 {
+		m.expire(t)
+		m.latest = t
+	} else if m.window != 0 {
+		// values are identified by their sequence numbers rather than by
+		// their values, so that the correct payload is removed among ties
+		if m.deque.Len() > 0 && m.deque.Front().seq <= val.seq-m.window {
+			m.deque.PopFront()
+		}
+	} else {
+		// x != x only holds for NaN, which always propagates to the minimum
+		if val.seq == 0 || x < m.min.x || x != x {
+			m.min = val
+		}
+		return nil
+	}
+
+	// tied values are kept, so that the earliest of them is at the front
+	for m.deque.Len() > 0 && m.deque.Back().x > x {
+		m.deque.PopBack()
+	}
+	m.deque.PushBack(val)
+
+	return nil
+}
+
+// expire removes all values at or before t - duration from a time-based window.
+func (m *ArgMin[T, P]) expire(t time.Time) {
+	cutoff := t.Add(-m.duration)
+	for m.deque.Len() > 0 && !m.deque.Front().t.After(cutoff) {
+		m.deque.PopFront()
+	}
+}
+
+// Value returns the value of the minimum, along with its payload. If the ArgMin
+// tracks values over a time-based window, expired values are removed first.
+func (m *ArgMin[T, P]) Value() (T, P, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var zero T
+	var none P
+	if m.count == 0 {
+		return zero, none, errors.New("no values seen yet")
+	} else if m.window == 0 && m.duration == 0 {
+		return m.min.x, m.min.payload, nil
+	}
+
+	if m.duration != 0 {
+		m.expire(m.clock.Now())
+		if m.deque.Len() == 0 {
+			return zero, none, errors.New("no values seen yet")
+		}
+	}
+
+	// the latest value within a count-based window is always kept,
+	// so this is never empty
+	front := m.deque.Front()
+	return front.x, front.payload, nil
+}
+
+// Clear resets the metric.
+func (m *ArgMin[T, P]) Clear() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.count = 0
+	m.min = argValue[T, P]{}
+	m.deque.Clear()
+	m.latest = time.Time{}
+}
@@ -0,0 +1,181 @@
+package minmax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewArgMin(t *testing.T) {
+	t.Run("pass: valid ArgMin is valid", func(t *testing.T) {
+		min, err := NewArgMin[float64, string](3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, min.window)
+		assert.Equal(t, 0, min.deque.Len())
+		assert.Equal(t, "minmax.ArgMin_{window:3}", min.String())
+	})
+
+	t.Run("fail: negative window returns error", func(t *testing.T) {
+		_, err := NewArgMin[float64, string](-1)
+		testutil.ContainsError(t, err, "-1 is a negative window")
+	})
+}
+
+func TestNewTimedArgMin(t *testing.T) {
+	t.Run("pass: valid ArgMin is valid", func(t *testing.T) {
+		min, err := NewTimedArgMin[float64, string](time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "minmax.ArgMin_{window:0,duration:1m0s}", min.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedArgMin[float64, string](0, nil)
+		testutil.ContainsError(t, err, "0s is a nonpositive duration")
+	})
+}
+
+func TestNewGlobalArgMin(t *testing.T) {
+	min, err := NewArgMin[float64, string](0)
+	require.NoError(t, err)
+	assert.Equal(t, min, NewGlobalArgMin[float64, string]())
+}
+
+func TestArgMinValue(t *testing.T) {
+	t.Run("pass: global ArgMin returns the payload of the minimum", func(t *testing.T) {
+		min := NewGlobalArgMin[float64, string]()
+		_, _, err := min.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		vals := []float64{8, 3, 5, 3, 1, 1}
+		payloads := []string{"a", "b", "c", "d", "e", "f"}
+		expected := []string{"a", "b", "b", "b", "e", "e"}
+		for i, val := range vals {
+			err := min.Push(val, payloads[i])
+			require.NoError(t, err)
+
+			_, payload, err := min.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], payload)
+		}
+
+		value, _, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 1., value)
+	})
+
+	t.Run("pass: windowed ArgMin returns the payload of the minimum", func(t *testing.T) {
+		min, err := NewArgMin[float64, string](3)
+		require.NoError(t, err)
+
+		vals := []float64{8, 3, 5, 9, 7, 6}
+		payloads := []string{"a", "b", "c", "d", "e", "f"}
+		expectedVals := []float64{8, 3, 3, 3, 5, 6}
+		expectedPayloads := []string{"a", "b", "b", "b", "c", "f"}
+		for i, val := range vals {
+			err := min.Push(val, payloads[i])
+			require.NoError(t, err)
+
+			value, payload, err := min.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expectedVals[i], value)
+			assert.Equal(t, expectedPayloads[i], payload)
+		}
+	})
+
+	t.Run("pass: ties return the earliest payload in the window", func(t *testing.T) {
+		min, err := NewArgMin[int, string](3)
+		require.NoError(t, err)
+
+		// the first tied value is evicted, after which the next tied value is the minimum
+		vals := []int{2, 2, 3, 2, 4, 5}
+		payloads := []string{"a", "b", "c", "d", "e", "f"}
+		expected := []string{"a", "a", "a", "b", "d", "d"}
+		for i, val := range vals {
+			err := min.Push(val, payloads[i])
+			require.NoError(t, err)
+
+			value, payload, err := min.Value()
+			require.NoError(t, err)
+			assert.Equal(t, 2, value)
+			assert.Equal(t, expected[i], payload)
+		}
+	})
+
+	t.Run("pass: timed ArgMin removes values older than duration", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start)
+		min, err := NewTimedArgMin[float64, time.Time](10*time.Second, clock)
+		require.NoError(t, err)
+
+		vals := []float64{1, 6, 1, 9, 2, 8}
+		offsets := []int{0, 2, 4, 10, 12, 16}
+		expected := []int{0, 0, 0, 4, 4, 12}
+		for i, val := range vals {
+			at := start.Add(time.Duration(offsets[i]) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := min.PushAt(val, at, at)
+			require.NoError(t, err)
+
+			_, payload, err := min.Value()
+			require.NoError(t, err)
+			assert.Equal(t, start.Add(time.Duration(expected[i])*time.Second), payload)
+		}
+
+		clock.Advance(time.Hour)
+		_, _, err = min.Value()
+		assert.EqualError(t, err, "no values seen yet")
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(time.Unix(1000, 0))
+		min, err := NewTimedArgMin[float64, string](time.Minute, clock)
+		require.NoError(t, err)
+
+		for i, val := range []float64{1, 6, 4} {
+			err := min.Push(val, payloadOf(i))
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		value, payload, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4., value)
+		assert.Equal(t, payloadOf(2), payload)
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		min, err := NewTimedArgMin[float64, string](time.Minute, nil)
+		require.NoError(t, err)
+
+		err = min.PushAt(1, "a", start)
+		require.NoError(t, err)
+
+		err = min.PushAt(2, "b", start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
+func TestArgMinClear(t *testing.T) {
+	min, err := NewArgMin[float64, string](3)
+	require.NoError(t, err)
+
+	for i, val := range []float64{1, 2, 3, 4} {
+		err := min.Push(val, payloadOf(i))
+		require.NoError(t, err)
+	}
+
+	min.Clear()
+	assert.Equal(t, 0, min.count)
+	assert.Equal(t, 0, min.deque.Len())
+	_, _, err = min.Value()
+	assert.EqualError(t, err, "no values seen yet")
+}
+
+func payloadOf(i int) string {
+	return string(rune('a' + i))
+}
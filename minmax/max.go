@@ -2,64 +2,154 @@ package minmax
 
 import (
 	"fmt"
-	"math"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
 )
 
 // Max keeps track of the maximum of a stream.
-type Max struct {
+type Max = TypedMax[float64]
+
+// TypedMax keeps track of the maximum of a stream of values of any ordered type T,
+// e.g. int64 or time.Duration, which avoids converting such values to float64.
+type TypedMax[T stream.Ordered] struct {
 	window int
 	mux    sync.Mutex
 	// Used if window > 0
 	queue *queue.RingBuffer
-	deque *deque.Deque[float64]
+	deque *deque.Deque[T]
+	// Used if duration > 0
+	duration time.Duration
+	clock    stream.Clock
+	timed    *deque.Deque[timedValue[T]]
+	latest   time.Time
 	// Used if window == 0
-	max   float64
+	max   T
 	count int
 }
 
 // NewMax instantiates a Max struct.
 func NewMax(window int) (*Max, error) {
+	return NewTypedMax[float64](window)
+}
+
+// NewTypedMax instantiates a TypedMax struct.
+func NewTypedMax[T stream.Ordered](window int) (*TypedMax[T], error) {
 	if window < 0 {
 		return nil, errors.Errorf("%d is a negative window", window)
 	}
 
-	return &Max{
+	return &TypedMax[T]{
 		queue:  queue.NewRingBuffer(uint64(window)),
-		deque:  new(deque.Deque[float64]),
-		max:    math.Inf(-1),
+		deque:  new(deque.Deque[T]),
+		timed:  new(deque.Deque[timedValue[T]]),
+		max:    initial[T](-1),
 		window: window,
 	}, nil
 }
 
+// NewTimedMax instantiates a Max struct that tracks values over a
+// time-based window, where values are removed once they are at least
+// the provided duration older than the current time of the Clock. Values pushed
+// without an explicit time are timestamped with the provided Clock,
+// which defaults to stream.SystemClock if nil.
+func NewTimedMax(duration time.Duration, clock stream.Clock) (*Max, error) {
+	return NewTimedTypedMax[float64](duration, clock)
+}
+
+// NewTimedTypedMax instantiates a TypedMax struct that tracks values over a
+// time-based window; see NewTimedMax for details.
+func NewTimedTypedMax[T stream.Ordered](duration time.Duration, clock stream.Clock) (*TypedMax[T], error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive duration", duration)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &TypedMax[T]{
+		queue:    queue.NewRingBuffer(uint64(0)),
+		deque:    new(deque.Deque[T]),
+		duration: duration,
+		clock:    clock,
+		timed:    new(deque.Deque[timedValue[T]]),
+		max:      initial[T](-1),
+	}, nil
+}
+
 // NewGlobalMax instantiates a global Max struct.
 // This is equivalent to calling NewMax(0).
 func NewGlobalMax() *Max {
-	return &Max{
+	return NewGlobalTypedMax[float64]()
+}
+
+// NewGlobalTypedMax instantiates a global TypedMax struct.
+// This is equivalent to calling NewTypedMax[T](0).
+func NewGlobalTypedMax[T stream.Ordered]() *TypedMax[T] {
+	return &TypedMax[T]{
 		queue:  queue.NewRingBuffer(uint64(0)),
-		deque:  new(deque.Deque[float64]),
-		max:    math.Inf(-1),
+		deque:  new(deque.Deque[T]),
+		timed:  new(deque.Deque[timedValue[T]]),
+		max:    initial[T](-1),
 		window: 0,
 	}
 }
 
 // String returns a string representation of the metric.
-func (m *Max) String() string {
+func (m *TypedMax[T]) String() string {
 	name := "minmax.Max"
-	window := fmt.Sprintf("window:%v", m.window)
-	return fmt.Sprintf("%s_{%s}", name, window)
+	params := []string{fmt.Sprintf("window:%v", m.window)}
+	if m.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", m.duration))
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
 }
 
 // Push adds a number for calculating the maximum.
-func (m *Max) Push(x float64) error {
+func (m *TypedMax[T]) Push(x T) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var t time.Time
+	if m.duration != 0 {
+		t = m.clock.Now()
+	}
+	return m.push(x, t)
+}
+
+// PushAt adds a number for calculating the maximum, which was observed at
+// the provided time. This is only meaningful if the Max tracks values over
+// a time-based window (see NewTimedMax), in which case values at or before
+// t - duration are removed from the window; otherwise the time is ignored.
+// Values must be pushed in chronological order, on the timeline of the Clock
+// (see stream.Clock).
+func (m *TypedMax[T]) PushAt(x T, t time.Time) error {
 	m.mux.Lock()
 	defer m.mux.Unlock()
+	return m.push(x, t)
+}
+
+func (m *TypedMax[T]) push(x T, t time.Time) error {
+	if m.duration != 0 {
+		if t.Before(m.latest) {
+			return errors.Errorf("time %v is before the latest time %v", t, m.latest)
+		}
+
+		m.expire(t)
 
-	if m.window != 0 {
+		for m.timed.Len() > 0 && m.timed.Back().x < x {
+			m.timed.PopBack()
+		}
+		m.timed.PushBack(timedValue[T]{x: x, t: t})
+		m.latest = t
+	} else if m.window != 0 {
 		if m.queue.Len() == uint64(m.window) {
 			val, err := m.queue.Get()
 			if err != nil {
@@ -68,14 +158,14 @@ func (m *Max) Push(x float64) error {
 
 			m.count--
 
-			if m.deque.Front() == *val.(*float64) {
+			if m.deque.Front() == *val.(*T) {
 				m.deque.PopFront()
 			}
 		}
 
 		err := m.queue.Put(&x)
 		if err != nil {
-			return errors.Wrapf(err, "error pushing %f to queue", x)
+			return errors.Wrapf(err, "error pushing %s to queue", stream.FormatValue(x))
 		}
 
 		m.count++
@@ -87,19 +177,40 @@ func (m *Max) Push(x float64) error {
 
 	} else {
 		m.count++
-		m.max = math.Max(m.max, x)
+		// x != x only holds for NaN, which always propagates to the maximum
+		if m.count == 1 || x > m.max || x != x {
+			m.max = x
+		}
 	}
 
 	return nil
 }
 
-// Value returns the value of the maximum.
-func (m *Max) Value() (float64, error) {
+// expire removes all values at or before t - duration from a time-based window.
+func (m *TypedMax[T]) expire(t time.Time) {
+	cutoff := t.Add(-m.duration)
+	for m.timed.Len() > 0 && !m.timed.Front().t.After(cutoff) {
+		m.timed.PopFront()
+	}
+}
+
+// Value returns the value of the maximum. If the Max tracks values over
+// a time-based window, expired values are removed first.
+func (m *TypedMax[T]) Value() (T, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
+	var zero T
+	if m.duration != 0 {
+		m.expire(m.clock.Now())
+		if m.timed.Len() == 0 {
+			return zero, errors.New("no values seen yet")
+		}
+		return m.timed.Front().x, nil
+	}
+
 	if m.count == 0 {
-		return 0, errors.New("no values seen yet")
+		return zero, errors.New("no values seen yet")
 	} else if m.window == 0 {
 		return m.max, nil
 	}
@@ -108,12 +219,14 @@ func (m *Max) Value() (float64, error) {
 }
 
 // Clear resets the metric.
-func (m *Max) Clear() {
+func (m *TypedMax[T]) Clear() {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 	m.count = 0
-	m.max = math.Inf(-1)
+	m.max = initial[T](-1)
 	m.queue.Dispose()
 	m.queue = queue.NewRingBuffer(uint64(m.window))
-	m.deque = new(deque.Deque[float64])
+	m.deque = new(deque.Deque[T])
+	m.timed.Clear()
+	m.latest = time.Time{}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,6 +31,22 @@ func TestNewMax(t *testing.T) {
 	})
 }
 
+func TestNewTimedMax(t *testing.T) {
+	t.Run("pass: valid Max is valid", func(t *testing.T) {
+		max, err := NewTimedMax(time.Minute, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Minute, max.duration)
+		assert.Equal(t, 0, max.timed.Len())
+		assert.Equal(t, "minmax.Max_{window:0,duration:1m0s}", max.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedMax(-time.Second, nil)
+		testutil.ContainsError(t, err, "-1s is a nonpositive duration")
+	})
+}
+
 func TestNewGlobalMax(t *testing.T) {
 	max, err := NewMax(0)
 	require.NoError(t, err)
@@ -174,6 +191,81 @@ func (s *MaxValueSuite) TestValueFailIfNoValuesSeen() {
 	assert.EqualError(s.T(), err, "no values seen yet")
 }
 
+func TestMaxPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		max, err := NewTimedMax(10*time.Second, clock)
+		require.NoError(t, err)
+
+		_, err = max.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		vals := []float64{9, 4, 6, 1, 8, 2}
+		expected := []float64{9, 9, 9, 6, 8, 8}
+		offsets := []int{0, 2, 4, 10, 12, 14}
+		for i, val := range vals {
+			at := start.Add(time.Duration(offsets[i]) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := max.PushAt(val, at)
+			require.NoError(t, err)
+
+			value, err := max.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		max, err := NewTimedMax(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, val := range []float64{9, 4, 6} {
+			err := max.Push(val)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		value, err := max.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 6., value)
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		max, err := NewTimedMax(time.Minute, clock)
+		require.NoError(t, err)
+
+		err = max.Push(9)
+		require.NoError(t, err)
+		clock.Advance(30 * time.Second)
+		err = max.Push(4)
+		require.NoError(t, err)
+
+		clock.Advance(45 * time.Second)
+		value, err := max.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4., value)
+
+		clock.Advance(time.Hour)
+		_, err = max.Value()
+		assert.EqualError(t, err, "no values seen yet")
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		max, err := NewTimedMax(time.Minute, nil)
+		require.NoError(t, err)
+
+		err = max.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = max.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
 func TestMaxClear(t *testing.T) {
 	max, err := NewMax(3)
 	require.NoError(t, err)
@@ -189,3 +281,56 @@ func TestMaxClear(t *testing.T) {
 	assert.Equal(t, uint64(0), max.queue.Len())
 	assert.Equal(t, 0, max.deque.Len())
 }
+
+func TestTypedMax(t *testing.T) {
+	// values above 2^53 are not exactly representable as float64
+	base := int64(1) << 60
+
+	t.Run("pass: global TypedMax tracks int64 values exactly", func(t *testing.T) {
+		max := NewGlobalTypedMax[int64]()
+
+		_, err := max.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		for _, offset := range []int64{-5, -3, -7} {
+			err = max.Push(base + offset)
+			require.NoError(t, err)
+		}
+
+		value, err := max.Value()
+		require.NoError(t, err)
+		assert.Equal(t, base-3, value)
+	})
+
+	t.Run("pass: windowed TypedMax tracks int64 values exactly", func(t *testing.T) {
+		max, err := NewTypedMax[int64](2)
+		require.NoError(t, err)
+
+		for _, offset := range []int64{-1, -5, -3} {
+			err = max.Push(base + offset)
+			require.NoError(t, err)
+		}
+
+		value, err := max.Value()
+		require.NoError(t, err)
+		assert.Equal(t, base-3, value)
+	})
+
+	t.Run("pass: timed TypedMax tracks time.Duration values", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start.Add(10 * time.Second))
+		max, err := NewTimedTypedMax[time.Duration](10*time.Second, clock)
+		require.NoError(t, err)
+
+		vals := []time.Duration{9 * time.Second, 4 * time.Second, 6 * time.Second}
+		offsets := []int{0, 2, 10}
+		for i, val := range vals {
+			err = max.PushAt(val, start.Add(time.Duration(offsets[i])*time.Second))
+			require.NoError(t, err)
+		}
+
+		value, err := max.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 6*time.Second, value)
+	})
+}
@@ -2,64 +2,154 @@ package minmax
 
 import (
 	"fmt"
-	"math"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
 )
 
 // Min keeps track of the minimum of a stream.
-type Min struct {
+type Min = TypedMin[float64]
+
+// TypedMin keeps track of the minimum of a stream of values of any ordered type T,
+// e.g. int64 or time.Duration, which avoids converting such values to float64.
+type TypedMin[T stream.Ordered] struct {
 	window int
 	mux    sync.Mutex
 	count  int
 	// Used if window > 0
 	queue *queue.RingBuffer
-	deque *deque.Deque[float64]
+	deque *deque.Deque[T]
+	// Used if duration > 0
+	duration time.Duration
+	clock    stream.Clock
+	timed    *deque.Deque[timedValue[T]]
+	latest   time.Time
 	// Used if window == 0
-	min float64
+	min T
 }
 
 // NewMin instantiates a Min struct.
 func NewMin(window int) (*Min, error) {
+	return NewTypedMin[float64](window)
+}
+
+// NewTypedMin instantiates a TypedMin struct.
+func NewTypedMin[T stream.Ordered](window int) (*TypedMin[T], error) {
 	if window < 0 {
 		return nil, errors.Errorf("%d is a negative window", window)
 	}
 
-	return &Min{
+	return &TypedMin[T]{
 		queue:  queue.NewRingBuffer(uint64(window)),
-		deque:  new(deque.Deque[float64]),
-		min:    math.Inf(1),
+		deque:  new(deque.Deque[T]),
+		timed:  new(deque.Deque[timedValue[T]]),
+		min:    initial[T](1),
 		window: window,
 	}, nil
 }
 
+// NewTimedMin instantiates a Min struct that tracks values over a
+// time-based window, where values are removed once they are at least
+// the provided duration older than the current time of the Clock. Values pushed
+// without an explicit time are timestamped with the provided Clock,
+// which defaults to stream.SystemClock if nil.
+func NewTimedMin(duration time.Duration, clock stream.Clock) (*Min, error) {
+	return NewTimedTypedMin[float64](duration, clock)
+}
+
+// NewTimedTypedMin instantiates a TypedMin struct that tracks values over a
+// time-based window; see NewTimedMin for details.
+func NewTimedTypedMin[T stream.Ordered](duration time.Duration, clock stream.Clock) (*TypedMin[T], error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive duration", duration)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &TypedMin[T]{
+		queue:    queue.NewRingBuffer(uint64(0)),
+		deque:    new(deque.Deque[T]),
+		duration: duration,
+		clock:    clock,
+		timed:    new(deque.Deque[timedValue[T]]),
+		min:      initial[T](1),
+	}, nil
+}
+
 // NewGlobalMin instantiates a global Min struct.
 // This is equivalent to calling NewMin(0).
 func NewGlobalMin() *Min {
-	return &Min{
+	return NewGlobalTypedMin[float64]()
+}
+
+// NewGlobalTypedMin instantiates a global TypedMin struct.
+// This is equivalent to calling NewTypedMin[T](0).
+func NewGlobalTypedMin[T stream.Ordered]() *TypedMin[T] {
+	return &TypedMin[T]{
 		queue:  queue.NewRingBuffer(uint64(0)),
-		deque:  new(deque.Deque[float64]),
-		min:    math.Inf(1),
+		deque:  new(deque.Deque[T]),
+		timed:  new(deque.Deque[timedValue[T]]),
+		min:    initial[T](1),
 		window: 0,
 	}
 }
 
 // String returns a string representation of the metric.
-func (m *Min) String() string {
+func (m *TypedMin[T]) String() string {
 	name := "minmax.Min"
-	window := fmt.Sprintf("window:%v", m.window)
-	return fmt.Sprintf("%s_{%s}", name, window)
+	params := []string{fmt.Sprintf("window:%v", m.window)}
+	if m.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", m.duration))
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
 }
 
 // Push adds a number for calculating the minimum.
-func (m *Min) Push(x float64) error {
+func (m *TypedMin[T]) Push(x T) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var t time.Time
+	if m.duration != 0 {
+		t = m.clock.Now()
+	}
+	return m.push(x, t)
+}
+
+// PushAt adds a number for calculating the minimum, which was observed at
+// the provided time. This is only meaningful if the Min tracks values over
+// a time-based window (see NewTimedMin), in which case values at or before
+// t - duration are removed from the window; otherwise the time is ignored.
+// Values must be pushed in chronological order, on the timeline of the Clock
+// (see stream.Clock).
+func (m *TypedMin[T]) PushAt(x T, t time.Time) error {
 	m.mux.Lock()
 	defer m.mux.Unlock()
+	return m.push(x, t)
+}
+
+func (m *TypedMin[T]) push(x T, t time.Time) error {
+	if m.duration != 0 {
+		if t.Before(m.latest) {
+			return errors.Errorf("time %v is before the latest time %v", t, m.latest)
+		}
+
+		m.expire(t)
 
-	if m.window != 0 {
+		for m.timed.Len() > 0 && m.timed.Back().x > x {
+			m.timed.PopBack()
+		}
+		m.timed.PushBack(timedValue[T]{x: x, t: t})
+		m.latest = t
+	} else if m.window != 0 {
 		if m.queue.Len() == uint64(m.window) {
 			val, err := m.queue.Get()
 			if err != nil {
@@ -68,14 +158,14 @@ func (m *Min) Push(x float64) error {
 
 			m.count--
 
-			if m.deque.Front() == *val.(*float64) {
+			if m.deque.Front() == *val.(*T) {
 				m.deque.PopFront()
 			}
 		}
 
 		err := m.queue.Put(&x)
 		if err != nil {
-			return errors.Wrapf(err, "error pushing %f to queue", x)
+			return errors.Wrapf(err, "error pushing %s to queue", stream.FormatValue(x))
 		}
 
 		m.count++
@@ -87,19 +177,40 @@ func (m *Min) Push(x float64) error {
 
 	} else {
 		m.count++
-		m.min = math.Min(m.min, x)
+		// x != x only holds for NaN, which always propagates to the minimum
+		if m.count == 1 || x < m.min || x != x {
+			m.min = x
+		}
 	}
 
 	return nil
 }
 
-// Value returns the value of the minimum.
-func (m *Min) Value() (float64, error) {
+// expire removes all values at or before t - duration from a time-based window.
+func (m *TypedMin[T]) expire(t time.Time) {
+	cutoff := t.Add(-m.duration)
+	for m.timed.Len() > 0 && !m.timed.Front().t.After(cutoff) {
+		m.timed.PopFront()
+	}
+}
+
+// Value returns the value of the minimum. If the Min tracks values over
+// a time-based window, expired values are removed first.
+func (m *TypedMin[T]) Value() (T, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
+	var zero T
+	if m.duration != 0 {
+		m.expire(m.clock.Now())
+		if m.timed.Len() == 0 {
+			return zero, errors.New("no values seen yet")
+		}
+		return m.timed.Front().x, nil
+	}
+
 	if m.count == 0 {
-		return 0, errors.New("no values seen yet")
+		return zero, errors.New("no values seen yet")
 	} else if m.window == 0 {
 		return m.min, nil
 	}
@@ -108,12 +219,14 @@ func (m *Min) Value() (float64, error) {
 }
 
 // Clear resets the metric.
-func (m *Min) Clear() {
+func (m *TypedMin[T]) Clear() {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 	m.count = 0
-	m.min = math.Inf(1)
+	m.min = initial[T](1)
 	m.queue.Dispose()
 	m.queue = queue.NewRingBuffer(uint64(m.window))
-	m.deque = new(deque.Deque[float64])
+	m.deque = new(deque.Deque[T])
+	m.timed.Clear()
+	m.latest = time.Time{}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,22 @@ func TestNewMin(t *testing.T) {
 	})
 }
 
+func TestNewTimedMin(t *testing.T) {
+	t.Run("pass: valid Min is valid", func(t *testing.T) {
+		min, err := NewTimedMin(time.Minute, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Minute, min.duration)
+		assert.Equal(t, 0, min.timed.Len())
+		assert.Equal(t, "minmax.Min_{window:0,duration:1m0s}", min.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedMin(-time.Second, nil)
+		testutil.ContainsError(t, err, "-1s is a nonpositive duration")
+	})
+}
+
 func TestNewGlobalMin(t *testing.T) {
 	min, err := NewMin(0)
 	require.NoError(t, err)
@@ -173,6 +190,81 @@ func (s *MinValueSuite) TestValueFailIfNoValuesSeen() {
 	assert.EqualError(s.T(), err, "no values seen yet")
 }
 
+func TestMinPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		min, err := NewTimedMin(10*time.Second, clock)
+		require.NoError(t, err)
+
+		_, err = min.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		vals := []float64{1, 6, 4, 9, 2, 8}
+		expected := []float64{1, 1, 1, 4, 2, 2}
+		offsets := []int{0, 2, 4, 10, 12, 14}
+		for i, val := range vals {
+			at := start.Add(time.Duration(offsets[i]) * time.Second)
+			clock.Advance(at.Sub(clock.Now()))
+			err := min.PushAt(val, at)
+			require.NoError(t, err)
+
+			value, err := min.Value()
+			require.NoError(t, err)
+			assert.Equal(t, expected[i], value)
+		}
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		min, err := NewTimedMin(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, val := range []float64{1, 6, 4} {
+			err := min.Push(val)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		value, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4., value)
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		min, err := NewTimedMin(time.Minute, clock)
+		require.NoError(t, err)
+
+		err = min.Push(9)
+		require.NoError(t, err)
+		clock.Advance(30 * time.Second)
+		err = min.Push(4)
+		require.NoError(t, err)
+
+		clock.Advance(45 * time.Second)
+		value, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4., value)
+
+		clock.Advance(time.Hour)
+		_, err = min.Value()
+		assert.EqualError(t, err, "no values seen yet")
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		min, err := NewTimedMin(time.Minute, nil)
+		require.NoError(t, err)
+
+		err = min.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = min.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
 func TestMinClear(t *testing.T) {
 	min, err := NewMin(3)
 	require.NoError(t, err)
@@ -188,3 +280,56 @@ func TestMinClear(t *testing.T) {
 	assert.Equal(t, uint64(0), min.queue.Len())
 	assert.Equal(t, 0, min.deque.Len())
 }
+
+func TestTypedMin(t *testing.T) {
+	// values above 2^53 are not exactly representable as float64
+	base := int64(1) << 60
+
+	t.Run("pass: global TypedMin tracks int64 values exactly", func(t *testing.T) {
+		min := NewGlobalTypedMin[int64]()
+
+		_, err := min.Value()
+		assert.EqualError(t, err, "no values seen yet")
+
+		for _, offset := range []int64{5, 3, 7} {
+			err = min.Push(base + offset)
+			require.NoError(t, err)
+		}
+
+		value, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, base+3, value)
+	})
+
+	t.Run("pass: windowed TypedMin tracks int64 values exactly", func(t *testing.T) {
+		min, err := NewTypedMin[int64](2)
+		require.NoError(t, err)
+
+		for _, offset := range []int64{1, 5, 3} {
+			err = min.Push(base + offset)
+			require.NoError(t, err)
+		}
+
+		value, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, base+3, value)
+	})
+
+	t.Run("pass: timed TypedMin tracks time.Duration values", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start.Add(10 * time.Second))
+		min, err := NewTimedTypedMin[time.Duration](10*time.Second, clock)
+		require.NoError(t, err)
+
+		vals := []time.Duration{time.Second, 6 * time.Second, 4 * time.Second}
+		offsets := []int{0, 2, 10}
+		for i, val := range vals {
+			err = min.PushAt(val, start.Add(time.Duration(offsets[i])*time.Second))
+			require.NoError(t, err)
+		}
+
+		value, err := min.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 4*time.Second, value)
+	})
+}
@@ -0,0 +1,37 @@
+package minmax
+
+import (
+	"math"
+	"time"
+
+	"github.com/alexander-yu/stream"
+)
+
+// timedValue is a value in a time-based window, along with the time it was pushed at.
+type timedValue[T stream.Ordered] struct {
+	x T
+	t time.Time
+}
+
+// initial returns the initial value of a global minimum (or maximum), which is
+// +Inf (or -Inf) for float64 and float32 values, and the zero value otherwise.
+func initial[T stream.Ordered](sign int) T {
+	var zero T
+	switch any(zero).(type) {
+	case float64:
+		return any(math.Inf(sign)).(T)
+	case float32:
+		return any(float32(math.Inf(sign))).(T)
+	default:
+		return zero
+	}
+}
+
+// argValue is a value tracked by an ArgMin (or ArgMax), along with its payload,
+// its sequence number in the stream, and the time it was pushed at.
+type argValue[T stream.Ordered, P any] struct {
+	x       T
+	payload P
+	seq     int
+	t       time.Time
+}
@@ -0,0 +1,217 @@
+package moment
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// Aggregate tracks multiple moment-based metrics of a stream with a single Core,
+// whose config is the merge of the configs of all of the metrics; each value is
+// pushed to the Core once, rather than once per metric. For example, Mean, Std,
+// Skewness and Kurtosis can all share the same update to the central sums.
+type Aggregate struct {
+	metrics []Metric
+	core    *Core
+	mux     sync.Mutex
+}
+
+// NewAggregate instantiates an Aggregate struct, and sets up each metric with the
+// shared Core; the metrics do not need to be passed into Init() beforehand. The
+// configs of the metrics must be compatible, e.g. they must all have the same window,
+// and they must all have the same Decay and HalfLife (if any).
+func NewAggregate(metrics ...Metric) (*Aggregate, error) {
+	if len(metrics) == 0 {
+		return nil, errors.New("no metrics provided")
+	}
+
+	configs := make([]*CoreConfig, len(metrics))
+	for i, metric := range metrics {
+		configs[i] = metric.Config()
+	}
+
+	err := checkDecays(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := MergeConfigs(configs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error merging configs")
+	}
+
+	core, err := NewCore(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating Core")
+	}
+
+	for _, metric := range metrics {
+		metric.SetCore(core)
+	}
+
+	return &Aggregate{metrics: metrics, core: core}, nil
+}
+
+// checkDecays returns an error if the configs do not all have the same Decay and
+// HalfLife. Unlike MergeConfigs, which treats an unset Decay or HalfLife as
+// compatible with any other, this treats it as not decaying values at all, since
+// e.g. a Mean would otherwise end up sharing the decayed Core of an EWMA.
+func checkDecays(configs []*CoreConfig) error {
+	for _, config := range configs[1:] {
+		if !equalPtr(config.Decay, configs[0].Decay) {
+			return errors.New("metrics have differing decays")
+		} else if !equalPtr(config.HalfLife, configs[0].HalfLife) {
+			return errors.New("metrics have differing half-lives")
+		}
+	}
+	return nil
+}
+
+// equalPtr returns if two pointers are both nil, or both point to equal values.
+func equalPtr[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// String returns a string representation of the metric.
+func (a *Aggregate) String() string {
+	name := "moment.Aggregate"
+	metrics := make([]string, len(a.metrics))
+	for i, metric := range a.metrics {
+		metrics[i] = metric.String()
+	}
+	return fmt.Sprintf("%s_{metrics:[%s]}", name, strings.Join(metrics, ","))
+}
+
+// Core returns the Core shared by the metrics.
+func (a *Aggregate) Core() *Core {
+	return a.core
+}
+
+// Push adds a new value for the metrics to consume.
+func (a *Aggregate) Push(x float64) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	err := a.core.Push(x)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+
+	return nil
+}
+
+// PushWeighted adds a new value with a given (positive) weight for the metrics to consume.
+func (a *Aggregate) PushWeighted(x float64, w float64) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	err := a.core.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+
+	return nil
+}
+
+// PushAt adds a new value for the metrics to consume, which was observed at the provided time.
+func (a *Aggregate) PushAt(x float64, t time.Time) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	err := a.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+
+	return nil
+}
+
+// Values returns the values of the metrics; in particular, it returns
+// a map of strings to values, where the strings are the string
+// representations of each metric (i.e. the result of calling String()).
+func (a *Aggregate) Values() (map[string]float64, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.values()
+}
+
+// Snapshot returns a consistent snapshot of the values of the metrics, along with
+// the number of values in the Core; since all of the values are read under a single
+// lock of the Core, they reflect the same set of values, even if values are pushed
+// to the Core concurrently or expire from a time-based window. Only the metrics of
+// this package can be read under the lock of the Core, so an error is returned if
+// any other Metric is included in the Aggregate.
+func (a *Aggregate) Snapshot() (*stream.Snapshot, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.core.rlock()
+	defer a.core.RUnlock()
+
+	values := map[string]float64{}
+	var result *multierror.Error
+	for _, metric := range a.metrics {
+		m, ok := metric.(unlockedMetric)
+		if !ok {
+			result = multierror.Append(result, errors.Errorf("metric %s cannot be read in a snapshot", metric.String()))
+			continue
+		}
+
+		val, err := m.value()
+		if err != nil {
+			result = multierror.Append(result, err)
+		} else {
+			values[metric.String()] = val
+		}
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving values from metrics")
+	}
+
+	return &stream.Snapshot{Values: values, Count: a.core.UnsafeCount()}, nil
+}
+
+// unlockedMetric is a Metric whose value can be read while its Core is already locked.
+type unlockedMetric interface {
+	Metric
+	value() (float64, error)
+}
+
+// values returns the values of the metrics, without locking.
+func (a *Aggregate) values() (map[string]float64, error) {
+	values := map[string]float64{}
+	var result *multierror.Error
+	for _, metric := range a.metrics {
+		val, err := metric.Value()
+		if err != nil {
+			result = multierror.Append(result, err)
+		} else {
+			values[metric.String()] = val
+		}
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving values from metrics")
+	}
+
+	return values, nil
+}
+
+// Clear resets all metrics.
+func (a *Aggregate) Clear() {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.core.Clear()
+}
@@ -0,0 +1,207 @@
+package moment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewAggregate(t *testing.T) {
+	t.Run("pass: metrics share a single Core", func(t *testing.T) {
+		mean := NewMean(3)
+		std := NewStd(3)
+		kurtosis := NewKurtosis(3)
+
+		aggregate, err := NewAggregate(mean, std, kurtosis)
+		require.NoError(t, err)
+
+		assert.True(t, mean.core == aggregate.Core())
+		assert.True(t, std.variance.core == aggregate.Core())
+		assert.True(t, kurtosis.core == aggregate.Core())
+		assert.Equal(t, 3, aggregate.Core().window)
+		assert.Len(t, aggregate.Core().sums, 5)
+		assert.Equal(t, "moment.Aggregate_{metrics:[moment.Mean_{window:3},moment.Std_{window:3},moment.Kurtosis_{window:3}]}", aggregate.String())
+	})
+
+	t.Run("fail: no metrics is invalid", func(t *testing.T) {
+		_, err := NewAggregate()
+		testutil.ContainsError(t, err, "no metrics provided")
+	})
+
+	t.Run("fail: incompatible configs are invalid", func(t *testing.T) {
+		_, err := NewAggregate(NewMean(3), NewStd(4))
+		testutil.ContainsError(t, err, "configs have differing windows")
+	})
+
+	t.Run("pass: metrics with the same decay share a single Core", func(t *testing.T) {
+		ewma := NewEWMA(0.5)
+		std := NewEWMStd(0.5)
+
+		aggregate, err := NewAggregate(ewma, std)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 10} {
+			err = aggregate.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.True(t, ewma.core == std.variance.core)
+		val, err := ewma.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 6.125, val)
+	})
+
+	t.Run("fail: undecayed and decayed metrics are invalid", func(t *testing.T) {
+		// a Mean must not share the decayed Core of an EWMA, which would report
+		// the decayed mean 6.125 instead of 4 for the values 1, 2, 3 and 10
+		_, err := NewAggregate(NewGlobalMean(), NewEWMA(0.5))
+		testutil.ContainsError(t, err, "metrics have differing decays")
+
+		_, err = NewAggregate(NewEWMA(0.5), NewGlobalMean())
+		testutil.ContainsError(t, err, "metrics have differing decays")
+
+		_, err = NewAggregate(NewGlobalMean(), NewHalfLifeEWMA(time.Minute))
+		testutil.ContainsError(t, err, "metrics have differing half-lives")
+
+		_, err = NewAggregate(NewEWMA(0.5), NewEWMStd(0.3))
+		testutil.ContainsError(t, err, "metrics have differing decays")
+	})
+
+	t.Run("fail: invalid config is invalid", func(t *testing.T) {
+		_, err := NewAggregate(NewMean(-1))
+		testutil.ContainsError(t, err, "error creating Core")
+	})
+}
+
+func TestAggregateValues(t *testing.T) {
+	t.Run("pass: values match separately initialized metrics", func(t *testing.T) {
+		metrics := []Metric{NewMean(3), NewStd(3), NewSkewness(3), NewKurtosis(3)}
+		expectedMetrics := []Metric{NewMean(3), NewStd(3), NewSkewness(3), NewKurtosis(3)}
+		for _, metric := range expectedMetrics {
+			err := Init(metric)
+			require.NoError(t, err)
+		}
+
+		aggregate, err := NewAggregate(metrics...)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4, 8} {
+			err := aggregate.Push(x)
+			require.NoError(t, err)
+			for _, metric := range expectedMetrics {
+				err := metric.Push(x)
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Equal(t, 3, aggregate.Core().Count())
+
+		values, err := aggregate.Values()
+		require.NoError(t, err)
+		assert.Len(t, values, 4)
+		for _, metric := range expectedMetrics {
+			expected, err := metric.Value()
+			require.NoError(t, err)
+			testutil.Approx(t, expected, values[metric.String()])
+		}
+	})
+
+	t.Run("pass: weighted and timed values are pushed to the Core", func(t *testing.T) {
+		mean := NewMean(0)
+		aggregate, err := NewAggregate(mean)
+		require.NoError(t, err)
+
+		err = aggregate.PushWeighted(1, 3)
+		require.NoError(t, err)
+		err = aggregate.PushAt(5, time.Now())
+		require.NoError(t, err)
+
+		values, err := aggregate.Values()
+		require.NoError(t, err)
+		testutil.Approx(t, 2, values[mean.String()])
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		aggregate, err := NewAggregate(NewMean(3), NewStd(3))
+		require.NoError(t, err)
+
+		_, err = aggregate.Values()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+		testutil.ContainsError(t, err, "2 errors occurred")
+	})
+}
+
+func TestAggregateSnapshot(t *testing.T) {
+	t.Run("pass: values and count reflect the same values", func(t *testing.T) {
+		mean := NewGlobalMean()
+		aggregate, err := NewAggregate(mean, NewGlobalStd())
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := aggregate.Push(x)
+			require.NoError(t, err)
+		}
+		err = aggregate.PushWeighted(4, 2)
+		require.NoError(t, err)
+		err = aggregate.PushAt(5, time.Now())
+		require.NoError(t, err)
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 5, snapshot.Count)
+		assert.Len(t, snapshot.Values, 2)
+		testutil.Approx(t, 19./6, snapshot.Values[mean.String()])
+
+		aggregate.Clear()
+		_, err = aggregate.Snapshot()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+	})
+
+	t.Run("pass: count is the number of values in the window", func(t *testing.T) {
+		mean := NewMean(3)
+		aggregate, err := NewAggregate(mean, NewStd(3))
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4, 5} {
+			err := aggregate.Push(x)
+			require.NoError(t, err)
+		}
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 3, snapshot.Count)
+		testutil.Approx(t, 4., snapshot.Values[mean.String()])
+	})
+
+	t.Run("fail: metrics from other packages cannot be read", func(t *testing.T) {
+		// embedding the interface hides the unexported methods of the Mean
+		aggregate, err := NewAggregate(struct{ Metric }{NewGlobalMean()})
+		require.NoError(t, err)
+
+		err = aggregate.Push(1)
+		require.NoError(t, err)
+
+		_, err = aggregate.Snapshot()
+		testutil.ContainsError(t, err, "cannot be read in a snapshot")
+	})
+}
+
+func TestAggregateClear(t *testing.T) {
+	mean := NewMean(3)
+	aggregate, err := NewAggregate(mean, NewStd(3))
+	require.NoError(t, err)
+
+	for _, x := range []float64{1, 2, 3} {
+		err := aggregate.Push(x)
+		require.NoError(t, err)
+	}
+
+	aggregate.Clear()
+	assert.Equal(t, 0, aggregate.Core().Count())
+	_, err = mean.Value()
+	testutil.ContainsError(t, err, "no values seen yet")
+}
@@ -1,21 +1,31 @@
 package moment
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
 )
 
 // CoreConfig is the struct containing configuration options for
 // instantiating a Core object.
 type CoreConfig struct {
-	Sums   SumsConfig // sums tracked must be positive
-	Window *int       // must be 0 if decay is set, must be nonnegative in general
-	Decay  *float64   // optional, must lie in the interval (0, 1)
+	Sums     SumsConfig     // sums tracked must be positive
+	Window   *int           // must be 0 if decay, a half-life or a duration is set, must be nonnegative in general
+	Decay    *float64       // optional, must lie in the interval (0, 1)
+	HalfLife *time.Duration // optional, decays values by elapsed time instead of by push; must be positive
+	Duration *time.Duration // optional, tracks values over a time-based window; must be nonnegative
+	Clock    stream.Clock   // optional, used to timestamp and expire values if a half-life or duration is set; defaults to stream.SystemClock
 }
 
 var defaultConfig = &CoreConfig{
-	Sums:   map[int]bool{},
-	Window: nil,
-	Decay:  nil,
+	Sums:     map[int]bool{},
+	Window:   nil,
+	Decay:    nil,
+	HalfLife: nil,
+	Duration: nil,
+	Clock:    nil,
 }
 
 // SumsConfig is an alias for a map of ints to bools; this configures
@@ -37,8 +47,11 @@ func MergeConfigs(configs ...*CoreConfig) (*CoreConfig, error) {
 		return configs[0], nil
 	default:
 		var (
-			window *int
-			decay  *float64
+			window   *int
+			decay    *float64
+			halfLife *time.Duration
+			duration *time.Duration
+			clock    stream.Clock
 		)
 		mergedConfig := &CoreConfig{
 			Sums: SumsConfig{},
@@ -64,10 +77,37 @@ func MergeConfigs(configs ...*CoreConfig) (*CoreConfig, error) {
 					return nil, errors.New("configs have differing decays")
 				}
 			}
+
+			if config.HalfLife != nil {
+				if halfLife == nil {
+					halfLife = config.HalfLife
+				} else if *halfLife != *config.HalfLife {
+					return nil, errors.New("configs have differing half-lives")
+				}
+			}
+
+			if config.Duration != nil {
+				if duration == nil {
+					duration = config.Duration
+				} else if *duration != *config.Duration {
+					return nil, errors.New("configs have differing durations")
+				}
+			}
+
+			if config.Clock != nil {
+				if clock == nil {
+					clock = config.Clock
+				} else if clock != config.Clock {
+					return nil, errors.New("configs have differing clocks")
+				}
+			}
 		}
 
 		mergedConfig.Window = window
 		mergedConfig.Decay = decay
+		mergedConfig.HalfLife = halfLife
+		mergedConfig.Duration = duration
+		mergedConfig.Clock = clock
 		return mergedConfig, nil
 	}
 }
@@ -87,6 +127,28 @@ func validateConfig(config *CoreConfig) error {
 		}
 	}
 
+	if config.HalfLife != nil {
+		if *config.HalfLife <= 0 {
+			return errors.Errorf("config has a nonpositive half-life of %v", *config.HalfLife)
+		} else if *config.Window > 0 {
+			return errors.New("config cannot have HalfLife set with a nonzero window")
+		} else if config.Decay != nil {
+			return errors.New("config cannot have both Decay and HalfLife set")
+		} else if config.Duration != nil && *config.Duration > 0 {
+			return errors.New("config cannot have both HalfLife and Duration set")
+		}
+	}
+
+	if config.Duration != nil {
+		if *config.Duration < 0 {
+			return errors.Errorf("config has a negative duration of %v", *config.Duration)
+		} else if *config.Duration > 0 && *config.Window > 0 {
+			return errors.New("config cannot have Duration set with a nonzero window")
+		} else if *config.Duration > 0 && config.Decay != nil {
+			return errors.New("config cannot have both Decay and Duration set")
+		}
+	}
+
 	for k := range config.Sums {
 		if k <= 0 {
 			return errors.Errorf("config has a nonpositive central moment of %d", k)
@@ -109,5 +171,17 @@ func setConfigDefaults(config *CoreConfig) *CoreConfig {
 		config.Decay = defaultConfig.Decay
 	}
 
+	if config.HalfLife == nil {
+		config.HalfLife = defaultConfig.HalfLife
+	}
+
+	if config.Duration == nil {
+		config.Duration = defaultConfig.Duration
+	}
+
+	if config.Clock == nil {
+		config.Clock = defaultConfig.Clock
+	}
+
 	return config
 }
@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +54,72 @@ func TestValidateConfig(t *testing.T) {
 		assert.EqualError(t, err, fmt.Sprintf("config has a nonpositive central moment of %d", -1))
 	})
 
+	t.Run("fail: config with a nonpositive half-life is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(0),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config has a nonpositive half-life of 0s")
+	})
+
+	t.Run("fail: config with a set half-life and nonzero window is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(3),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have HalfLife set with a nonzero window")
+	})
+
+	t.Run("fail: config with a set half-life and decay is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Decay:    stream.FloatPtr(0.3),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have both Decay and HalfLife set")
+	})
+
+	t.Run("fail: config with a set half-life and duration is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(time.Second),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have both HalfLife and Duration set")
+	})
+
+	t.Run("fail: config with a negative duration is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(-time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config has a negative duration of -1s")
+	})
+
+	t.Run("fail: config with a set duration and nonzero window is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(3),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have Duration set with a nonzero window")
+	})
+
+	t.Run("fail: config with a set duration and decay is invalid", func(t *testing.T) {
+		config := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Decay:    stream.FloatPtr(0.3),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		err := validateConfig(config)
+		assert.EqualError(t, err, "config cannot have both Decay and Duration set")
+	})
+
 	t.Run("fail: config without Window is invalid", func(t *testing.T) {
 		config := &CoreConfig{}
 		err := validateConfig(config)
@@ -163,6 +230,34 @@ func TestMergeConfigs(t *testing.T) {
 		assert.EqualError(t, err, "configs have differing windows")
 	})
 
+	t.Run("fail: multiple configs passed fails if half-lives are not compatible", func(t *testing.T) {
+		config1 := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(time.Second),
+		}
+		config2 := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(time.Minute),
+		}
+
+		_, err := MergeConfigs(config1, config2)
+		assert.EqualError(t, err, "configs have differing half-lives")
+	})
+
+	t.Run("fail: multiple configs passed fails if durations are not compatible", func(t *testing.T) {
+		config1 := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Second),
+		}
+		config2 := &CoreConfig{
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+		}
+
+		_, err := MergeConfigs(config1, config2)
+		assert.EqualError(t, err, "configs have differing durations")
+	})
+
 	t.Run("fail: multiple configs passed fails if decays are not compatible", func(t *testing.T) {
 		config1 := &CoreConfig{
 			Sums:   SumsConfig{1: true, 2: true},
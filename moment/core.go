@@ -3,10 +3,13 @@ package moment
 import (
 	"math"
 	"sync"
+	"time"
 
+	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
 
+	"github.com/alexander-yu/stream"
 	mathutil "github.com/alexander-yu/stream/util/math"
 )
 
@@ -16,9 +19,25 @@ type Core struct {
 	mean   float64
 	sums   []float64
 	count  int
+	weight float64
 	window int
 	decay  *float64
 	queue  *queue.RingBuffer
+	// Used if halfLife > 0
+	halfLife    time.Duration
+	decayWeight float64
+	// Used if duration > 0
+	duration time.Duration
+	timed    *deque.Deque[timedItem]
+	// Used if halfLife > 0 or duration > 0
+	clock  stream.Clock
+	latest time.Time
+}
+
+// timedItem is a value in a time-based window, along with the time it was pushed at.
+type timedItem struct {
+	item interface{}
+	t    time.Time
 }
 
 // Init sets a CoreWrapper up with a core for consuming.
@@ -59,9 +78,28 @@ func NewCore(config *CoreConfig) (*Core, error) {
 
 	c.queue = queue.NewRingBuffer(uint64(c.window))
 
+	if config.HalfLife != nil {
+		c.halfLife = *config.HalfLife
+	}
+	if config.Duration != nil {
+		c.duration = *config.Duration
+	}
+	c.clock = config.Clock
+	if c.clock == nil {
+		c.clock = stream.SystemClock
+	}
+	c.timed = new(deque.Deque[timedItem])
+
 	return c, nil
 }
 
+// weightedValue is a value in the window that was pushed with a weight;
+// values pushed without weights are stored as plain float64s.
+type weightedValue struct {
+	x float64
+	w float64
+}
+
 // Push adds a new value for a Core object to consume.
 func (c *Core) Push(x float64) error {
 	c.mux.Lock()
@@ -69,34 +107,169 @@ func (c *Core) Push(x float64) error {
 	return c.UnsafePush(x)
 }
 
+// PushWeighted adds a new value with a given (positive) weight for a Core
+// object to consume. For example, pushing a value with a weight of 3 is
+// equivalent to pushing it 3 times, but the weight need not be an integer.
+// If the Core has a window, the window still holds a fixed number of pushes,
+// and a weighted value is removed with its weight once it falls out of the window.
+func (c *Core) PushWeighted(x float64, w float64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.UnsafePushWeighted(x, w)
+}
+
+// UnsafePushWeighted adds a new value with a given (positive) weight for a Core
+// object to consume, but does not lock. This should only be used if the user
+// plans to make use of the Lock()/Unlock() Core methods.
+func (c *Core) UnsafePushWeighted(x float64, w float64) error {
+	if w <= 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+		return errors.Errorf("weight %f is not a positive finite number", w)
+	} else if c.decay != nil || c.halfLife != 0 {
+		return errors.New("weighted pushes are not supported with decay set")
+	}
+
+	return c.push(x, weightedValue{x: x, w: w}, c.now())
+}
+
 // UnsafePush adds a new value for a Core object to consume,
 // but does not lock. This should only be used if the user
 // plans to make use of the Lock()/Unlock() Core methods.
 func (c *Core) UnsafePush(x float64) error {
-	if c.window != 0 {
-		if c.queue.Len() == uint64(c.window) {
-			tail, err := c.queue.Get()
-			if err != nil {
-				return errors.Wrap(err, "error popping item from queue")
-			}
+	return c.push(x, x, c.now())
+}
 
-			c.remove(tail.(float64))
-		}
+// PushAt adds a new value for a Core object to consume, which was observed
+// at the provided time. This is only meaningful if the Core tracks values over
+// a time-based window (i.e. has a Duration set), in which case values at or
+// before t - Duration are removed from the window, or if the Core decays values
+// by a HalfLife, in which case values are decayed by the time elapsed since the
+// previous push; otherwise the time is ignored. Values must be pushed in
+// chronological order. With a Duration, t must also be on the timeline of the
+// Core's Clock, against which Expire and the values of metrics remove values
+// (see stream.Clock).
+func (c *Core) PushAt(x float64, t time.Time) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.UnsafePushAt(x, t)
+}
+
+// UnsafePushAt adds a new value for a Core object to consume, which was observed
+// at the provided time, but does not lock. This should only be used if the user
+// plans to make use of the Lock()/Unlock() Core methods.
+func (c *Core) UnsafePushAt(x float64, t time.Time) error {
+	return c.push(x, x, t)
+}
 
-		err := c.queue.Put(x)
+// now returns the current time according to the Core's clock, if the Core
+// tracks values over a time-based window or decays values by a half-life.
+func (c *Core) now() time.Time {
+	if c.duration == 0 && c.halfLife == 0 {
+		return time.Time{}
+	}
+	return c.clock.Now()
+}
+
+// push adds a new item (either a float64 or a weightedValue) for the value x,
+// evicting any values that have fallen out of the window.
+func (c *Core) push(x float64, item interface{}, t time.Time) error {
+	if (c.duration != 0 || c.halfLife != 0) && t.Before(c.latest) {
+		return errors.Errorf("time %v is before the latest time %v", t, c.latest)
+	}
+
+	err := c.evict(t)
+	if err != nil {
+		return err
+	}
+
+	if c.window != 0 {
+		err := c.queue.Put(item)
 		if err != nil {
 			return errors.Wrapf(err, "error pushing %f to queue", x)
 		}
+	} else if c.duration != 0 {
+		c.timed.PushBack(timedItem{item: item, t: t})
 	}
 
-	if c.decay == nil {
-		c.add(x)
-	} else {
-		c.addDecay(x)
+	switch val := item.(type) {
+	case weightedValue:
+		c.addWeighted(val.x, val.w)
+	default:
+		if c.decay == nil && c.halfLife == 0 {
+			c.add(x)
+		} else {
+			c.addDecay(x, t)
+		}
+	}
+
+	if c.duration != 0 || c.halfLife != 0 {
+		c.latest = t
+	}
+	return nil
+}
+
+// evict removes the oldest value from the window if the window is full, or
+// if tracking a time-based window, removes all values at or before t - duration.
+func (c *Core) evict(t time.Time) error {
+	if c.duration != 0 {
+		c.expire(t)
+		return nil
+	}
+
+	if c.window == 0 || c.queue.Len() != uint64(c.window) {
+		return nil
 	}
+
+	tail, err := c.queue.Get()
+	if err != nil {
+		return errors.Wrap(err, "error popping item from queue")
+	}
+
+	c.removeItem(tail)
 	return nil
 }
 
+// expire removes all values at or before t - duration from a time-based window.
+func (c *Core) expire(t time.Time) {
+	cutoff := t.Add(-c.duration)
+	for c.timed.Len() > 0 && !c.timed.Front().t.After(cutoff) {
+		c.removeItem(c.timed.PopFront().item)
+	}
+}
+
+// Expire removes any values that have fallen out of a time-based window as of
+// the current time of the Core's Clock, so that the stats do not include stale
+// values after a period without pushes; this is a no-op unless the Core has a
+// Duration set. Otherwise, this locks the Core for writing, so it must not be
+// called while holding RLock.
+func (c *Core) Expire() {
+	if c.duration == 0 {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.UnsafeExpire()
+}
+
+// UnsafeExpire removes any values that have fallen out of a time-based window
+// as of the current time of the Core's Clock, but does not lock. This should
+// only be used if the user plans to make use of the Lock()/Unlock() Core methods.
+func (c *Core) UnsafeExpire() {
+	if c.duration != 0 {
+		c.expire(c.clock.Now())
+	}
+}
+
+// removeItem removes an item (either a float64 or a weightedValue) from the stats.
+func (c *Core) removeItem(item interface{}) {
+	switch val := item.(type) {
+	case weightedValue:
+		c.removeWeighted(val.x, val.w)
+	default:
+		c.remove(val.(float64))
+	}
+}
+
 // add updates the mean, count, and centralized power sums in an efficient
 // and stable (numerically speaking) way, which allows for more accurate reporting
 // of moments. See the following paper for details on the algorithm used:
@@ -105,7 +278,8 @@ func (c *Core) UnsafePush(x float64) error {
 // moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
 func (c *Core) add(x float64) {
 	c.count++
-	count := float64(c.count)
+	c.weight++
+	count := c.weight
 	delta := x - c.mean
 	c.mean += delta / count
 	for k := len(c.sums) - 1; k >= 2; k-- {
@@ -128,15 +302,11 @@ func (c *Core) add(x float64) {
 // P. Pebay, T. B. Terriberry, H. Kolla, J. Bennett, Numerically stable, scalable
 // formulas for parallel and online computation of higher-order multivariate central
 // moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
-func (c *Core) addDecay(x float64) {
+func (c *Core) addDecay(x float64, t time.Time) {
 	c.count++
+	c.weight++
 
-	var decay float64
-	if c.count == 1 {
-		decay = 1
-	} else {
-		decay = *c.decay
-	}
+	decay := c.decayAt(t)
 
 	delta := x - c.mean
 	c.mean += decay * delta
@@ -155,13 +325,37 @@ func (c *Core) addDecay(x float64) {
 	}
 }
 
+// decayAt returns the weight given to a new value pushed at time t, relative
+// to the total (decayed) weight of all values seen so far. With a constant decay,
+// this is just the decay itself (apart from the first value); with a half-life,
+// the weight of each value is halved for every half-life that has elapsed since
+// it was pushed, so the relative weight of a new value depends on the time elapsed
+// since the previous push rather than on the number of pushes.
+func (c *Core) decayAt(t time.Time) float64 {
+	if c.halfLife == 0 {
+		if c.count == 1 {
+			return 1
+		}
+		return *c.decay
+	}
+
+	if c.count == 1 {
+		c.decayWeight = 1
+	} else {
+		elapsed := float64(t.Sub(c.latest)) / float64(c.halfLife)
+		c.decayWeight = c.decayWeight*math.Exp2(-elapsed) + 1
+	}
+	return 1 / c.decayWeight
+}
+
 // remove simply undoes the result of an add() call, and clears out the stats
 // if we remove the last item of a window (only needed in the case where the
 // window size is 1).
 func (c *Core) remove(x float64) {
 	c.count--
+	c.weight--
 	if c.count > 0 {
-		count := float64(c.count)
+		count := c.weight
 		c.mean -= (x - c.mean) / count
 		delta := x - c.mean
 		for k := 2; k <= len(c.sums)-1; k++ {
@@ -177,14 +371,169 @@ func (c *Core) remove(x float64) {
 			}
 		}
 	} else {
-		c.mean = 0
-		for k := range c.sums {
-			c.sums[k] = 0
+		c.clearStats()
+	}
+}
+
+// addWeighted updates the mean, total weight, and centralized power sums for
+// a value with an arbitrary weight. This treats the value as a partition of
+// its own (with a weight of w and power sums of 0), and combines it with the
+// existing stats using the pairwise update from the following paper:
+// P. Pebay, T. B. Terriberry, H. Kolla, J. Bennett, Numerically stable, scalable
+// formulas for parallel and online computation of higher-order multivariate central
+// moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
+func (c *Core) addWeighted(x float64, w float64) {
+	weight := c.weight
+	c.count++
+	c.weight += w
+
+	delta := x - c.mean
+	c.mean += w * delta / c.weight
+	for k := len(c.sums) - 1; k >= 2; k-- {
+		c.sums[k] += w * math.Pow(weight*delta/c.weight, float64(k))
+		for i := 1; i <= k; i++ {
+			c.sums[k] +=
+				float64(mathutil.Binom(k, i)) *
+					math.Pow(-w*delta/c.weight, float64(i)) *
+					c.sum(k-i, weight)
+		}
+	}
+}
+
+// removeWeighted undoes the result of an addWeighted() call, and clears out the stats
+// if we remove the last item of a window.
+func (c *Core) removeWeighted(x float64, w float64) {
+	c.count--
+	if c.count > 0 {
+		total := c.weight
+		c.weight -= w
+		c.mean = (total*c.mean - w*x) / c.weight
+		delta := x - c.mean
+		// since the update for the kth power sum depends on the lower power
+		// sums before the update, we need to undo the updates in increasing order
+		for k := 2; k <= len(c.sums)-1; k++ {
+			c.sums[k] -= w * math.Pow(c.weight*delta/total, float64(k))
+			for i := 1; i <= k; i++ {
+				c.sums[k] -=
+					float64(mathutil.Binom(k, i)) *
+						math.Pow(-w*delta/total, float64(i)) *
+						c.sum(k-i, c.weight)
+			}
 		}
+	} else {
+		c.clearStats()
+	}
+}
+
+// sum returns the kth power sum for a given total weight; the 0th power sum
+// is the total weight itself, and the 1st power sum is always 0.
+func (c *Core) sum(k int, weight float64) float64 {
+	switch k {
+	case 0:
+		return weight
+	case 1:
+		return 0
+	default:
+		return c.sums[k]
+	}
+}
+
+// clearStats resets the mean, total weight and centralized power sums.
+func (c *Core) clearStats() {
+	c.weight = 0
+	c.mean = 0
+	for k := range c.sums {
+		c.sums[k] = 0
 	}
 }
 
-// Count returns the number of values seen seen globally.
+// Merge combines the stats of another Core into this one, so that the
+// Core reflects the union of the values seen by both. This allows for values
+// to be consumed in parallel (or on separate hosts) and combined afterwards.
+// Both Cores must be global (i.e. have a window of 0 and no duration) and must not be
+// exponentially weighted, and the other Core must track every power sum
+// that this Core tracks. See the following paper for details on the
+// algorithm used:
+// P. Pebay, T. B. Terriberry, H. Kolla, J. Bennett, Numerically stable, scalable
+// formulas for parallel and online computation of higher-order multivariate central
+// moments with arbitrary weights, Computational Statistics 31 (2016) 1305–1325.
+func (c *Core) Merge(other *Core) error {
+	// copy the other Core's stats first, so that we never hold both locks
+	// at once (which also allows for a Core to be merged with itself)
+	other.mux.RLock()
+	otherWindow := other.window
+	otherDuration := other.duration
+	otherDecay := other.decay
+	otherHalfLife := other.halfLife
+	otherCount := other.count
+	otherWeight := other.weight
+	otherMean := other.mean
+	otherSums := make([]float64, len(other.sums))
+	copy(otherSums, other.sums)
+	other.mux.RUnlock()
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.window != 0 || otherWindow != 0 || c.duration != 0 || otherDuration != 0 {
+		return errors.New("cannot merge Cores with nonzero windows")
+	}
+
+	if c.decay != nil || otherDecay != nil || c.halfLife != 0 || otherHalfLife != 0 {
+		return errors.New("cannot merge Cores with decay set")
+	}
+
+	if len(otherSums) < len(c.sums) {
+		return errors.Errorf(
+			"cannot merge Core tracking sums up to %d into Core tracking sums up to %d",
+			len(otherSums)-1,
+			len(c.sums)-1,
+		)
+	}
+
+	if otherCount == 0 {
+		return nil
+	}
+
+	countA := c.weight
+	countB := otherWeight
+	count := countA + countB
+	delta := otherMean - c.mean
+
+	// the kth power sum of a partition relative to its own mean;
+	// note that the 0th power sum is just the total weight, and the 1st is always 0
+	sum := func(sums []float64, n float64, k int) float64 {
+		switch k {
+		case 0:
+			return n
+		case 1:
+			return 0
+		default:
+			return sums[k]
+		}
+	}
+
+	// recenter each partition's power sums around the merged mean, using
+	// the binomial expansion of ((x - μ_A) + (μ_A - μ))^k
+	newSums := make([]float64, len(c.sums))
+	for k := 2; k < len(c.sums); k++ {
+		for i := 0; i <= k; i++ {
+			binom := float64(mathutil.Binom(k, i))
+			newSums[k] += binom * math.Pow(-countB*delta/count, float64(i)) * sum(c.sums, countA, k-i)
+			newSums[k] += binom * math.Pow(countA*delta/count, float64(i)) * sum(otherSums, countB, k-i)
+		}
+	}
+
+	c.count += otherCount
+	c.weight += otherWeight
+	c.mean += countB * delta / count
+	c.sums = newSums
+	return nil
+}
+
+// Count returns the number of values seen seen globally. If the Core tracks
+// values over a time-based window, this does not remove values that have expired
+// since the latest push; call Expire beforehand to do so.
 func (c *Core) Count() int {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -198,7 +547,24 @@ func (c *Core) UnsafeCount() int {
 	return c.count
 }
 
-// Mean returns the mean of values seen.
+// Weight returns the total weight of values seen; if no values
+// have been pushed with weights, this is the same as the count.
+// As with Count, call Expire beforehand to remove expired values.
+func (c *Core) Weight() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.UnsafeWeight()
+}
+
+// UnsafeWeight returns the total weight of values seen,
+// but does not lock. This should only be used if the user
+// plans to make use of the [R]Lock()/[R]Unlock() Core methods.
+func (c *Core) UnsafeWeight() float64 {
+	return c.weight
+}
+
+// Mean returns the mean of values seen. As with Count,
+// call Expire beforehand to remove expired values.
 func (c *Core) Mean() (float64, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -218,7 +584,8 @@ func (c *Core) UnsafeMean() (float64, error) {
 
 // Sum returns the kth-power centralized sum of values seen.
 // In other words, this returns the kth power sum of the differences
-// of the values seen from their mean.
+// of the values seen from their mean. As with Count, call Expire
+// beforehand to remove expired values.
 func (c *Core) Sum(k int) (float64, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -256,9 +623,21 @@ func (c *Core) UnsafeClear() {
 	}
 
 	c.count = 0
+	c.weight = 0
 	c.mean = 0
 	c.queue.Dispose()
 	c.queue = queue.NewRingBuffer(uint64(c.window))
+	c.decayWeight = 0
+	c.timed.Clear()
+	c.latest = time.Time{}
+}
+
+// rlock removes any values that have fallen out of a time-based window under the
+// write lock, and then locks the Core for reading; this is used by the values of
+// metrics, rather than by the accessors of the Core, which are safe under RLock.
+func (c *Core) rlock() {
+	c.Expire()
+	c.mux.RLock()
 }
 
 // RLock locks the core internals for reading.
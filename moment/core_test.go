@@ -2,7 +2,9 @@ package moment
 
 import (
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -377,3 +379,457 @@ func TestLock(t *testing.T) {
 	require.NoError(t, err)
 	testutil.Approx(t, 26./3., sum)
 }
+
+func TestMerge(t *testing.T) {
+	t.Run("pass: merged Core matches Core that saw all values", func(t *testing.T) {
+		expected := &mockWrapper{window: stream.IntPtr(0)}
+		left := &mockWrapper{window: stream.IntPtr(0)}
+		right := &mockWrapper{window: stream.IntPtr(0)}
+		for _, wrapper := range []*mockWrapper{expected, left, right} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		xs := []float64{1, 2, 3, 4, 8, -5, 10.5}
+		for i, x := range xs {
+			err := expected.core.Push(x)
+			require.NoError(t, err)
+
+			if i < 3 {
+				err = left.core.Push(x)
+			} else {
+				err = right.core.Push(x)
+			}
+			require.NoError(t, err)
+		}
+
+		err := left.core.Merge(right.core)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected.core.count, left.core.count)
+		testutil.Approx(t, expected.core.mean, left.core.mean)
+		testutil.ApproxSlice(t, expected.core.sums, left.core.sums)
+
+		// the merged Core should keep consuming values as usual
+		err = expected.core.Push(7)
+		require.NoError(t, err)
+		err = left.core.Push(7)
+		require.NoError(t, err)
+
+		testutil.Approx(t, expected.core.mean, left.core.mean)
+		testutil.ApproxSlice(t, expected.core.sums, left.core.sums)
+	})
+
+	t.Run("pass: merging into an empty Core copies the other Core", func(t *testing.T) {
+		empty := &mockWrapper{window: stream.IntPtr(0)}
+		other := &mockWrapper{window: stream.IntPtr(0)}
+		for _, wrapper := range []*mockWrapper{empty, other} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		for _, x := range []float64{1, 2, 3, 4, 8} {
+			err := other.core.Push(x)
+			require.NoError(t, err)
+		}
+
+		err := empty.core.Merge(other.core)
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, empty.core.count)
+		testutil.Approx(t, other.core.mean, empty.core.mean)
+		testutil.ApproxSlice(t, other.core.sums, empty.core.sums)
+	})
+
+	t.Run("fail: windowed Cores cannot be merged", func(t *testing.T) {
+		global := &mockWrapper{window: stream.IntPtr(0)}
+		windowed := &mockWrapper{window: stream.IntPtr(3)}
+		for _, wrapper := range []*mockWrapper{global, windowed} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		err := global.core.Merge(windowed.core)
+		assert.EqualError(t, err, "cannot merge Cores with nonzero windows")
+	})
+
+	t.Run("fail: decayed Cores cannot be merged", func(t *testing.T) {
+		global := &mockWrapper{window: stream.IntPtr(0)}
+		decayed := &mockWrapper{window: stream.IntPtr(0), decay: stream.FloatPtr(0.3)}
+		for _, wrapper := range []*mockWrapper{global, decayed} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		err := decayed.core.Merge(global.core)
+		assert.EqualError(t, err, "cannot merge Cores with decay set")
+	})
+
+	t.Run("fail: Core must track all sums", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(0)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		core, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{2: true},
+			Window: stream.IntPtr(0),
+		})
+		require.NoError(t, err)
+
+		err = wrapper.core.Merge(core)
+		assert.EqualError(t, err, "cannot merge Core tracking sums up to 2 into Core tracking sums up to 4")
+	})
+}
+
+func TestPushWeighted(t *testing.T) {
+	t.Run("pass: integer weights match repeated pushes", func(t *testing.T) {
+		weighted := &mockWrapper{window: stream.IntPtr(0)}
+		repeated := &mockWrapper{window: stream.IntPtr(0)}
+		for _, wrapper := range []*mockWrapper{weighted, repeated} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		xs := []float64{1, 2, 3, 4, 8}
+		ws := []float64{2, 1, 3, 1, 4}
+		for i, x := range xs {
+			err := weighted.core.PushWeighted(x, ws[i])
+			require.NoError(t, err)
+
+			for j := 0; j < int(ws[i]); j++ {
+				err := repeated.core.Push(x)
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Equal(t, 5, weighted.core.Count())
+		testutil.Approx(t, 11., weighted.core.Weight())
+		testutil.Approx(t, repeated.core.mean, weighted.core.mean)
+		testutil.ApproxSlice(t, repeated.core.sums, weighted.core.sums)
+	})
+
+	t.Run("pass: weighted values are removed from window", func(t *testing.T) {
+		windowed := &mockWrapper{window: stream.IntPtr(3)}
+		expected := &mockWrapper{window: stream.IntPtr(0)}
+		for _, wrapper := range []*mockWrapper{windowed, expected} {
+			err := Init(wrapper)
+			require.NoError(t, err)
+		}
+
+		xs := []float64{1, 2, 3, 4, 8}
+		ws := []float64{0.5, 2.5, 1, 3, 1.5}
+		for i, x := range xs {
+			err := windowed.core.PushWeighted(x, ws[i])
+			require.NoError(t, err)
+		}
+
+		// mix in an unweighted push as well, which evicts the 3rd value
+		err := windowed.core.Push(5)
+		require.NoError(t, err)
+
+		for i := 3; i < len(xs); i++ {
+			err := expected.core.PushWeighted(xs[i], ws[i])
+			require.NoError(t, err)
+		}
+		err = expected.core.Push(5)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, windowed.core.Count())
+		testutil.Approx(t, expected.core.weight, windowed.core.weight)
+		testutil.Approx(t, expected.core.mean, windowed.core.mean)
+		testutil.ApproxSlice(t, expected.core.sums, windowed.core.sums)
+	})
+
+	t.Run("pass: removing last weighted value clears stats", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{2: true},
+			Window: stream.IntPtr(1),
+		})
+		require.NoError(t, err)
+
+		err = core.PushWeighted(1, 2)
+		require.NoError(t, err)
+		err = core.PushWeighted(2, 3)
+		require.NoError(t, err)
+
+		testutil.Approx(t, 3., core.weight)
+		testutil.Approx(t, 2., core.mean)
+		testutil.ApproxSlice(t, []float64{0, 0, 0}, core.sums)
+	})
+
+	t.Run("fail: nonpositive weights are invalid", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{Window: stream.IntPtr(0)})
+		require.NoError(t, err)
+
+		err = core.PushWeighted(1, 0)
+		assert.EqualError(t, err, fmt.Sprintf("weight %f is not a positive finite number", 0.))
+	})
+
+	t.Run("fail: weighted pushes are unsupported with decay", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{
+			Window: stream.IntPtr(0),
+			Decay:  stream.FloatPtr(0.3),
+		})
+		require.NoError(t, err)
+
+		err = core.PushWeighted(1, 2)
+		assert.EqualError(t, err, "weighted pushes are not supported with decay set")
+	})
+}
+
+func TestPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start.Add(12 * time.Second))
+		timed, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{2: true, 3: true},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(10 * time.Second),
+			Clock:    clock,
+		})
+		require.NoError(t, err)
+
+		expected, err := NewCore(&CoreConfig{
+			Sums:   SumsConfig{2: true, 3: true},
+			Window: stream.IntPtr(0),
+		})
+		require.NoError(t, err)
+
+		offsets := []int{0, 2, 3, 6, 12}
+		xs := []float64{1, 2, 3, 4, 8}
+		for i, x := range xs {
+			err := timed.PushAt(x, start.Add(time.Duration(offsets[i])*time.Second))
+			require.NoError(t, err)
+		}
+
+		// the values at 0s and 2s are at or before the cutoff of 12s - 10s
+		for _, x := range xs[2:] {
+			err := expected.Push(x)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 3, timed.Count())
+		testutil.Approx(t, expected.mean, timed.mean)
+		testutil.ApproxSlice(t, expected.sums, timed.sums)
+
+		// all values are removed after a long enough gap
+		clock.Advance(48 * time.Second)
+		err = timed.PushAt(5, start.Add(time.Minute))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, timed.Count())
+		testutil.Approx(t, 5., timed.mean)
+		testutil.ApproxSlice(t, []float64{0, 0, 0, 0}, timed.sums)
+	})
+
+	t.Run("pass: Push uses the configured clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		core, err := NewCore(&CoreConfig{
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+			Clock:    clock,
+		})
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := core.Push(x)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		err = core.PushWeighted(6, 2)
+		require.NoError(t, err)
+
+		// only the value pushed at 60s and the weighted value at 90s remain
+		assert.Equal(t, 2, core.Count())
+		testutil.Approx(t, 3., core.Weight())
+		mean, err := core.Mean()
+		require.NoError(t, err)
+		testutil.Approx(t, 5., mean)
+	})
+
+	t.Run("pass: expired values are removed by Expire", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		core, err := NewCore(&CoreConfig{
+			Sums:     SumsConfig{2: true},
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+			Clock:    clock,
+		})
+		require.NoError(t, err)
+
+		for _, x := range []float64{100, 4} {
+			err := core.Push(x)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		// the accessors do not remove values on their own, so they can be called under RLock
+		core.RLock()
+		assert.Equal(t, 2, core.Count())
+		core.RUnlock()
+
+		// only the value pushed at 30s is left, without any further pushes
+		core.Expire()
+		assert.Equal(t, 1, core.Count())
+		mean, err := core.Mean()
+		require.NoError(t, err)
+		testutil.Approx(t, 4., mean)
+
+		clock.Advance(time.Hour)
+		core.Expire()
+		assert.Equal(t, 0, core.Count())
+		testutil.Approx(t, 0., core.Weight())
+		_, err = core.Mean()
+		assert.EqualError(t, err, "no values seen yet")
+		_, err = core.Sum(2)
+		assert.EqualError(t, err, "no values seen yet")
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{
+			Window:   stream.IntPtr(0),
+			Duration: stream.DurationPtr(time.Minute),
+		})
+		require.NoError(t, err)
+
+		err = core.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = core.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+
+	t.Run("pass: time is ignored without a duration", func(t *testing.T) {
+		core, err := NewCore(&CoreConfig{Window: stream.IntPtr(2)})
+		require.NoError(t, err)
+
+		for i, x := range []float64{1, 2, 3} {
+			err := core.PushAt(x, start.Add(-time.Duration(i)*time.Hour))
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 2, core.Count())
+	})
+}
+
+func TestHalfLife(t *testing.T) {
+	start := time.Unix(1000, 0)
+	config := func() *CoreConfig {
+		return &CoreConfig{
+			Sums:     SumsConfig{2: true, 3: true},
+			Window:   stream.IntPtr(0),
+			HalfLife: stream.DurationPtr(10 * time.Second),
+		}
+	}
+
+	t.Run("pass: values are weighted by the time elapsed since they were pushed", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		offsets := []float64{0, 1, 1, 7, 30, 32.5, 60}
+		xs := []float64{1, 2, 3, 4, 8, -5, 10.5}
+		for i, x := range xs {
+			err := core.PushAt(x, start.Add(time.Duration(offsets[i]*float64(time.Second))))
+			require.NoError(t, err)
+		}
+
+		// the weight of each value is halved every 10 seconds after it was pushed
+		last := offsets[len(offsets)-1]
+		weights := make([]float64, len(xs))
+		var total, mean float64
+		for i, x := range xs {
+			weights[i] = math.Exp2(-(last - offsets[i]) / 10)
+			total += weights[i]
+			mean += weights[i] * x
+		}
+		mean /= total
+
+		sums := make([]float64, 4)
+		for i, x := range xs {
+			for k := 2; k <= 3; k++ {
+				sums[k] += weights[i] * math.Pow(x-mean, float64(k)) / total
+			}
+		}
+
+		testutil.Approx(t, mean, core.mean)
+		testutil.ApproxSlice(t, sums, core.sums)
+	})
+
+	t.Run("pass: quiet periods decay old values", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		err = core.PushAt(0, start)
+		require.NoError(t, err)
+		err = core.PushAt(10, start.Add(10*time.Second))
+		require.NoError(t, err)
+
+		// the first value has half the weight of the second
+		testutil.Approx(t, 20./3, core.mean)
+
+		err = core.PushAt(20, start.Add(time.Hour))
+		require.NoError(t, err)
+
+		testutil.Approx(t, 20., core.mean)
+	})
+
+	t.Run("pass: values pushed at the same time are weighted equally", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 3, 5} {
+			err := core.PushAt(x, start)
+			require.NoError(t, err)
+		}
+
+		testutil.Approx(t, 3., core.mean)
+		testutil.Approx(t, 8./3, core.sums[2])
+	})
+
+	t.Run("pass: Push uses the configured clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		c := config()
+		c.Clock = clock
+		core, err := NewCore(c)
+		require.NoError(t, err)
+
+		err = core.Push(0)
+		require.NoError(t, err)
+		clock.Advance(10 * time.Second)
+		err = core.Push(10)
+		require.NoError(t, err)
+
+		testutil.Approx(t, 20./3, core.mean)
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		err = core.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = core.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+
+	t.Run("fail: weighted pushes are not supported", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+
+		err = core.PushWeighted(1, 2)
+		assert.EqualError(t, err, "weighted pushes are not supported with decay set")
+	})
+
+	t.Run("fail: Cores with a half-life cannot be merged", func(t *testing.T) {
+		core, err := NewCore(config())
+		require.NoError(t, err)
+		global, err := NewCore(&CoreConfig{Window: stream.IntPtr(0)})
+		require.NoError(t, err)
+
+		err = global.Merge(core)
+		assert.EqualError(t, err, "cannot merge Cores with decay set")
+	})
+}
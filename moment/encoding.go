@@ -0,0 +1,277 @@
+package moment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/gammazero/deque"
+	"github.com/pkg/errors"
+	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older snapshots.
+const encodingVersion uint8 = 1
+
+// encodedItem is the binary representation of a value in the window.
+// A weight of 0 denotes a value that was pushed without a weight, and
+// a time of 0 denotes a value in a count-based window.
+type encodedItem struct {
+	X float64
+	W float64
+	T int64
+}
+
+// MarshalBinary encodes the state of the Core (including any values
+// currently in its window) into a binary form, so that it can be
+// checkpointed and later restored with UnmarshalBinary. The Clock of
+// the Core is not encoded.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (c *Core) MarshalBinary() ([]byte, error) {
+	// reading the window contents requires mutating the queue,
+	// so we need to hold the write lock
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	items, err := c.windowItems()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading window values")
+	}
+
+	var hasDecay uint8
+	var decay float64
+	if c.decay != nil {
+		hasDecay = 1
+		decay = *c.decay
+	}
+
+	buf := &bytes.Buffer{}
+	for _, field := range []interface{}{
+		encodingVersion,
+		int64(c.count),
+		c.weight,
+		int64(c.window),
+		int64(c.halfLife),
+		c.decayWeight,
+		int64(c.duration),
+		encodeTime(c.latest),
+		hasDecay,
+		decay,
+		c.mean,
+		uint32(len(c.sums)),
+		c.sums,
+		uint32(len(items)),
+		items,
+	} {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding Core")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the Core from data produced by
+// MarshalBinary, replacing any state the Core currently has. If the Core
+// does not have a Clock set already, stream.SystemClock is used.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (c *Core) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var (
+		count       int64
+		weight      float64
+		window      int64
+		halfLife    int64
+		decayWeight float64
+		duration    int64
+		latest      int64
+		hasDecay    uint8
+		decay       float64
+		mean        float64
+		numSums     uint32
+	)
+	for _, field := range []interface{}{
+		&count,
+		&weight,
+		&window,
+		&halfLife,
+		&decayWeight,
+		&duration,
+		&latest,
+		&hasDecay,
+		&decay,
+		&mean,
+		&numSums,
+	} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding Core")
+		}
+	}
+
+	if window < 0 {
+		return errors.Errorf("encoded Core has a negative window of %d", window)
+	} else if duration < 0 {
+		return errors.Errorf("encoded Core has a negative duration of %v", time.Duration(duration))
+	} else if halfLife < 0 {
+		return errors.Errorf("encoded Core has a negative half-life of %v", time.Duration(halfLife))
+	}
+
+	if 8*int(numSums) > r.Len() {
+		return errors.Errorf("encoded Core has %d sums but only %d bytes remaining", numSums, r.Len())
+	}
+
+	sums := make([]float64, numSums)
+	err = binary.Read(r, binary.BigEndian, sums)
+	if err != nil {
+		return errors.Wrap(err, "error decoding sums")
+	}
+
+	var numValues uint32
+	err = binary.Read(r, binary.BigEndian, &numValues)
+	if err != nil {
+		return errors.Wrap(err, "error decoding window size")
+	}
+
+	if duration == 0 && int64(numValues) > window {
+		return errors.Errorf("encoded Core has %d window values for a window of %d", numValues, window)
+	}
+
+	if 24*int(numValues) > r.Len() {
+		return errors.Errorf("encoded Core has %d window values but only %d bytes remaining", numValues, r.Len())
+	}
+
+	items := make([]encodedItem, numValues)
+	err = binary.Read(r, binary.BigEndian, items)
+	if err != nil {
+		return errors.Wrap(err, "error decoding window values")
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded Core has %d trailing bytes", r.Len())
+	}
+
+	q := queue.NewRingBuffer(uint64(window))
+	timed := new(deque.Deque[timedItem])
+	for _, encoded := range items {
+		var item interface{} = encoded.X
+		if encoded.W != 0 {
+			item = weightedValue{x: encoded.X, w: encoded.W}
+		}
+
+		if duration != 0 {
+			timed.PushBack(timedItem{item: item, t: time.Unix(0, encoded.T)})
+			continue
+		}
+
+		err := q.Put(item)
+		if err != nil {
+			return errors.Wrapf(err, "error pushing %f to queue", encoded.X)
+		}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.queue != nil {
+		c.queue.Dispose()
+	}
+
+	if c.clock == nil {
+		c.clock = stream.SystemClock
+	}
+
+	c.count = int(count)
+	c.weight = weight
+	c.window = int(window)
+	c.halfLife = time.Duration(halfLife)
+	c.decayWeight = decayWeight
+	c.duration = time.Duration(duration)
+	c.decay = nil
+	if hasDecay != 0 {
+		c.decay = &decay
+	}
+	c.mean = mean
+	c.sums = sums
+	c.queue = q
+	c.timed = timed
+	c.latest = decodeTime(latest)
+
+	return nil
+}
+
+// windowItems returns the encoded items currently in the window, from oldest
+// to newest. The queue is drained and refilled in the same order, so this
+// must be called while holding the write lock.
+func (c *Core) windowItems() ([]encodedItem, error) {
+	items := []encodedItem{}
+	if c.duration != 0 {
+		for i := 0; i < c.timed.Len(); i++ {
+			timed := c.timed.At(i)
+			items = append(items, encodeItem(timed.item, timed.t.UnixNano()))
+		}
+		return items, nil
+	} else if c.window == 0 {
+		return items, nil
+	}
+
+	n := c.queue.Len()
+	raw := make([]interface{}, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item, err := c.queue.Get()
+		if err != nil {
+			return nil, errors.Wrap(err, "error popping item from queue")
+		}
+
+		raw = append(raw, item)
+		items = append(items, encodeItem(item, 0))
+	}
+
+	for _, item := range raw {
+		err := c.queue.Put(item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error pushing %v to queue", item)
+		}
+	}
+
+	return items, nil
+}
+
+func encodeItem(item interface{}, t int64) encodedItem {
+	switch val := item.(type) {
+	case weightedValue:
+		return encodedItem{X: val.x, W: val.w, T: t}
+	default:
+		return encodedItem{X: val.(float64), T: t}
+	}
+}
+
+// encodeTime encodes a time as nanoseconds since the Unix epoch,
+// where the zero time.Time is encoded as 0.
+func encodeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func decodeTime(t int64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
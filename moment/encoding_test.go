@@ -0,0 +1,264 @@
+package moment
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestCoreMarshalBinary(t *testing.T) {
+	t.Run("pass: restored windowed Core matches original", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(3)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4, 8} {
+			err := wrapper.core.Push(x)
+			require.NoError(t, err)
+		}
+
+		data, err := wrapper.core.MarshalBinary()
+		require.NoError(t, err)
+
+		core := &Core{}
+		err = core.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, wrapper.core.count, core.count)
+		assert.Equal(t, wrapper.core.window, core.window)
+		assert.Nil(t, core.decay)
+		testutil.Approx(t, wrapper.core.mean, core.mean)
+		testutil.ApproxSlice(t, wrapper.core.sums, core.sums)
+		assert.Equal(t, uint64(3), core.queue.Len())
+
+		// marshaling should not disturb the original window
+		assert.Equal(t, uint64(3), wrapper.core.queue.Len())
+
+		// both Cores should evict the same values going forward
+		for _, x := range []float64{5, 6} {
+			err := wrapper.core.Push(x)
+			require.NoError(t, err)
+			err = core.Push(x)
+			require.NoError(t, err)
+		}
+
+		testutil.Approx(t, wrapper.core.mean, core.mean)
+		testutil.ApproxSlice(t, wrapper.core.sums, core.sums)
+	})
+
+	t.Run("pass: restored decayed Core matches original", func(t *testing.T) {
+		wrapper := &mockWrapper{
+			window: stream.IntPtr(0),
+			decay:  stream.FloatPtr(0.3),
+		}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		for _, x := range []float64{3, 4, 8} {
+			err := wrapper.core.Push(x)
+			require.NoError(t, err)
+		}
+
+		data, err := wrapper.core.MarshalBinary()
+		require.NoError(t, err)
+
+		core := &Core{}
+		err = core.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.3, *core.decay)
+
+		err = wrapper.core.Push(2)
+		require.NoError(t, err)
+		err = core.Push(2)
+		require.NoError(t, err)
+
+		assert.Equal(t, wrapper.core.count, core.count)
+		testutil.Approx(t, wrapper.core.mean, core.mean)
+		testutil.ApproxSlice(t, wrapper.core.sums, core.sums)
+	})
+
+	t.Run("pass: restored Core keeps weighted window values", func(t *testing.T) {
+		wrapper := &mockWrapper{window: stream.IntPtr(2)}
+		err := Init(wrapper)
+		require.NoError(t, err)
+
+		err = wrapper.core.PushWeighted(1, 2.5)
+		require.NoError(t, err)
+		err = wrapper.core.Push(3)
+		require.NoError(t, err)
+
+		data, err := wrapper.core.MarshalBinary()
+		require.NoError(t, err)
+
+		core := &Core{}
+		err = core.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		testutil.Approx(t, 3.5, core.weight)
+
+		// evicting the weighted value should remove its full weight
+		for _, x := range []float64{5, 6} {
+			err := wrapper.core.Push(x)
+			require.NoError(t, err)
+			err = core.Push(x)
+			require.NoError(t, err)
+		}
+
+		testutil.Approx(t, 2., core.weight)
+		testutil.Approx(t, wrapper.core.mean, core.mean)
+		testutil.ApproxSlice(t, wrapper.core.sums, core.sums)
+	})
+
+	t.Run("pass: restored Core keeps time-based window values", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		config := func() *CoreConfig {
+			return &CoreConfig{
+				Sums:     SumsConfig{2: true},
+				Window:   stream.IntPtr(0),
+				Duration: stream.DurationPtr(10 * time.Second),
+			}
+		}
+
+		src, err := NewCore(config())
+		require.NoError(t, err)
+		for i, x := range []float64{1, 2, 3} {
+			err := src.PushAt(x, start.Add(time.Duration(4*i)*time.Second))
+			require.NoError(t, err)
+		}
+
+		data, err := src.MarshalBinary()
+		require.NoError(t, err)
+
+		dst, err := NewCore(config())
+		require.NoError(t, err)
+		err = dst.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10*time.Second, dst.duration)
+		assert.Equal(t, 3, dst.timed.Len())
+
+		err = dst.PushAt(4, start.Add(4*time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+
+		// the value at 4s should be removed along with the value at 0s
+		err = src.PushAt(4, start.Add(14*time.Second))
+		require.NoError(t, err)
+		err = dst.PushAt(4, start.Add(14*time.Second))
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, dst.count)
+		testutil.Approx(t, src.mean, dst.mean)
+		testutil.ApproxSlice(t, src.sums, dst.sums)
+	})
+
+	t.Run("pass: restored Core keeps half-life state", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		config := func() *CoreConfig {
+			return &CoreConfig{
+				Sums:     SumsConfig{2: true},
+				Window:   stream.IntPtr(0),
+				HalfLife: stream.DurationPtr(10 * time.Second),
+			}
+		}
+
+		src, err := NewCore(config())
+		require.NoError(t, err)
+		for i, x := range []float64{1, 2, 3} {
+			err := src.PushAt(x, start.Add(time.Duration(4*i)*time.Second))
+			require.NoError(t, err)
+		}
+
+		data, err := src.MarshalBinary()
+		require.NoError(t, err)
+
+		dst := &Core{}
+		err = dst.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10*time.Second, dst.halfLife)
+		assert.Equal(t, src.latest, dst.latest)
+
+		// both Cores should decay the same amount going forward
+		err = src.PushAt(4, start.Add(20*time.Second))
+		require.NoError(t, err)
+		err = dst.PushAt(4, start.Add(20*time.Second))
+		require.NoError(t, err)
+
+		testutil.Approx(t, src.decayWeight, dst.decayWeight)
+		testutil.Approx(t, src.mean, dst.mean)
+		testutil.ApproxSlice(t, src.sums, dst.sums)
+	})
+
+	t.Run("pass: restoring replaces existing state", func(t *testing.T) {
+		src, err := NewCore(&CoreConfig{Window: stream.IntPtr(2)})
+		require.NoError(t, err)
+		for _, x := range []float64{1, 2} {
+			err := src.Push(x)
+			require.NoError(t, err)
+		}
+
+		data, err := src.MarshalBinary()
+		require.NoError(t, err)
+
+		dst, err := NewCore(&CoreConfig{Window: stream.IntPtr(5)})
+		require.NoError(t, err)
+		for _, x := range []float64{10, 20, 30} {
+			err := dst.Push(x)
+			require.NoError(t, err)
+		}
+
+		err = dst.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, dst.Count())
+		mean, err := dst.Mean()
+		require.NoError(t, err)
+		testutil.Approx(t, 1.5, mean)
+	})
+}
+
+func TestCoreUnmarshalBinary(t *testing.T) {
+	core, err := NewCore(&CoreConfig{Window: stream.IntPtr(3)})
+	require.NoError(t, err)
+	err = core.Push(1)
+	require.NoError(t, err)
+
+	data, err := core.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("fail: unsupported version returns error", func(t *testing.T) {
+		corrupt := append([]byte{}, data...)
+		corrupt[0] = 0
+		err := (&Core{}).UnmarshalBinary(corrupt)
+		assert.EqualError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: newer version returns error", func(t *testing.T) {
+		corrupt := append([]byte{}, data...)
+		corrupt[0] = encodingVersion + 1
+		err := (&Core{}).UnmarshalBinary(corrupt)
+		assert.EqualError(t, err, fmt.Sprintf("unsupported encoding version %d", encodingVersion+1))
+	})
+
+	t.Run("fail: truncated data returns error", func(t *testing.T) {
+		err := (&Core{}).UnmarshalBinary(data[:len(data)-1])
+		testutil.ContainsError(t, err, "window values")
+	})
+
+	t.Run("fail: trailing data returns error", func(t *testing.T) {
+		err := (&Core{}).UnmarshalBinary(append(data, 0))
+		assert.EqualError(t, err, "encoded Core has 1 trailing bytes")
+	})
+
+	t.Run("fail: empty data returns error", func(t *testing.T) {
+		err := (&Core{}).UnmarshalBinary(nil)
+		testutil.ContainsError(t, err, "error decoding encoding version")
+	})
+}
@@ -2,6 +2,7 @@ package moment
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -10,8 +11,9 @@ import (
 
 // EWMA is a metric that tracks the exponentially weighted moving average.
 type EWMA struct {
-	decay float64
-	core  *Core
+	decay    float64
+	halfLife time.Duration
+	core     *Core
 }
 
 // NewEWMA instantiates a EWMA struct.
@@ -19,6 +21,13 @@ func NewEWMA(decay float64) *EWMA {
 	return &EWMA{decay: decay}
 }
 
+// NewHalfLifeEWMA instantiates a EWMA struct that decays values by the time
+// elapsed between pushes instead of by a constant decay per push; the weight
+// of each value is halved for every half-life that passes after it is pushed.
+func NewHalfLifeEWMA(halfLife time.Duration) *EWMA {
+	return &EWMA{halfLife: halfLife}
+}
+
 // SetCore sets the Core.
 func (a *EWMA) SetCore(c *Core) {
 	a.core = c
@@ -31,6 +40,13 @@ func (a *EWMA) IsSetCore() bool {
 
 // Config returns the CoreConfig needed.
 func (a *EWMA) Config() *CoreConfig {
+	if a.halfLife != 0 {
+		return &CoreConfig{
+			Window:   stream.IntPtr(0),
+			HalfLife: &a.halfLife,
+		}
+	}
+
 	return &CoreConfig{
 		Window: stream.IntPtr(0),
 		Decay:  &a.decay,
@@ -41,6 +57,9 @@ func (a *EWMA) Config() *CoreConfig {
 func (a *EWMA) String() string {
 	name := "moment.EWMA"
 	decay := fmt.Sprintf("decay:%v", a.decay)
+	if a.halfLife != 0 {
+		decay = fmt.Sprintf("halfLife:%v", a.halfLife)
+	}
 	return fmt.Sprintf("%s_{%s}", name, decay)
 }
 
@@ -57,16 +76,36 @@ func (a *EWMA) Push(x float64) error {
 	return nil
 }
 
+// PushAt adds a new value for EWMA to consume, which was observed at the provided
+// time; this is only meaningful if the EWMA decays values by a half-life.
+func (a *EWMA) PushAt(x float64, t time.Time) error {
+	if !a.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := a.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the exponentially weighted moving average.
 func (a *EWMA) Value() (float64, error) {
 	if !a.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	a.core.RLock()
+	a.core.rlock()
 	defer a.core.RUnlock()
 
-	ewma, err := a.core.Mean()
+	return a.value()
+}
+
+// value returns the value of the exponentially weighted moving average,
+// but does not lock the Core.
+func (a *EWMA) value() (float64, error) {
+	ewma, err := a.core.UnsafeMean()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
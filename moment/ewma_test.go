@@ -2,6 +2,7 @@ package moment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -103,3 +104,27 @@ func TestEWMAString(t *testing.T) {
 	expectedString := "moment.EWMA_{decay:0.3}"
 	assert.Equal(t, expectedString, ewma.String())
 }
+
+func TestHalfLifeEWMA(t *testing.T) {
+	start := time.Unix(1000, 0)
+	ewma := NewHalfLifeEWMA(time.Minute)
+	assert.Equal(t, "moment.EWMA_{halfLife:1m0s}", ewma.String())
+
+	err := ewma.PushAt(1, start)
+	testutil.ContainsError(t, err, "Core is not set")
+
+	err = Init(ewma)
+	require.NoError(t, err)
+	assert.Nil(t, ewma.core.decay)
+	assert.Equal(t, time.Minute, ewma.core.halfLife)
+
+	for i, x := range []float64{1, 7} {
+		err := ewma.PushAt(x, start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	// the first value has half the weight of the second
+	value, err := ewma.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, 5., value)
+}
@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,9 +12,10 @@ import (
 
 // EWMMoment is a metric that tracks the kth exponentially weighted sample central moment.
 type EWMMoment struct {
-	k     int
-	decay float64
-	core  *Core
+	k        int
+	decay    float64
+	halfLife time.Duration
+	core     *Core
 }
 
 // NewEWMMoment instantiates a EWMMoment struct.
@@ -24,6 +26,16 @@ func NewEWMMoment(k int, decay float64) *EWMMoment {
 	}
 }
 
+// NewHalfLifeEWMMoment instantiates a EWMMoment struct that decays values by the
+// time elapsed between pushes instead of by a constant decay per push; the weight
+// of each value is halved for every half-life that passes after it is pushed.
+func NewHalfLifeEWMMoment(k int, halfLife time.Duration) *EWMMoment {
+	return &EWMMoment{
+		k:        k,
+		halfLife: halfLife,
+	}
+}
+
 // SetCore sets the Core.
 func (m *EWMMoment) SetCore(c *Core) {
 	m.core = c
@@ -36,6 +48,14 @@ func (m *EWMMoment) IsSetCore() bool {
 
 // Config returns the CoreConfig needed.
 func (m *EWMMoment) Config() *CoreConfig {
+	if m.halfLife != 0 {
+		return &CoreConfig{
+			Sums:     SumsConfig{m.k: true},
+			Window:   stream.IntPtr(0),
+			HalfLife: &m.halfLife,
+		}
+	}
+
 	return &CoreConfig{
 		Sums: SumsConfig{m.k: true},
 		// exponentially-weighted moments must be global, as the
@@ -51,11 +71,19 @@ func (m *EWMMoment) String() string {
 	name := "moment.EWMMoment"
 	params := []string{
 		fmt.Sprintf("k:%v", m.k),
-		fmt.Sprintf("decay:%v", m.decay),
+		m.decayParam(),
 	}
 	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
 }
 
+// decayParam returns the string representation of how values are decayed.
+func (m *EWMMoment) decayParam() string {
+	if m.halfLife != 0 {
+		return fmt.Sprintf("halfLife:%v", m.halfLife)
+	}
+	return fmt.Sprintf("decay:%v", m.decay)
+}
+
 // Push adds a new value for EWMMoment to consume.
 func (m *EWMMoment) Push(x float64) error {
 	if !m.IsSetCore() {
@@ -69,13 +97,36 @@ func (m *EWMMoment) Push(x float64) error {
 	return nil
 }
 
+// PushAt adds a new value for EWMMoment to consume, which was observed at the provided
+// time; this is only meaningful if the EWMMoment decays values by a half-life.
+func (m *EWMMoment) PushAt(x float64, t time.Time) error {
+	if !m.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := m.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the kth exponentially weighted sample central moment.
 func (m *EWMMoment) Value() (float64, error) {
 	if !m.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	moment, err := m.core.Sum(m.k)
+	m.core.rlock()
+	defer m.core.RUnlock()
+
+	return m.value()
+}
+
+// value returns the value of the kth exponentially weighted sample central
+// moment, but does not lock the Core.
+func (m *EWMMoment) value() (float64, error) {
+	moment, err := m.core.UnsafeSum(m.k)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
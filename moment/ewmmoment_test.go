@@ -2,6 +2,7 @@ package moment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -106,3 +107,9 @@ func TestEWMMomentString(t *testing.T) {
 	expectedString := "moment.EWMMoment_{k:2,decay:0.3}"
 	assert.Equal(t, expectedString, moment.String())
 }
+
+func TestHalfLifeEWMMomentString(t *testing.T) {
+	moment := NewHalfLifeEWMMoment(2, time.Second)
+	expectedString := "moment.EWMMoment_{k:2,halfLife:1s}"
+	assert.Equal(t, expectedString, moment.String())
+}
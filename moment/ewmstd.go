@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -17,6 +18,13 @@ func NewEWMStd(decay float64) *EWMStd {
 	return &EWMStd{variance: NewEWMMoment(2, decay)}
 }
 
+// NewHalfLifeEWMStd instantiates an EWMStd struct that decays values by the time
+// elapsed between pushes instead of by a constant decay per push; the weight of
+// each value is halved for every half-life that passes after it is pushed.
+func NewHalfLifeEWMStd(halfLife time.Duration) *EWMStd {
+	return &EWMStd{variance: NewHalfLifeEWMMoment(2, halfLife)}
+}
+
 // SetCore sets the Core.
 func (s *EWMStd) SetCore(c *Core) {
 	s.variance.SetCore(c)
@@ -35,8 +43,7 @@ func (s *EWMStd) Config() *CoreConfig {
 // String returns a string representation of the metric.
 func (s *EWMStd) String() string {
 	name := "moment.EWMStd"
-	decay := fmt.Sprintf("decay:%v", *s.variance.Config().Decay)
-	return fmt.Sprintf("%s_{%s}", name, decay)
+	return fmt.Sprintf("%s_{%s}", name, s.variance.decayParam())
 }
 
 // Push adds a new value for EWMStd to consume.
@@ -52,13 +59,36 @@ func (s *EWMStd) Push(x float64) error {
 	return nil
 }
 
+// PushAt adds a new value for EWMStd to consume, which was observed at the provided
+// time; this is only meaningful if the EWMStd decays values by a half-life.
+func (s *EWMStd) PushAt(x float64, t time.Time) error {
+	if !s.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := s.variance.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the exponentially weighted sample standard deviation.
 func (s *EWMStd) Value() (float64, error) {
 	if !s.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	variance, err := s.variance.Value()
+	s.variance.core.rlock()
+	defer s.variance.core.RUnlock()
+
+	return s.value()
+}
+
+// value returns the value of the exponentially weighted sample standard deviation,
+// but does not lock the Core.
+func (s *EWMStd) value() (float64, error) {
+	variance, err := s.variance.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 2nd moment")
 	}
@@ -3,6 +3,7 @@ package moment
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -107,3 +108,25 @@ func TestEWMStdString(t *testing.T) {
 	expectedString := "moment.EWMStd_{decay:0.3}"
 	assert.Equal(t, expectedString, std.String())
 }
+
+func TestHalfLifeEWMStd(t *testing.T) {
+	start := time.Unix(1000, 0)
+	std := NewHalfLifeEWMStd(time.Minute)
+	assert.Equal(t, "moment.EWMStd_{halfLife:1m0s}", std.String())
+
+	err := std.PushAt(1, start)
+	testutil.ContainsError(t, err, "Core is not set")
+
+	err = Init(std)
+	require.NoError(t, err)
+
+	for i, x := range []float64{1, 7} {
+		err := std.PushAt(x, start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	// weights of 1/3 and 2/3 around a mean of 5, i.e. (16 + 2 * 4) / 3
+	value, err := std.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, math.Sqrt(8), value)
+}
@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -75,26 +76,60 @@ func (k *Kurtosis) Push(x float64) error {
 	return nil
 }
 
+// PushWeighted adds a new value with a given weight for Kurtosis to consume.
+// Weights are treated as frequency weights, i.e. pushing a value with
+// a weight of 3 is equivalent to pushing it 3 times.
+func (k *Kurtosis) PushWeighted(x float64, w float64) error {
+	if !k.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := k.core.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
+// PushAt adds a new value for Kurtosis to consume, which was observed at the provided
+// time; this is only meaningful if the Core tracks values over a time-based window.
+func (k *Kurtosis) PushAt(x float64, t time.Time) error {
+	if !k.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := k.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample excess kurtosis.
 func (k *Kurtosis) Value() (float64, error) {
 	if !k.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	k.core.RLock()
+	k.core.rlock()
 	defer k.core.RUnlock()
 
-	count := float64(k.core.Count())
+	return k.value()
+}
+
+// value returns the value of the sample excess kurtosis, but does not lock the Core.
+func (k *Kurtosis) value() (float64, error) {
+	count := k.core.UnsafeWeight()
 	if count == 0 {
 		return 0, errors.New("no values seen yet")
 	}
 
-	variance, err := k.variance.Value()
+	variance, err := k.variance.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 2nd moment")
 	}
 
-	moment, err := k.moment4.Value()
+	moment, err := k.moment4.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 4th moment")
 	}
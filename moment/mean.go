@@ -2,6 +2,7 @@ package moment
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -60,16 +61,50 @@ func (m *Mean) Push(x float64) error {
 	return nil
 }
 
+// PushWeighted adds a new value with a given weight for Mean to consume.
+// Weights are treated as frequency weights, i.e. pushing a value with
+// a weight of 3 is equivalent to pushing it 3 times.
+func (m *Mean) PushWeighted(x float64, w float64) error {
+	if !m.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := m.core.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
+// PushAt adds a new value for Mean to consume, which was observed at the provided
+// time; this is only meaningful if the Core tracks values over a time-based window.
+func (m *Mean) PushAt(x float64, t time.Time) error {
+	if !m.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := m.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the mean.
 func (m *Mean) Value() (float64, error) {
 	if !m.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	m.core.RLock()
+	m.core.rlock()
 	defer m.core.RUnlock()
 
-	mean, err := m.core.Mean()
+	return m.value()
+}
+
+// value returns the value of the mean, but does not lock the Core.
+func (m *Mean) value() (float64, error) {
+	mean, err := m.core.UnsafeMean()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
@@ -2,11 +2,13 @@ package moment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alexander-yu/stream"
 	testutil "github.com/alexander-yu/stream/util/test"
 )
 
@@ -132,3 +134,39 @@ func TestMeanString(t *testing.T) {
 	expectedString := "moment.Mean_{window:3}"
 	assert.Equal(t, expectedString, mean.String())
 }
+
+func TestMeanPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := testutil.NewClock(start.Add(12 * time.Second))
+	core, err := NewCore(&CoreConfig{
+		Window:   stream.IntPtr(0),
+		Duration: stream.DurationPtr(10 * time.Second),
+		Clock:    clock,
+	})
+	require.NoError(t, err)
+
+	mean := NewGlobalMean()
+	mean.SetCore(core)
+
+	for i, x := range []float64{100, 1, 2, 3} {
+		err := mean.PushAt(x, start.Add(time.Duration(4*i)*time.Second))
+		require.NoError(t, err)
+	}
+
+	value, err := mean.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, 2., value)
+
+	// the values expire without any further pushes
+	clock.Advance(5 * time.Second)
+	value, err = mean.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, 2.5, value)
+
+	clock.Advance(time.Hour)
+	_, err = mean.Value()
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	err = NewGlobalMean().PushAt(1, start)
+	testutil.ContainsError(t, err, "Core is not set")
+}
@@ -0,0 +1,158 @@
+package moment
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+// weightedMetric is a Metric that supports weighted pushes.
+type weightedMetric interface {
+	Metric
+	PushWeighted(x float64, w float64) error
+}
+
+// weightedMoment returns the total weight, the weighted mean and the kth
+// weighted central sum of the values xs with (frequency) weights ws.
+func weightedMoment(xs []float64, ws []float64, k int) (float64, float64, float64) {
+	var weight, mean float64
+	for i, x := range xs {
+		weight += ws[i]
+		mean += ws[i] * x
+	}
+	mean /= weight
+
+	var sum float64
+	for i, x := range xs {
+		sum += ws[i] * math.Pow(x-mean, float64(k))
+	}
+	return weight, mean, sum
+}
+
+func TestMetricPushWeighted(t *testing.T) {
+	testCases := []struct {
+		name      string
+		newMetric func(window int) weightedMetric
+		expected  func(xs []float64, ws []float64) float64
+	}{
+		{
+			name:      "Mean",
+			newMetric: func(window int) weightedMetric { return NewMean(window) },
+			expected: func(xs []float64, ws []float64) float64 {
+				_, mean, _ := weightedMoment(xs, ws, 1)
+				return mean
+			},
+		},
+		{
+			name:      "Moment",
+			newMetric: func(window int) weightedMetric { return New(3, window) },
+			expected: func(xs []float64, ws []float64) float64 {
+				weight, _, sum := weightedMoment(xs, ws, 3)
+				return sum / (weight - 1)
+			},
+		},
+		{
+			name:      "Std",
+			newMetric: func(window int) weightedMetric { return NewStd(window) },
+			expected: func(xs []float64, ws []float64) float64 {
+				weight, _, sum := weightedMoment(xs, ws, 2)
+				return math.Sqrt(sum / (weight - 1))
+			},
+		},
+		{
+			name:      "Skewness",
+			newMetric: func(window int) weightedMetric { return NewSkewness(window) },
+			expected: func(xs []float64, ws []float64) float64 {
+				weight, _, sum2 := weightedMoment(xs, ws, 2)
+				_, _, sum3 := weightedMoment(xs, ws, 3)
+				adjust := math.Sqrt(weight*(weight-1)) / (weight - 2)
+				return adjust * (sum3 / weight) / math.Pow(sum2/weight, 1.5)
+			},
+		},
+		{
+			name:      "Kurtosis",
+			newMetric: func(window int) weightedMetric { return NewKurtosis(window) },
+			expected: func(xs []float64, ws []float64) float64 {
+				weight, _, sum2 := weightedMoment(xs, ws, 2)
+				_, _, sum4 := weightedMoment(xs, ws, 4)
+				return (sum4/weight)/math.Pow(sum2/weight, 2) - 3
+			},
+		},
+	}
+
+	xs := []float64{1, 2, 3, 4, 8}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("pass: integer weights match repeated pushes", func(t *testing.T) {
+				weighted := tc.newMetric(0)
+				repeated := tc.newMetric(0)
+				for _, metric := range []weightedMetric{weighted, repeated} {
+					err := Init(metric)
+					require.NoError(t, err)
+				}
+
+				ws := []float64{2, 1, 3, 1, 4}
+				for i, x := range xs {
+					err := weighted.PushWeighted(x, ws[i])
+					require.NoError(t, err)
+
+					for j := 0; j < int(ws[i]); j++ {
+						err := repeated.Push(x)
+						require.NoError(t, err)
+					}
+				}
+
+				expected, err := repeated.Value()
+				require.NoError(t, err)
+				value, err := weighted.Value()
+				require.NoError(t, err)
+				testutil.Approx(t, expected, value)
+			})
+
+			t.Run("pass: fractional weights match weighted statistic", func(t *testing.T) {
+				metric := tc.newMetric(0)
+				err := Init(metric)
+				require.NoError(t, err)
+
+				ws := []float64{0.5, 2.5, 1.25, 3, 1.5}
+				for i, x := range xs {
+					err := metric.PushWeighted(x, ws[i])
+					require.NoError(t, err)
+				}
+
+				value, err := metric.Value()
+				require.NoError(t, err)
+				testutil.Approx(t, tc.expected(xs, ws), value)
+			})
+
+			t.Run("pass: weighted values are removed from window", func(t *testing.T) {
+				metric := tc.newMetric(3)
+				err := Init(metric)
+				require.NoError(t, err)
+
+				ws := []float64{0.5, 2.5, 1.25, 3, 1.5}
+				for i, x := range xs {
+					err := metric.PushWeighted(x, ws[i])
+					require.NoError(t, err)
+				}
+
+				// mix in an unweighted push as well, which evicts the 3rd value
+				err = metric.Push(5)
+				require.NoError(t, err)
+
+				value, err := metric.Value()
+				require.NoError(t, err)
+				testutil.Approx(t, tc.expected([]float64{4, 8, 5}, []float64{3, 1.5, 1}), value)
+			})
+
+			t.Run("fail: Core is not set", func(t *testing.T) {
+				err := tc.newMetric(0).PushWeighted(1, 2)
+				testutil.ContainsError(t, err, "Core is not set")
+			})
+		})
+	}
+}
@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -69,22 +70,57 @@ func (m *Moment) Push(x float64) error {
 	return nil
 }
 
+// PushWeighted adds a new value with a given weight for Moment to consume.
+// Weights are treated as frequency weights, i.e. pushing a value with
+// a weight of 3 is equivalent to pushing it 3 times.
+func (m *Moment) PushWeighted(x float64, w float64) error {
+	if !m.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := m.core.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
+// PushAt adds a new value for Moment to consume, which was observed at the provided
+// time; this is only meaningful if the Core tracks values over a time-based window.
+func (m *Moment) PushAt(x float64, t time.Time) error {
+	if !m.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := m.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the kth sample central moment.
 func (m *Moment) Value() (float64, error) {
 	if !m.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	m.core.RLock()
+	m.core.rlock()
 	defer m.core.RUnlock()
 
-	moment, err := m.core.Sum(m.k)
+	return m.value()
+}
+
+// value returns the value of the kth sample central moment,
+// but does not lock the Core.
+func (m *Moment) value() (float64, error) {
+	moment, err := m.core.UnsafeSum(m.k)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving sum")
 	}
 
-	count := m.core.Count()
-	moment /= (float64(count) - 1.)
+	weight := m.core.UnsafeWeight()
+	moment /= (weight - 1.)
 
 	return moment, nil
 }
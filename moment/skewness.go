@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -75,27 +76,62 @@ func (s *Skewness) Push(x float64) error {
 	return nil
 }
 
+// PushWeighted adds a new value with a given weight for Skewness to consume.
+// Weights are treated as frequency weights, i.e. pushing a value with
+// a weight of 3 is equivalent to pushing it 3 times.
+func (s *Skewness) PushWeighted(x float64, w float64) error {
+	if !s.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := s.core.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
+// PushAt adds a new value for Skewness to consume, which was observed at the provided
+// time; this is only meaningful if the Core tracks values over a time-based window.
+func (s *Skewness) PushAt(x float64, t time.Time) error {
+	if !s.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := s.core.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the adjusted Fisher-Pearson sample skewness.
 func (s *Skewness) Value() (float64, error) {
 	if !s.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	s.core.RLock()
+	s.core.rlock()
 	defer s.core.RUnlock()
 
-	count := float64(s.core.Count())
+	return s.value()
+}
+
+// value returns the value of the adjusted Fisher-Pearson sample skewness,
+// but does not lock the Core.
+func (s *Skewness) value() (float64, error) {
+	count := s.core.UnsafeWeight()
 	if count == 0 {
 		return 0, errors.New("no values seen yet")
 	}
 
-	variance, err := s.variance.Value()
+	variance, err := s.variance.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 2nd moment")
 	}
 	variance *= (count - 1) / count
 
-	moment, err := s.moment3.Value()
+	moment, err := s.moment3.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 3rd moment")
 	}
@@ -3,6 +3,7 @@ package moment
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -58,13 +59,51 @@ func (s *Std) Push(x float64) error {
 	return nil
 }
 
+// PushWeighted adds a new value with a given weight for Std to consume.
+// Weights are treated as frequency weights, i.e. pushing a value with
+// a weight of 3 is equivalent to pushing it 3 times.
+func (s *Std) PushWeighted(x float64, w float64) error {
+	if !s.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := s.variance.PushWeighted(x, w)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
+// PushAt adds a new value for Std to consume, which was observed at the provided
+// time; this is only meaningful if the Core tracks values over a time-based window.
+func (s *Std) PushAt(x float64, t time.Time) error {
+	if !s.IsSetCore() {
+		return errors.New("Core is not set")
+	}
+
+	err := s.variance.PushAt(x, t)
+	if err != nil {
+		return errors.Wrap(err, "error pushing to core")
+	}
+	return nil
+}
+
 // Value returns the value of the sample standard deviation.
 func (s *Std) Value() (float64, error) {
 	if !s.IsSetCore() {
 		return 0, errors.New("Core is not set")
 	}
 
-	variance, err := s.variance.Value()
+	s.variance.core.rlock()
+	defer s.variance.core.RUnlock()
+
+	return s.value()
+}
+
+// value returns the value of the sample standard deviation,
+// but does not lock the Core.
+func (s *Std) value() (float64, error) {
+	variance, err := s.variance.value()
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving 2nd moment")
 	}
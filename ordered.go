@@ -0,0 +1,37 @@
+package stream
+
+import "fmt"
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is a constraint that permits any floating-point type.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is a constraint that permits any integer or floating-point type,
+// e.g. int64 or time.Duration as well as float64.
+type Number interface {
+	Integer | Float
+}
+
+// Ordered is a constraint that permits any type that supports the < operator.
+type Ordered interface {
+	Number | ~string
+}
+
+// FormatValue formats a value for error messages and string representations;
+// floating-point values are formatted as with %f, and all other values are
+// formatted as with %v.
+func FormatValue[T Ordered](x T) string {
+	switch v := any(x).(type) {
+	case float32, float64:
+		return fmt.Sprintf("%f", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
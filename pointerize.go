@@ -1,5 +1,7 @@
 package stream
 
+import "time"
+
 /* These helpers return pointers to provided base types;
  * these are needed because Go does not allow pointers to constant
  * expressions, or for the allocation of a pointer to base type to
@@ -11,3 +13,6 @@ func IntPtr(v int) *int { return &v }
 
 // FloatPtr returns a pointer to a float.
 func FloatPtr(v float64) *float64 { return &v }
+
+// DurationPtr returns a pointer to a time.Duration.
+func DurationPtr(v time.Duration) *time.Duration { return &v }
@@ -0,0 +1,299 @@
+package quantile
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// Metric is the interface for a metric that tracks quantiles of a stream with a
+// Quantile, i.e. Quantile, Median, IQR and Summary; these can be included in an Aggregate.
+type Metric interface {
+	String() string
+	Clear()
+	base() *Quantile
+}
+
+func (q *TypedQuantile[T]) base() *TypedQuantile[T] {
+	return q
+}
+
+func (m *Median) base() *Quantile {
+	return m.quantile
+}
+
+func (i *IQR) base() *Quantile {
+	return i.quantile
+}
+
+func (s *Summary) base() *Quantile {
+	return s.quantile
+}
+
+// Aggregate tracks multiple quantile-based metrics of a stream, where the metrics
+// with the same window, duration, Impl and Clock share a single window and order
+// statistic; each value is pushed to each distinct order statistic once, rather than
+// once per metric. Metrics whose Impl was configured with order.Options (e.g. the
+// compression of a TDigest) never share an order statistic, since the options
+// cannot be compared. For example, a Median, an IQR and a Summary with the same window
+// can all share the same order statistic tree and ring buffer. Each metric still uses
+// its own interpolation, and can still be queried directly.
+type Aggregate struct {
+	metrics []Metric
+	// sources holds the shared Quantile used by each metric, in the same order
+	sources []*Quantile
+	shared  []*Quantile
+	count   int
+	mux     sync.Mutex
+}
+
+// NewAggregate instantiates an Aggregate struct, and sets up the metrics to share
+// their windows and order statistics. The Aggregate takes over the metrics: they
+// must not have any values pushed to them beforehand, and must not be used
+// concurrently until NewAggregate returns, since the windows and order statistics
+// of the metrics that do not end up owning a shared one are released.
+func NewAggregate(metrics ...Metric) (*Aggregate, error) {
+	if len(metrics) == 0 {
+		return nil, errors.New("no metrics provided")
+	}
+
+	for _, metric := range metrics {
+		err := metric.base().shareable(metric.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var shared []*Quantile
+	sources := make([]*Quantile, len(metrics))
+	for i, metric := range metrics {
+		q := metric.base()
+		var source *Quantile
+		for _, s := range shared {
+			if s.window == q.window && s.duration == q.duration && s.impl == q.impl && s.clock == q.clock &&
+				!s.implOptions && !q.implOptions {
+				source = s
+				break
+			}
+		}
+
+		if source == nil {
+			shared = append(shared, q)
+			source = q
+		} else if source != q {
+			q.share(source)
+		}
+		sources[i] = source
+	}
+
+	return &Aggregate{metrics: metrics, sources: sources, shared: shared}, nil
+}
+
+// shareable returns an error if the TypedQuantile of the named metric cannot be
+// taken over by an Aggregate, i.e. if it is already in one or has values pushed to it.
+func (q *TypedQuantile[T]) shareable(name string) error {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+
+	if q.shared != nil {
+		return errors.Errorf("metric %s is already in an Aggregate", name)
+	} else if q.statistic.Size() != 0 {
+		return errors.Errorf("metric %s already has values pushed to it", name)
+	}
+	return nil
+}
+
+// share sets up a TypedQuantile to use the window and order statistic of another
+// TypedQuantile, and releases its own.
+func (q *TypedQuantile[T]) share(source *TypedQuantile[T]) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.shared = source
+	q.queue.Dispose()
+	q.queue = nil
+	q.timed = nil
+	q.statistic = nil
+}
+
+// String returns a string representation of the metric.
+func (a *Aggregate) String() string {
+	name := "quantile.Aggregate"
+	metrics := make([]string, len(a.metrics))
+	for i, metric := range a.metrics {
+		metrics[i] = metric.String()
+	}
+	return fmt.Sprintf("%s_{metrics:[%s]}", name, strings.Join(metrics, ","))
+}
+
+// Statistics returns the number of distinct order statistics shared by the metrics.
+func (a *Aggregate) Statistics() int {
+	return len(a.shared)
+}
+
+// Push adds a number for the metrics to consume.
+func (a *Aggregate) Push(x float64) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	var (
+		result   *multierror.Error
+		consumed bool
+	)
+	for _, q := range a.shared {
+		err := q.Push(x)
+		if err != nil {
+			result = multierror.Append(result, err)
+		} else {
+			consumed = true
+		}
+	}
+
+	if consumed {
+		a.count++
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantiles", x)
+	}
+
+	return nil
+}
+
+// PushAt adds a number for the metrics to consume, which was observed
+// at the provided time; see Quantile.PushAt for details.
+func (a *Aggregate) PushAt(x float64, t time.Time) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	var (
+		result   *multierror.Error
+		consumed bool
+	)
+	for _, q := range a.shared {
+		err := q.PushAt(x, t)
+		if err != nil {
+			result = multierror.Append(result, err)
+		} else {
+			consumed = true
+		}
+	}
+
+	if consumed {
+		a.count++
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantiles", x)
+	}
+
+	return nil
+}
+
+// Values returns the values of the metrics; in particular, it returns a map of
+// strings to values, where the strings are the string representations of each metric
+// (i.e. the result of calling String()). The values of a Summary are keyed by its string
+// representation followed by the quantile in brackets (e.g. "quantile.Summary_{...}[0.99]").
+// A Quantile has no single value, so it is not included, and should be queried directly.
+func (a *Aggregate) Values() (map[string]float64, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.rlock()
+	defer a.runlock()
+
+	return a.values()
+}
+
+// Snapshot returns a consistent snapshot of the values of the metrics, along with
+// the number of values pushed through the Aggregate (and consumed by at least one
+// of the shared order statistics) since it was last cleared. Since the shared order
+// statistics stay locked while the snapshot is taken, all of the values reflect the
+// same set of values, even if the metrics are also pushed to directly; however,
+// values pushed to the metrics directly are not counted.
+func (a *Aggregate) Snapshot() (*stream.Snapshot, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.rlock()
+	defer a.runlock()
+
+	values, err := a.values()
+	if err != nil {
+		return nil, err
+	}
+
+	return &stream.Snapshot{Values: values, Count: a.count}, nil
+}
+
+// rlock locks each of the shared order statistics for reading, after removing any
+// values that have fallen out of their time-based windows.
+func (a *Aggregate) rlock() {
+	for _, q := range a.shared {
+		q.rlock()
+	}
+}
+
+// runlock undoes an rlock call.
+func (a *Aggregate) runlock() {
+	for _, q := range a.shared {
+		q.mux.RUnlock()
+	}
+}
+
+// values returns the values of the metrics, without locking.
+func (a *Aggregate) values() (map[string]float64, error) {
+	values := map[string]float64{}
+	var result *multierror.Error
+	for i, metric := range a.metrics {
+		switch metric := metric.(type) {
+		case *Median:
+			val, err := metric.value(a.sources[i])
+			if err != nil {
+				result = multierror.Append(result, err)
+			} else {
+				values[metric.String()] = val
+			}
+		case *IQR:
+			val, err := metric.value(a.sources[i])
+			if err != nil {
+				result = multierror.Append(result, err)
+			} else {
+				values[metric.String()] = val
+			}
+		case *Summary:
+			summary, err := metric.values(a.sources[i])
+			if err != nil {
+				result = multierror.Append(result, err)
+			} else {
+				for quantile, val := range summary {
+					values[fmt.Sprintf("%s[%s]", metric.String(), quantile)] = val.(float64)
+				}
+			}
+		}
+	}
+
+	err := result.ErrorOrNil()
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving values from metrics")
+	}
+
+	return values, nil
+}
+
+// Clear resets all metrics.
+func (a *Aggregate) Clear() {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.count = 0
+	for _, q := range a.shared {
+		q.Clear()
+	}
+}
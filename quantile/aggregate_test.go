@@ -0,0 +1,345 @@
+package quantile
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/quantile/tdigest"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewAggregate(t *testing.T) {
+	t.Run("pass: metrics with the same window share an order statistic", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		iqr, err := NewIQR(3)
+		require.NoError(t, err)
+		p99, err := New(3)
+		require.NoError(t, err)
+		global, err := NewGlobalMedian()
+		require.NoError(t, err)
+		skipList, err := NewMedian(3, ImplOption(SkipList))
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr, p99, global, skipList)
+		require.NoError(t, err)
+		assert.Equal(t, 3, aggregate.Statistics())
+
+		assert.Nil(t, median.quantile.shared)
+		assert.True(t, iqr.quantile.shared == median.quantile)
+		assert.True(t, p99.shared == median.quantile)
+		assert.Nil(t, iqr.quantile.statistic)
+		assert.Nil(t, global.quantile.shared)
+		assert.Nil(t, skipList.quantile.shared)
+	})
+
+	t.Run("pass: metrics with different clocks do not share an order statistic", func(t *testing.T) {
+		clock := testutil.NewClock(time.Unix(1000, 0))
+		median, err := NewGlobalMedian(DurationOption(time.Minute))
+		require.NoError(t, err)
+		iqr, err := NewGlobalIQR(DurationOption(time.Minute), ClockOption(clock))
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr)
+		require.NoError(t, err)
+		assert.Equal(t, 2, aggregate.Statistics())
+	})
+
+	t.Run("fail: no metrics is invalid", func(t *testing.T) {
+		_, err := NewAggregate()
+		testutil.ContainsError(t, err, "no metrics provided")
+	})
+
+	t.Run("fail: metric already in an Aggregate is invalid", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		iqr, err := NewIQR(3)
+		require.NoError(t, err)
+
+		_, err = NewAggregate(median, iqr)
+		require.NoError(t, err)
+
+		_, err = NewAggregate(iqr)
+		testutil.ContainsError(t, err, "is already in an Aggregate")
+	})
+
+	t.Run("pass: metrics with Impl options do not share an order statistic", func(t *testing.T) {
+		median, err := NewGlobalMedian(ImplOption(TDigest, tdigest.CompressionOption(50)))
+		require.NoError(t, err)
+		iqr, err := NewGlobalIQR(ImplOption(TDigest, tdigest.CompressionOption(500)))
+		require.NoError(t, err)
+		p99, err := NewGlobalQuantile(ImplOption(TDigest))
+		require.NoError(t, err)
+		p95, err := NewGlobalQuantile(ImplOption(TDigest))
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr, p99, p95)
+		require.NoError(t, err)
+		assert.Equal(t, 3, aggregate.Statistics())
+		assert.Nil(t, median.quantile.shared)
+		assert.Nil(t, iqr.quantile.shared)
+		assert.True(t, p95.shared == p99)
+	})
+
+	t.Run("fail: metric with values is invalid", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		iqr, err := NewIQR(3)
+		require.NoError(t, err)
+
+		err = iqr.Push(1)
+		require.NoError(t, err)
+
+		_, err = NewAggregate(median, iqr)
+		testutil.ContainsError(t, err, "already has values pushed to it")
+		assert.Nil(t, iqr.quantile.shared)
+		assert.NotNil(t, iqr.quantile.statistic)
+	})
+}
+
+func TestAggregateValues(t *testing.T) {
+	t.Run("pass: values match separate metrics", func(t *testing.T) {
+		median, err := NewMedian(4)
+		require.NoError(t, err)
+		iqr, err := NewIQR(4)
+		require.NoError(t, err)
+		summary, err := NewSummary(4, []float64{0.99})
+		require.NoError(t, err)
+		p99, err := New(4)
+		require.NoError(t, err)
+
+		expectedMedian, err := NewMedian(4)
+		require.NoError(t, err)
+		expectedIQR, err := NewIQR(4)
+		require.NoError(t, err)
+		expectedQuantile, err := New(4)
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr, summary, p99)
+		require.NoError(t, err)
+		assert.Equal(t, 1, aggregate.Statistics())
+
+		for _, x := range []float64{5, 1, 8, 2, 9, 3} {
+			err = aggregate.Push(x)
+			require.NoError(t, err)
+
+			err = expectedMedian.Push(x)
+			require.NoError(t, err)
+			err = expectedIQR.Push(x)
+			require.NoError(t, err)
+			err = expectedQuantile.Push(x)
+			require.NoError(t, err)
+		}
+
+		expectedMedianValue, err := expectedMedian.Value()
+		require.NoError(t, err)
+		expectedIQRValue, err := expectedIQR.Value()
+		require.NoError(t, err)
+		expectedP99Value, err := expectedQuantile.Value(0.99)
+		require.NoError(t, err)
+
+		values, err := aggregate.Values()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{
+			median.String():             expectedMedianValue,
+			iqr.String():                expectedIQRValue,
+			summary.String() + "[0.99]": expectedP99Value,
+		}, values)
+
+		// each metric can still be queried directly
+		value, err := median.Value()
+		require.NoError(t, err)
+		assert.Equal(t, expectedMedianValue, value)
+
+		value, err = p99.Value(0.99)
+		require.NoError(t, err)
+		assert.Equal(t, expectedP99Value, value)
+	})
+
+	t.Run("pass: pushing to a metric pushes to the shared order statistic", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		iqr, err := NewIQR(3)
+		require.NoError(t, err)
+
+		_, err = NewAggregate(median, iqr)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4} {
+			err = iqr.Push(x)
+			require.NoError(t, err)
+		}
+
+		value, err := median.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 3., value)
+	})
+
+	t.Run("pass: timed values are pushed to the shared order statistic", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start.Add(15 * time.Second))
+		median, err := NewGlobalMedian(DurationOption(10*time.Second), ClockOption(clock))
+		require.NoError(t, err)
+		iqr, err := NewGlobalIQR(DurationOption(10*time.Second), ClockOption(clock))
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr)
+		require.NoError(t, err)
+
+		for i, x := range []float64{1, 2, 3, 4} {
+			err = aggregate.PushAt(x, start.Add(time.Duration(5*i)*time.Second))
+			require.NoError(t, err)
+		}
+
+		value, err := median.Value()
+		require.NoError(t, err)
+		assert.Equal(t, 3.5, value)
+
+		err = aggregate.PushAt(5, start)
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+
+	t.Run("fail: returns error if any Value() call fails", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		summary, err := NewSummary(3, []float64{0.5})
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, summary)
+		require.NoError(t, err)
+
+		_, err = aggregate.Values()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+		testutil.ContainsError(t, err, "2 errors occurred")
+	})
+}
+
+func TestAggregateSnapshot(t *testing.T) {
+	t.Run("pass: values and count reflect the same values", func(t *testing.T) {
+		median, err := NewMedian(3)
+		require.NoError(t, err)
+		iqr, err := NewIQR(3)
+		require.NoError(t, err)
+		global, err := NewGlobalMedian()
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(median, iqr, global)
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3, 4} {
+			err = aggregate.Push(x)
+			require.NoError(t, err)
+		}
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, &stream.Snapshot{
+			Values: map[string]float64{
+				median.String(): 3,
+				iqr.String():    1,
+				global.String(): 2.5,
+			},
+			Count: 4,
+		}, snapshot)
+
+		aggregate.Clear()
+		_, err = aggregate.Snapshot()
+		testutil.ContainsError(t, err, "error retrieving values from metrics")
+	})
+
+	t.Run("pass: values consumed by some metrics are counted", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		clock := testutil.NewClock(start)
+		timed, err := NewGlobalMedian(DurationOption(time.Minute), ClockOption(clock))
+		require.NoError(t, err)
+		global, err := NewGlobalMedian()
+		require.NoError(t, err)
+
+		aggregate, err := NewAggregate(timed, global)
+		require.NoError(t, err)
+
+		err = aggregate.PushAt(1, start)
+		require.NoError(t, err)
+
+		// only the global median consumes a value pushed out of order
+		err = aggregate.PushAt(3, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+
+		snapshot, err := aggregate.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, 2, snapshot.Count)
+		assert.Equal(t, 1., snapshot.Values[timed.String()])
+		assert.Equal(t, 2., snapshot.Values[global.String()])
+	})
+}
+
+func TestAggregateClear(t *testing.T) {
+	median, err := NewMedian(3)
+	require.NoError(t, err)
+	iqr, err := NewIQR(3)
+	require.NoError(t, err)
+
+	aggregate, err := NewAggregate(median, iqr)
+	require.NoError(t, err)
+
+	for _, x := range []float64{1, 2, 3, 4} {
+		err = aggregate.Push(x)
+		require.NoError(t, err)
+	}
+
+	aggregate.Clear()
+	_, err = iqr.Value()
+	testutil.ContainsError(t, err, "no values seen yet")
+	assert.Equal(t, uint64(0), median.quantile.queue.Len())
+}
+
+func TestAggregateConcurrency(t *testing.T) {
+	median, err := NewMedian(10)
+	require.NoError(t, err)
+	iqr, err := NewIQR(10)
+	require.NoError(t, err)
+	summary, err := NewSummary(10, []float64{0.5, 0.9})
+	require.NoError(t, err)
+
+	aggregate, err := NewAggregate(median, iqr, summary)
+	require.NoError(t, err)
+
+	err = aggregate.Push(0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, aggregate.Push(float64(i*100+j)))
+				assert.NoError(t, iqr.Push(float64(j)))
+
+				_, err := aggregate.Values()
+				assert.NoError(t, err)
+				_, err = aggregate.Snapshot()
+				assert.NoError(t, err)
+				_, err = median.Value()
+				assert.NoError(t, err)
+				_, err = summary.Values()
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAggregateString(t *testing.T) {
+	median, err := NewMedian(3)
+	require.NoError(t, err)
+
+	aggregate, err := NewAggregate(median)
+	require.NoError(t, err)
+	assert.Equal(t, "quantile.Aggregate_{metrics:[quantile.Median_{quantile:quantile.Quantile_{window:3,interpolation:4}}]}", aggregate.String())
+}
@@ -0,0 +1,264 @@
+package ddsketch
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+const (
+	// DefaultRelativeAccuracy is the default relative accuracy for a DDSketch
+	DefaultRelativeAccuracy float64 = 0.01
+	// DefaultMaxBins is the default maximum number of buckets for each sign of values in a DDSketch
+	DefaultMaxBins int = 2048
+)
+
+// Node represents a bucket in a DDSketch, whose value is the
+// representative value of the bucket.
+type Node float64
+
+// Value returns the value stored at the node.
+func (n Node) Value() float64 {
+	return float64(n)
+}
+
+// DDSketch implements a DDSketch, and also satisfies the
+// order.Statistic interface.
+type DDSketch struct {
+	alpha     float64
+	maxBins   int
+	gamma     float64
+	logGamma  float64
+	positive  *store
+	negative  *store
+	zeroCount int
+}
+
+// New instantiates a DDSketch struct.
+func New(options ...order.Option) (*DDSketch, error) {
+	d := &DDSketch{
+		alpha:   DefaultRelativeAccuracy,
+		maxBins: DefaultMaxBins,
+	}
+
+	for _, option := range options {
+		err := option(d)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting option")
+		}
+	}
+
+	d.gamma = (1 + d.alpha) / (1 - d.alpha)
+	d.logGamma = math.Log(d.gamma)
+	d.positive = newStore(d.maxBins)
+	d.negative = newStore(d.maxBins)
+
+	return d, nil
+}
+
+// RelativeAccuracy returns the relative accuracy of the sketch.
+func (d *DDSketch) RelativeAccuracy() float64 {
+	return d.alpha
+}
+
+// index returns the index of the bucket that the (positive) value x belongs to;
+// the bucket with index i contains the values in (gamma^(i-1), gamma^i].
+func (d *DDSketch) index(x float64) int {
+	return int(math.Ceil(math.Log(x) / d.logGamma))
+}
+
+// value returns the representative value of the bucket with index i, which
+// is within a relative error of alpha of every value in the bucket.
+func (d *DDSketch) value(i int) float64 {
+	return 2 * math.Pow(d.gamma, float64(i)) / (d.gamma + 1)
+}
+
+// Size returns the number of values in the sketch.
+func (d *DDSketch) Size() int {
+	return d.positive.count + d.negative.count + d.zeroCount
+}
+
+// Clear resets the sketch.
+func (d *DDSketch) Clear() {
+	d.positive.clear()
+	d.negative.clear()
+	d.zeroCount = 0
+}
+
+// Add inserts a value into the sketch.
+func (d *DDSketch) Add(val float64) {
+	switch {
+	case val > 0:
+		d.positive.add(d.index(val), 1)
+	case val < 0:
+		d.negative.add(d.index(-val), 1)
+	default:
+		d.zeroCount++
+	}
+}
+
+// Remove deletes a value from the sketch; since values are only tracked by
+// their buckets, this removes a value from the bucket that the value belongs to.
+func (d *DDSketch) Remove(val float64) {
+	switch {
+	case val > 0:
+		d.positive.remove(d.index(val))
+	case val < 0:
+		d.negative.remove(d.index(-val))
+	default:
+		if d.zeroCount > 0 {
+			d.zeroCount--
+		}
+	}
+}
+
+// Select returns a node whose value approximates the kth smallest value in the
+// sketch, to within the relative accuracy of the sketch.
+func (d *DDSketch) Select(k int) order.Node {
+	if k < 0 || k >= d.Size() {
+		return nil
+	}
+
+	values, counts := d.cumulative()
+	return Node(selectFrom(values, counts, k))
+}
+
+// cumulative returns the representative values of the nonempty buckets of the
+// sketch in ascending order, along with the cumulative counts of the buckets
+// up to and including each bucket.
+func (d *DDSketch) cumulative() (values []float64, counts []int) {
+	total := 0
+
+	// negative values are ordered by descending magnitude
+	keys := d.negative.keys()
+	for i := len(keys) - 1; i >= 0; i-- {
+		total += d.negative.bins[keys[i]]
+		values = append(values, -d.value(keys[i]))
+		counts = append(counts, total)
+	}
+
+	if d.zeroCount > 0 {
+		total += d.zeroCount
+		values = append(values, 0)
+		counts = append(counts, total)
+	}
+
+	for _, key := range d.positive.keys() {
+		total += d.positive.bins[key]
+		values = append(values, d.value(key))
+		counts = append(counts, total)
+	}
+
+	return values, counts
+}
+
+// selectFrom returns the kth smallest value from the cumulative counts of the sketch.
+func selectFrom(values []float64, counts []int, k int) float64 {
+	i := sort.Search(len(counts), func(i int) bool {
+		return counts[i] > k
+	})
+	return values[i]
+}
+
+// Rank returns the approximate number of values strictly less than the given value;
+// in particular, it counts the values whose buckets have representative values
+// strictly less than the given value, which is consistent with Select.
+func (d *DDSketch) Rank(val float64) int {
+	return d.rank(val, false)
+}
+
+// rank counts the values whose buckets have representative values less than
+// the given value, including those equal to it if inclusive is set.
+func (d *DDSketch) rank(val float64, inclusive bool) int {
+	values, counts := d.cumulative()
+	i := sort.Search(len(values), func(i int) bool {
+		if inclusive {
+			return values[i] > val
+		}
+		return values[i] >= val
+	})
+
+	if i == 0 {
+		return 0
+	}
+	return counts[i-1]
+}
+
+// Value returns the approximate value of the quantile q of the sketch, linearly
+// interpolating between the two nearest values if q lies between them.
+func (d *DDSketch) Value(q float64) (float64, error) {
+	values, err := d.Values(q)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// Values returns the approximate values of multiple quantiles of the sketch, in the
+// order that they were provided; this only sorts the buckets of the sketch once, and
+// so is more efficient than calling Value for each quantile.
+func (d *DDSketch) Values(qs ...float64) ([]float64, error) {
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			return nil, errors.Errorf("quantile %f not in [0, 1]", q)
+		}
+	}
+
+	size := d.Size()
+	if size == 0 {
+		return nil, errors.New("no values seen yet")
+	}
+
+	values, counts := d.cumulative()
+	result := make([]float64, len(qs))
+	for i, q := range qs {
+		idx := q * float64(size-1)
+		lower := selectFrom(values, counts, int(math.Floor(idx)))
+		upper := selectFrom(values, counts, int(math.Ceil(idx)))
+		frac := idx - math.Floor(idx)
+		result[i] = (1-frac)*lower + frac*upper
+	}
+
+	return result, nil
+}
+
+// CDF returns the approximate fraction of values in the sketch that are less than
+// or equal to the given value.
+func (d *DDSketch) CDF(val float64) (float64, error) {
+	size := d.Size()
+	if size == 0 {
+		return 0, errors.New("no values seen yet")
+	}
+
+	return float64(d.rank(val, true)) / float64(size), nil
+}
+
+// Merge merges the values of another sketch into the sketch, so that the sketch
+// summarizes the values of both sketches; the other sketch is unchanged.
+// Both sketches must have the same relative accuracy.
+func (d *DDSketch) Merge(other *DDSketch) error {
+	if d.alpha != other.alpha {
+		return errors.Errorf(
+			"cannot merge DDSketch with relative accuracy %f into DDSketch with relative accuracy %f",
+			other.alpha,
+			d.alpha,
+		)
+	}
+
+	for _, pair := range [][2]*store{{d.negative, other.negative}, {d.positive, other.positive}} {
+		// copy the buckets first, which allows for a sketch to be merged with itself
+		bins := make(map[int]int, len(pair[1].bins))
+		for i, n := range pair[1].bins {
+			bins[i] = n
+		}
+		for i, n := range bins {
+			pair[0].add(i, n)
+		}
+	}
+	d.zeroCount += other.zeroCount
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package ddsketch
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
+		_, err := New(RelativeAccuracyOption(-1))
+		testutil.ContainsError(t, err, "error setting option")
+	})
+
+	t.Run("pass: no options is valid", func(t *testing.T) {
+		d, err := New()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultRelativeAccuracy, d.RelativeAccuracy())
+	})
+
+	t.Run("pass: valid Options are valid", func(t *testing.T) {
+		_, err := New(RelativeAccuracyOption(0.05), MaxBinsOption(128))
+		require.NoError(t, err)
+	})
+}
+
+func TestSelect(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	xs := make([]float64, 2000)
+	for i := range xs {
+		xs[i] = rng.NormFloat64() * 100
+		d.Add(xs[i])
+	}
+	d.Add(0)
+	xs = append(xs, 0)
+	sort.Float64s(xs)
+
+	require.Equal(t, len(xs), d.Size())
+	for k, x := range xs {
+		assert.InDelta(t, x, d.Select(k).Value(), DefaultRelativeAccuracy*math.Abs(x)+1e-12)
+	}
+
+	assert.Nil(t, d.Select(-1))
+	assert.Nil(t, d.Select(len(xs)))
+}
+
+func TestRank(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	for _, x := range []float64{-3, -2, -1, 0, 1, 2, 3} {
+		d.Add(x)
+	}
+
+	assert.Equal(t, 0, d.Rank(-4))
+	assert.Equal(t, 1, d.Rank(-2.5))
+	assert.Equal(t, 3, d.Rank(0))
+	assert.Equal(t, 4, d.Rank(0.5))
+	assert.Equal(t, 6, d.Rank(2.5))
+	assert.Equal(t, 7, d.Rank(4))
+
+	// ranks are consistent with the values returned by Select
+	for k := 0; k < d.Size(); k++ {
+		assert.Equal(t, k, d.Rank(d.Select(k).Value()))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	for _, x := range []float64{-1, 0, 1, 2, 3} {
+		d.Add(x)
+	}
+
+	d.Remove(2)
+	d.Remove(0)
+	d.Remove(-1)
+	// removing a value that was never added is a no-op
+	d.Remove(100)
+	d.Remove(-100)
+	d.Remove(0)
+
+	assert.Equal(t, 2, d.Size())
+	assert.InEpsilon(t, 1, d.Select(0).Value(), DefaultRelativeAccuracy)
+	assert.InEpsilon(t, 3, d.Select(1).Value(), DefaultRelativeAccuracy)
+
+	d.Clear()
+	assert.Equal(t, 0, d.Size())
+	assert.Nil(t, d.Select(0))
+}
+
+func TestCollapse(t *testing.T) {
+	d, err := New(MaxBinsOption(10))
+	require.NoError(t, err)
+
+	for i := 1; i <= 1000; i++ {
+		d.Add(float64(i))
+	}
+
+	assert.Len(t, d.positive.bins, 10)
+	assert.Equal(t, 1000, d.Size())
+	// the highest values are still within the relative accuracy
+	assert.InEpsilon(t, 1000, d.Select(999).Value(), DefaultRelativeAccuracy)
+
+	// values below the collapsed floor are removed from the floor bucket
+	d.Remove(1)
+	assert.Equal(t, 999, d.Size())
+}
+
+func TestValue(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	_, err = d.Value(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for i := 1; i <= 100; i++ {
+		d.Add(float64(i))
+	}
+
+	_, err = d.Value(1.5)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", 1.5))
+
+	for _, q := range []float64{0, 0.25, 0.5, 0.99, 1} {
+		val, err := d.Value(q)
+		require.NoError(t, err)
+		assert.InEpsilon(t, 1+q*99, val, DefaultRelativeAccuracy)
+	}
+}
+
+func TestCDF(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	_, err = d.CDF(0)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for _, x := range []float64{-2, -1, 0, 1, 2} {
+		d.Add(x)
+	}
+
+	for x, expected := range map[float64]float64{-3: 0, -1.5: 0.2, -0.5: 0.4, 0: 0.6, 1.5: 0.8, 2.5: 1} {
+		cdf, err := d.CDF(x)
+		require.NoError(t, err)
+		assert.Equal(t, expected, cdf)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("fail: differing relative accuracies cannot be merged", func(t *testing.T) {
+		d1, err := New()
+		require.NoError(t, err)
+		d2, err := New(RelativeAccuracyOption(0.05))
+		require.NoError(t, err)
+
+		err = d1.Merge(d2)
+		testutil.ContainsError(t, err, "cannot merge DDSketch with relative accuracy")
+	})
+
+	t.Run("pass: merged sketch matches sketch of all values", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		all, err := New()
+		require.NoError(t, err)
+		shards := make([]*DDSketch, 4)
+		for i := range shards {
+			shards[i], err = New()
+			require.NoError(t, err)
+			for j := 0; j < 1000; j++ {
+				x := rng.NormFloat64()
+				shards[i].Add(x)
+				all.Add(x)
+			}
+		}
+		shards[0].Add(0)
+		all.Add(0)
+
+		merged, err := New()
+		require.NoError(t, err)
+		for _, shard := range shards {
+			require.NoError(t, merged.Merge(shard))
+		}
+
+		assert.Equal(t, all.Size(), merged.Size())
+		for k := 0; k < all.Size(); k++ {
+			assert.Equal(t, all.Select(k).Value(), merged.Select(k).Value())
+		}
+	})
+
+	t.Run("pass: sketch can be merged with itself", func(t *testing.T) {
+		d, err := New()
+		require.NoError(t, err)
+		d.Add(1)
+		d.Add(0)
+
+		require.NoError(t, d.Merge(d))
+		assert.Equal(t, 4, d.Size())
+		assert.Equal(t, 2, d.Rank(0.5))
+	})
+}
+
+func TestValues(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	_, err = sketch.Values(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		sketch.Add(rng.Float64())
+	}
+
+	_, err = sketch.Values(0.5, -1)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", -1.))
+
+	qs := []float64{0.999, 0.5, 0.9, 0.95, 0.99}
+	values, err := sketch.Values(qs...)
+	require.NoError(t, err)
+	for i, q := range qs {
+		expected, err := sketch.Value(q)
+		require.NoError(t, err)
+		assert.Equal(t, expected, values[i])
+	}
+}
@@ -0,0 +1,13 @@
+// Package ddsketch provides the implementation for DDSketch, a quantile sketch
+// with relative-error guarantees on values.
+//
+// Values are counted in logarithmically sized buckets, so that any quantile
+// returned by the sketch is within a relative error of alpha (the relative
+// accuracy) of the exact quantile, i.e. |x' - x| <= alpha * |x|. Unlike most
+// sketches, values can also be removed from a DDSketch, which allows it to be
+// used over windows. Once the number of buckets exceeds the configured
+// maximum, the buckets closest to zero are collapsed into one another, and
+// the relative error guarantee no longer holds for values in those buckets.
+//
+// See https://arxiv.org/abs/1908.10693 for more details.
+package ddsketch
@@ -0,0 +1,175 @@
+package ddsketch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older sketches.
+const encodingVersion uint8 = 1
+
+// encodedBin is the binary representation of a bucket.
+type encodedBin struct {
+	Index int64
+	Count int64
+}
+
+// MarshalBinary encodes the sketch into a compact binary form, so that it
+// can be shipped elsewhere and restored with UnmarshalBinary (and potentially
+// merged into other sketches). Only the nonempty buckets are encoded.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (d *DDSketch) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, field := range []interface{}{
+		encodingVersion,
+		d.alpha,
+		int64(d.maxBins),
+		int64(d.zeroCount),
+	} {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding DDSketch")
+		}
+	}
+
+	for _, s := range []*store{d.negative, d.positive} {
+		err := s.encode(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding buckets")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the sketch from data produced by MarshalBinary,
+// replacing any state the sketch currently has.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (d *DDSketch) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var (
+		alpha     float64
+		maxBins   int64
+		zeroCount int64
+	)
+	for _, field := range []interface{}{&alpha, &maxBins, &zeroCount} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding DDSketch")
+		}
+	}
+
+	if alpha <= 0 || alpha >= 1 {
+		return errors.Errorf("encoded DDSketch has a relative accuracy %f not in (0, 1)", alpha)
+	} else if maxBins < 2 {
+		return errors.Errorf("encoded DDSketch has max bins %d less than 2", maxBins)
+	} else if zeroCount < 0 {
+		return errors.Errorf("encoded DDSketch has a negative zero count of %d", zeroCount)
+	}
+
+	negative, err := decodeStore(r, int(maxBins))
+	if err != nil {
+		return errors.Wrap(err, "error decoding negative buckets")
+	}
+
+	positive, err := decodeStore(r, int(maxBins))
+	if err != nil {
+		return errors.Wrap(err, "error decoding positive buckets")
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded DDSketch has %d trailing bytes", r.Len())
+	}
+
+	d.alpha = alpha
+	d.maxBins = int(maxBins)
+	d.gamma = (1 + alpha) / (1 - alpha)
+	d.logGamma = math.Log(d.gamma)
+	d.zeroCount = int(zeroCount)
+	d.negative = negative
+	d.positive = positive
+
+	return nil
+}
+
+func (s *store) encode(buf *bytes.Buffer) error {
+	var collapsed uint8
+	if s.collapsed {
+		collapsed = 1
+	}
+
+	keys := s.keys()
+	bins := make([]encodedBin, len(keys))
+	for i, key := range keys {
+		bins[i] = encodedBin{Index: int64(key), Count: int64(s.bins[key])}
+	}
+
+	for _, field := range []interface{}{
+		collapsed,
+		int64(s.floor),
+		uint32(len(bins)),
+		bins,
+	} {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeStore(r *bytes.Reader, maxBins int) (*store, error) {
+	var (
+		collapsed uint8
+		floor     int64
+		numBins   uint32
+	)
+	for _, field := range []interface{}{&collapsed, &floor, &numBins} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if int(numBins) > maxBins {
+		return nil, errors.Errorf("encoded store has %d buckets for max bins of %d", numBins, maxBins)
+	} else if 16*int(numBins) > r.Len() {
+		return nil, errors.Errorf("encoded store has %d buckets but only %d bytes remaining", numBins, r.Len())
+	}
+
+	bins := make([]encodedBin, numBins)
+	err := binary.Read(r, binary.BigEndian, bins)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newStore(maxBins)
+	s.collapsed = collapsed != 0
+	s.floor = int(floor)
+	for _, bin := range bins {
+		if bin.Count <= 0 {
+			return nil, errors.Errorf("encoded store has a nonpositive count of %d", bin.Count)
+		} else if _, ok := s.bins[int(bin.Index)]; ok {
+			return nil, errors.Errorf("encoded store has a duplicate bucket %d", bin.Index)
+		}
+		s.bins[int(bin.Index)] = int(bin.Count)
+		s.count += int(bin.Count)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,73 @@
+package ddsketch
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	t.Run("pass: restored sketch matches original", func(t *testing.T) {
+		d, err := New(RelativeAccuracyOption(0.02), MaxBinsOption(50))
+		require.NoError(t, err)
+
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			d.Add(rng.NormFloat64() * 1000)
+		}
+		d.Add(0)
+
+		data, err := d.MarshalBinary()
+		require.NoError(t, err)
+
+		restored := &DDSketch{}
+		err = restored.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, d.alpha, restored.alpha)
+		assert.Equal(t, d.maxBins, restored.maxBins)
+		assert.Equal(t, d.positive, restored.positive)
+		assert.Equal(t, d.negative, restored.negative)
+		assert.Equal(t, d.Size(), restored.Size())
+		for k := 0; k < d.Size(); k++ {
+			assert.Equal(t, d.Select(k).Value(), restored.Select(k).Value())
+		}
+
+		// the restored sketch can still be merged into sketches with the same accuracy
+		err = d.Merge(restored)
+		require.NoError(t, err)
+		assert.Equal(t, 2002, d.Size())
+	})
+
+	t.Run("fail: unsupported version is invalid", func(t *testing.T) {
+		err := (&DDSketch{}).UnmarshalBinary([]byte{0})
+		testutil.ContainsError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: truncated data is invalid", func(t *testing.T) {
+		d, err := New()
+		require.NoError(t, err)
+		d.Add(1)
+
+		data, err := d.MarshalBinary()
+		require.NoError(t, err)
+
+		err = (&DDSketch{}).UnmarshalBinary(data[:len(data)-1])
+		testutil.ContainsError(t, err, "error decoding positive buckets")
+	})
+
+	t.Run("fail: trailing bytes are invalid", func(t *testing.T) {
+		d, err := New()
+		require.NoError(t, err)
+
+		data, err := d.MarshalBinary()
+		require.NoError(t, err)
+
+		err = (&DDSketch{}).UnmarshalBinary(append(data, 0))
+		testutil.ContainsError(t, err, "encoded DDSketch has 1 trailing bytes")
+	})
+}
@@ -0,0 +1,42 @@
+package ddsketch
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+// RelativeAccuracyOption creates an option that sets the relative accuracy for a DDSketch.
+func RelativeAccuracyOption(alpha float64) order.Option {
+	return func(s order.Statistic) error {
+		var (
+			sketch *DDSketch
+			ok     bool
+		)
+		if sketch, ok = s.(*DDSketch); !ok {
+			return errors.New("attempted to set relative accuracy on a non-DDSketch")
+		} else if alpha <= 0 || alpha >= 1 {
+			return errors.Errorf("attempted to set relative accuracy %f not in (0, 1)", alpha)
+		}
+		sketch.alpha = alpha
+		return nil
+	}
+}
+
+// MaxBinsOption creates an option that sets the maximum number of buckets kept
+// for each sign of values in a DDSketch.
+func MaxBinsOption(maxBins int) order.Option {
+	return func(s order.Statistic) error {
+		var (
+			sketch *DDSketch
+			ok     bool
+		)
+		if sketch, ok = s.(*DDSketch); !ok {
+			return errors.New("attempted to set max bins on a non-DDSketch")
+		} else if maxBins < 2 {
+			return errors.Errorf("attempted to set max bins %d less than 2", maxBins)
+		}
+		sketch.maxBins = maxBins
+		return nil
+	}
+}
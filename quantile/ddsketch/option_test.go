@@ -0,0 +1,70 @@
+package ddsketch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream/quantile/ost/rb"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestRelativeAccuracyOption(t *testing.T) {
+	t.Run("fail: non-DDSketch is invalid", func(t *testing.T) {
+		err := RelativeAccuracyOption(0.01)(&rb.Tree{})
+		testutil.ContainsError(t, err, "attempted to set relative accuracy on a non-DDSketch")
+	})
+
+	t.Run("fail: relative accuracy <= 0 is invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = RelativeAccuracyOption(0.)(sketch)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set relative accuracy %f not in (0, 1)", 0.))
+	})
+
+	t.Run("fail: relative accuracy >= 1 is invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = RelativeAccuracyOption(1.)(sketch)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set relative accuracy %f not in (0, 1)", 1.))
+	})
+
+	t.Run("pass: valid relative accuracy is set", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = RelativeAccuracyOption(0.05)(sketch)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.05, sketch.alpha)
+	})
+}
+
+func TestMaxBinsOption(t *testing.T) {
+	t.Run("fail: non-DDSketch is invalid", func(t *testing.T) {
+		err := MaxBinsOption(10)(&rb.Tree{})
+		testutil.ContainsError(t, err, "attempted to set max bins on a non-DDSketch")
+	})
+
+	t.Run("fail: max bins < 2 is invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = MaxBinsOption(1)(sketch)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set max bins %d less than 2", 1))
+	})
+
+	t.Run("pass: valid max bins is set", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = MaxBinsOption(10)(sketch)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, sketch.maxBins)
+	})
+}
@@ -0,0 +1,80 @@
+package ddsketch
+
+import (
+	"sort"
+)
+
+// store keeps the counts of the buckets for values of one sign.
+type store struct {
+	bins    map[int]int
+	count   int
+	maxBins int
+	// if collapsed, every index below floor is counted in the floor bucket
+	collapsed bool
+	floor     int
+}
+
+func newStore(maxBins int) *store {
+	return &store{
+		bins:    map[int]int{},
+		maxBins: maxBins,
+	}
+}
+
+func (s *store) index(i int) int {
+	if s.collapsed && i < s.floor {
+		return s.floor
+	}
+	return i
+}
+
+func (s *store) add(i int, n int) {
+	s.bins[s.index(i)] += n
+	s.count += n
+
+	for len(s.bins) > s.maxBins {
+		s.collapse()
+	}
+}
+
+func (s *store) remove(i int) bool {
+	i = s.index(i)
+	n, ok := s.bins[i]
+	if !ok {
+		return false
+	}
+
+	if n == 1 {
+		delete(s.bins, i)
+	} else {
+		s.bins[i] = n - 1
+	}
+	s.count--
+	return true
+}
+
+// collapse merges the two lowest buckets.
+func (s *store) collapse() {
+	keys := s.keys()
+	s.bins[keys[1]] += s.bins[keys[0]]
+	delete(s.bins, keys[0])
+	s.collapsed = true
+	s.floor = keys[1]
+}
+
+// keys returns the indices of the nonempty buckets in ascending order.
+func (s *store) keys() []int {
+	keys := make([]int, 0, len(s.bins))
+	for k := range s.bins {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func (s *store) clear() {
+	s.bins = map[int]int{}
+	s.count = 0
+	s.collapsed = false
+	s.floor = 0
+}
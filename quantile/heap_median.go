@@ -3,11 +3,15 @@ package quantile
 import (
 	heapops "container/heap"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
 
+	"github.com/alexander-yu/stream"
 	"github.com/alexander-yu/stream/quantile/heap"
 )
 
@@ -17,9 +21,19 @@ type HeapMedian struct {
 	lowHeap  *heap.Heap
 	highHeap *heap.Heap
 	queue    *queue.RingBuffer
+	duration time.Duration
+	clock    stream.Clock
+	timed    *deque.Deque[timedItem]
+	latest   time.Time
 	mux      sync.Mutex
 }
 
+// timedItem is a heap item in a time-based window, along with the time it was pushed at.
+type timedItem struct {
+	item *heap.Item
+	t    time.Time
+}
+
 func fmax(x float64, y float64) bool {
 	return x > y
 }
@@ -39,6 +53,31 @@ func NewHeapMedian(window int) (*HeapMedian, error) {
 		lowHeap:  heap.New("low", []float64{}, fmax),
 		highHeap: heap.New("high", []float64{}, fmin),
 		queue:    queue.NewRingBuffer(uint64(window)),
+		timed:    new(deque.Deque[timedItem]),
+	}, nil
+}
+
+// NewTimedHeapMedian instantiates a HeapMedian struct that tracks values over
+// a time-based window, where values are removed once they are at least the
+// provided duration older than the current time of the provided Clock, which
+// defaults to stream.SystemClock if nil. Values pushed without an explicit
+// time are timestamped with the Clock as well.
+func NewTimedHeapMedian(duration time.Duration, clock stream.Clock) (*HeapMedian, error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive duration", duration)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return &HeapMedian{
+		lowHeap:  heap.New("low", []float64{}, fmax),
+		highHeap: heap.New("high", []float64{}, fmin),
+		queue:    queue.NewRingBuffer(uint64(0)),
+		duration: duration,
+		clock:    clock,
+		timed:    new(deque.Deque[timedItem]),
 	}, nil
 }
 
@@ -50,14 +89,18 @@ func NewGlobalHeapMedian() *HeapMedian {
 		lowHeap:  heap.New("low", []float64{}, fmax),
 		highHeap: heap.New("high", []float64{}, fmin),
 		queue:    queue.NewRingBuffer(uint64(0)),
+		timed:    new(deque.Deque[timedItem]),
 	}
 }
 
 // String returns a string representation of the metric.
 func (m *HeapMedian) String() string {
 	name := "quantile.HeapMedian"
-	window := fmt.Sprintf("window:%v", m.window)
-	return fmt.Sprintf("%s_{%s}", name, window)
+	params := []string{fmt.Sprintf("window:%v", m.window)}
+	if m.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", m.duration))
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
 }
 
 // Push adds a number for calculating the median.
@@ -65,6 +108,30 @@ func (m *HeapMedian) Push(x float64) error {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
+	var t time.Time
+	if m.duration != 0 {
+		t = m.clock.Now()
+	}
+	return m.push(x, t)
+}
+
+// PushAt adds a number for calculating the median, which was observed at
+// the provided time. This is only meaningful if the HeapMedian tracks values
+// over a time-based window (see NewTimedHeapMedian), in which case values at
+// or before t - duration are removed from the window; otherwise the time is
+// ignored. Values must be pushed in chronological order, on the timeline of
+// the Clock (see stream.Clock).
+func (m *HeapMedian) PushAt(x float64, t time.Time) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.push(x, t)
+}
+
+func (m *HeapMedian) push(x float64, t time.Time) error {
+	if m.duration != 0 {
+		return m.pushTimed(x, t)
+	}
+
 	var item *heap.Item
 	// if queue is full, we need to remove old item
 	if m.window != 0 && m.queue.Len() == uint64(m.window) {
@@ -94,11 +161,7 @@ func (m *HeapMedian) Push(x float64) error {
 		item = m.rebalance(item)
 	} else {
 		item = &heap.Item{Val: x}
-		if m.lowHeap.Len() == 0 || x <= m.lowHeap.Peek() {
-			heapops.Push(m.lowHeap, item)
-		} else {
-			heapops.Push(m.highHeap, item)
-		}
+		m.insert(item)
 		item = m.rebalance(item)
 	}
 
@@ -112,6 +175,54 @@ func (m *HeapMedian) Push(x float64) error {
 	return nil
 }
 
+func (m *HeapMedian) pushTimed(x float64, t time.Time) error {
+	if t.Before(m.latest) {
+		return errors.Errorf("time %v is before the latest time %v", t, m.latest)
+	}
+
+	m.expire(t)
+
+	item := &heap.Item{Val: x}
+	m.insert(item)
+	m.rebalance(item)
+
+	m.timed.PushBack(timedItem{item: item, t: t})
+	m.latest = t
+	return nil
+}
+
+// expire removes all values at or before t - duration from a time-based window.
+func (m *HeapMedian) expire(t time.Time) {
+	cutoff := t.Add(-m.duration)
+	for m.timed.Len() > 0 && !m.timed.Front().t.After(cutoff) {
+		item := m.timed.PopFront().item
+		if item.HeapID == m.lowHeap.ID {
+			m.lowHeap.Remove(item)
+		} else {
+			m.highHeap.Remove(item)
+		}
+		m.rebalance(item)
+	}
+}
+
+// insert pushes an item to the heap that keeps every value in the low heap
+// at most every value in the high heap; if the low heap is empty (which can
+// happen after values are removed), the high heap may still have a value.
+func (m *HeapMedian) insert(item *heap.Item) {
+	var low bool
+	if m.lowHeap.Len() > 0 {
+		low = item.Val <= m.lowHeap.Peek()
+	} else {
+		low = m.highHeap.Len() == 0 || item.Val <= m.highHeap.Peek()
+	}
+
+	if low {
+		heapops.Push(m.lowHeap, item)
+	} else {
+		heapops.Push(m.highHeap, item)
+	}
+}
+
 func (m *HeapMedian) rebalance(item *heap.Item) *heap.Item {
 	if m.lowHeap.Len()+1 < m.highHeap.Len() {
 		item = heapops.Pop(m.highHeap).(*heap.Item)
@@ -123,11 +234,16 @@ func (m *HeapMedian) rebalance(item *heap.Item) *heap.Item {
 	return item
 }
 
-// Value returns the value of the median.
+// Value returns the value of the median. If the HeapMedian tracks values over
+// a time-based window, expired values are removed first.
 func (m *HeapMedian) Value() (float64, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
+	if m.duration != 0 {
+		m.expire(m.clock.Now())
+	}
+
 	if m.lowHeap.Len()+m.highHeap.Len() == 0 {
 		return 0, errors.New("no values seen yet")
 	}
@@ -153,4 +269,6 @@ func (m *HeapMedian) Clear() {
 	m.queue = queue.NewRingBuffer(uint64(m.window))
 	m.lowHeap = heap.New("low", []float64{}, fmax)
 	m.highHeap = heap.New("high", []float64{}, fmin)
+	m.timed.Clear()
+	m.latest = time.Time{}
 }
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,6 +27,19 @@ func TestNewHeapMedian(t *testing.T) {
 	})
 }
 
+func TestNewTimedHeapMedian(t *testing.T) {
+	t.Run("pass: returns a HeapMedian", func(t *testing.T) {
+		median, err := NewTimedHeapMedian(time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "quantile.HeapMedian_{window:0,duration:1m0s}", median.String())
+	})
+
+	t.Run("fail: nonpositive duration is invalid", func(t *testing.T) {
+		_, err := NewTimedHeapMedian(0, nil)
+		assert.EqualError(t, err, "0s is a nonpositive duration")
+	})
+}
+
 func TestNewGlobalHeapMedian(t *testing.T) {
 	median, err := NewHeapMedian(0)
 	require.NoError(t, err)
@@ -134,6 +149,102 @@ func TestHeapMedianPush(t *testing.T) {
 	})
 }
 
+func TestHeapMedianPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: matches median of values within duration", func(t *testing.T) {
+		rand.Seed(1)
+		clock := testutil.NewClock(start)
+		median, err := NewTimedHeapMedian(10*time.Second, clock)
+		require.NoError(t, err)
+
+		type timedValue struct {
+			x float64
+			t time.Time
+		}
+		values := []timedValue{}
+		now := start
+		for i := 0; i < 200; i++ {
+			delta := time.Duration(rand.Intn(3000)) * time.Millisecond
+			now = now.Add(delta)
+			clock.Advance(delta)
+			x := float64(rand.Intn(100))
+			err := median.PushAt(x, now)
+			require.NoError(t, err)
+
+			values = append(values, timedValue{x: x, t: now})
+			window := []float64{}
+			for _, value := range values {
+				if value.t.After(now.Add(-10 * time.Second)) {
+					window = append(window, value.x)
+				}
+			}
+			sort.Float64s(window)
+
+			n := len(window)
+			expected := window[n/2]
+			if n%2 == 0 {
+				expected = (window[n/2-1] + window[n/2]) / 2
+			}
+
+			actual, err := median.Value()
+			require.NoError(t, err)
+			testutil.Approx(t, expected, actual)
+			assert.Equal(t, n, median.lowHeap.Len()+median.highHeap.Len())
+		}
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		median, err := NewTimedHeapMedian(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, x := range []float64{10, 1, 2} {
+			err := median.Push(x)
+			require.NoError(t, err)
+			clock.Advance(20 * time.Second)
+		}
+
+		// only the values pushed at 20s and 40s remain
+		value, err := median.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 1.5, value)
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		median, err := NewTimedHeapMedian(time.Minute, clock)
+		require.NoError(t, err)
+
+		for _, x := range []float64{10, 1, 2} {
+			err := median.Push(x)
+			require.NoError(t, err)
+			clock.Advance(20 * time.Second)
+		}
+
+		clock.Advance(30 * time.Second)
+		value, err := median.Value()
+		require.NoError(t, err)
+		testutil.Approx(t, 2., value)
+
+		clock.Advance(time.Hour)
+		_, err = median.Value()
+		assert.EqualError(t, err, "no values seen yet")
+		assert.Equal(t, 0, median.timed.Len())
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		median, err := NewTimedHeapMedian(time.Minute, nil)
+		require.NoError(t, err)
+
+		err = median.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = median.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
 func TestHeapMedianValue(t *testing.T) {
 	t.Run("pass: if low heap is larger, return its top", func(t *testing.T) {
 		median, err := NewHeapMedian(10)
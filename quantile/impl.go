@@ -3,10 +3,14 @@ package quantile
 import (
 	"github.com/pkg/errors"
 
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/quantile/ddsketch"
+	"github.com/alexander-yu/stream/quantile/kll"
 	"github.com/alexander-yu/stream/quantile/order"
 	"github.com/alexander-yu/stream/quantile/ost/avl"
 	"github.com/alexander-yu/stream/quantile/ost/rb"
 	"github.com/alexander-yu/stream/quantile/skiplist"
+	"github.com/alexander-yu/stream/quantile/tdigest"
 )
 
 // Impl represents an enum that enumerates the currently supported implementations
@@ -20,12 +24,21 @@ const (
 	RedBlack
 	// SkipList represents the skip list implementation for the order.Statistic interface
 	SkipList
+	// TDigest represents the t-digest sketch implementation for the order.Statistic interface;
+	// quantiles are approximate, with the most accurate quantiles near the tails
+	TDigest
+	// KLL represents the KLL sketch implementation for the order.Statistic interface;
+	// quantiles are approximate, with a rank error of roughly 1.65% for the default k
+	KLL
+	// DDSketch represents the DDSketch implementation for the order.Statistic interface;
+	// quantiles are approximate, with a relative error of 1% for the default relative accuracy
+	DDSketch
 )
 
 // Valid returns whether or not the Impl value is a valid value.
 func (i Impl) Valid() bool {
 	switch i {
-	case AVL, RedBlack, SkipList:
+	case AVL, RedBlack, SkipList, TDigest, KLL, DDSketch:
 		return true
 	default:
 		return false
@@ -42,7 +55,42 @@ func (i Impl) init(options ...order.Option) (order.Statistic, error) {
 		return &rb.Tree{}, nil
 	case SkipList:
 		return skiplist.New(options...)
+	case TDigest:
+		return tdigest.New(options...)
+	case KLL:
+		return kll.New(options...)
+	case DDSketch:
+		return ddsketch.New(options...)
 	default:
 		return nil, errors.Errorf("%v is not a supported Impl value", i)
 	}
 }
+
+// removable returns whether or not the implementation supports removing values,
+// which is required for tracking values over a window.
+func (i Impl) removable() bool {
+	switch i {
+	case TDigest, KLL:
+		return false
+	default:
+		return true
+	}
+}
+
+// initTyped returns an empty implementation over values of type T, which is the
+// provided float64 implementation if T is float64; only the order statistic trees
+// support values of other types.
+func initTyped[T stream.Number](i Impl, statistic order.Statistic) (order.TypedStatistic[T], error) {
+	if typed, ok := any(statistic).(order.TypedStatistic[T]); ok {
+		return typed, nil
+	}
+
+	switch i {
+	case AVL:
+		return &avl.TypedTree[T]{}, nil
+	case RedBlack:
+		return &rb.TypedTree[T]{}, nil
+	default:
+		return nil, errors.Errorf("Impl %d only supports float64 values", i)
+	}
+}
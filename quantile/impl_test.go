@@ -31,6 +31,24 @@ func TestImplInit(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("pass: t-digest implementation is supported", func(t *testing.T) {
+		i := TDigest
+		_, err := i.init()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pass: KLL implementation is supported", func(t *testing.T) {
+		i := KLL
+		_, err := i.init()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pass: DDSketch implementation is supported", func(t *testing.T) {
+		i := DDSketch
+		_, err := i.init()
+		assert.NoError(t, err)
+	})
+
 	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
 		i := SkipList
 		_, err := i.init(skiplist.ProbabilityOption(-1))
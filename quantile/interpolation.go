@@ -1,5 +1,11 @@
 package quantile
 
+import (
+	"math"
+
+	"github.com/alexander-yu/stream"
+)
+
 // Interpolation represents an enum that enumerates the
 // different interpolation methods that can be chosen
 // when retrieving quantile metrics. In particular,
@@ -40,3 +46,68 @@ func (i Interpolation) Valid() bool {
 		return false
 	}
 }
+
+// isFloat returns whether or not T is a floating-point type.
+func isFloat[T stream.Number]() bool {
+	half := 0.5
+	return T(half) != 0
+}
+
+// interpolate returns the value that lies at the fraction delta of the way from
+// lo to hi; for integer types, this is rounded to the nearest integer.
+func interpolate[T stream.Number](lo T, hi T, delta float64) T {
+	if isFloat[T]() {
+		return T(1-delta)*lo + T(delta)*hi
+	}
+
+	span := distance(lo, hi)
+	offset := math.Round(delta * span)
+	if offset >= span {
+		return hi
+	}
+	// the offset is less than 2^64, and adding it with wraparound gives
+	// the exact result even if it does not fit in T by itself
+	return lo + T(uint64(offset))
+}
+
+// midpoint returns the average of lo and hi, where lo is at most hi;
+// for integer types, this is rounded down.
+func midpoint[T stream.Number](lo T, hi T) T {
+	if isFloat[T]() {
+		return (lo + hi) / 2
+	}
+
+	// halve each value first so that nothing overflows, and then add back
+	// the halves of their remainders, rounded down
+	remainders := (lo - lo/2*2) + (hi - hi/2*2)
+	if remainders < 0 {
+		return lo/2 + hi/2 + (remainders-1)/2
+	}
+	return lo/2 + hi/2 + remainders/2
+}
+
+// distance returns hi - lo as a float64, where lo is at most hi; for signed
+// integer types, hi - lo overflows when lo and hi are far enough apart,
+// in which case the difference is taken after converting them to float64.
+func distance[T stream.Number](lo T, hi T) float64 {
+	if d := hi - lo; d >= 0 {
+		return float64(d)
+	}
+	return float64(hi) - float64(lo)
+}
+
+// successor returns the smallest value of type T that is greater than x,
+// along with whether or not such a value exists.
+func successor[T stream.Number](x T) (T, bool) {
+	if !isFloat[T]() {
+		next := x + 1
+		return next, next > x
+	}
+
+	next := T(math.Nextafter(float64(x), math.Inf(1)))
+	if next == x {
+		// the successor of a float32 value may round back to the value itself
+		next = T(math.Nextafter32(float32(x), float32(math.Inf(1))))
+	}
+	return next, next > x
+}
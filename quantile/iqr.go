@@ -2,6 +2,7 @@ package quantile
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -43,22 +44,34 @@ func (i *IQR) Push(x float64) error {
 	return nil
 }
 
+// PushAt adds a number for calculating the interquartile range, which was observed
+// at the provided time; see Quantile.PushAt for details.
+func (i *IQR) PushAt(x float64, t time.Time) error {
+	err := i.quantile.PushAt(x, t)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantile", x)
+	}
+	return nil
+}
+
 // Value returns the value of the interquartile range.
 func (i *IQR) Value() (float64, error) {
-	i.quantile.RLock()
-	defer i.quantile.RUnlock()
+	s := i.quantile.source()
+	s.rlock()
+	defer s.mux.RUnlock()
 
-	q25, err := i.quantile.Value(0.25)
-	if err != nil {
-		return 0, errors.Wrap(err, "error retrieving 1st quartile")
-	}
+	return i.value(s)
+}
 
-	q75, err := i.quantile.Value(0.75)
+// value returns the value of the interquartile range from the provided source
+// of its Quantile, without locking.
+func (i *IQR) value(s *Quantile) (float64, error) {
+	quartiles, err := i.quantile.values(s, 0.25, 0.75)
 	if err != nil {
-		return 0, errors.Wrap(err, "error retrieving 3rd quartile")
+		return 0, errors.Wrap(err, "error retrieving quartiles")
 	}
 
-	return q75 - q25, nil
+	return quartiles[1] - quartiles[0], nil
 }
 
 // Clear resets the metric.
@@ -0,0 +1,13 @@
+// Package kll provides the implementation for KLL sketches, a randomized
+// quantile sketch with rank-error guarantees.
+//
+// A KLL sketch keeps a hierarchy of compactors, where each value at level h
+// represents 2^h values of the stream; when a compactor fills up, it is sorted
+// and a random half of its values are promoted to the next level. With the
+// default k of 200, the rank of any returned quantile is within roughly 1.65%
+// of n of the exact rank with 99% confidence, using O(k) space; the error
+// decreases proportionally to 1/k. Values cannot be removed from a KLL sketch,
+// so it cannot be used over windows.
+//
+// See https://arxiv.org/abs/1603.05346 for more details.
+package kll
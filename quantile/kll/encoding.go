@@ -0,0 +1,117 @@
+package kll
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older sketches.
+const encodingVersion uint8 = 1
+
+// MarshalBinary encodes the sketch into a compact binary form, so that it
+// can be shipped elsewhere and restored with UnmarshalBinary (and potentially
+// merged into other sketches). The rand source of the sketch is not encoded.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (s *KLL) MarshalBinary() ([]byte, error) {
+	fields := []interface{}{
+		encodingVersion,
+		int64(s.k),
+		int64(s.count),
+		uint32(len(s.compactors)),
+	}
+	for _, compactor := range s.compactors {
+		fields = append(fields, uint32(len(compactor)), compactor)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, field := range fields {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding KLL")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the sketch from data produced by MarshalBinary,
+// replacing any state the sketch currently has. If the sketch does not have
+// a rand source set already, a new one is seeded with the current time.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (s *KLL) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var (
+		k         int64
+		count     int64
+		numLevels uint32
+	)
+	for _, field := range []interface{}{&k, &count, &numLevels} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding KLL")
+		}
+	}
+
+	if k < 8 {
+		return errors.Errorf("encoded KLL has k %d less than 8", k)
+	} else if numLevels < 1 || numLevels > 64 {
+		return errors.Errorf("encoded KLL has %d levels, which is not in [1, 64]", numLevels)
+	}
+
+	compactors := make([][]float64, numLevels)
+	var (
+		size   int
+		weight int64
+	)
+	for h := range compactors {
+		var length uint32
+		err := binary.Read(r, binary.BigEndian, &length)
+		if err != nil {
+			return errors.Wrapf(err, "error decoding size of level %d", h)
+		} else if 8*int(length) > r.Len() {
+			return errors.Errorf("encoded KLL has %d values at level %d but only %d bytes remaining", length, h, r.Len())
+		}
+
+		compactors[h] = make([]float64, length)
+		err = binary.Read(r, binary.BigEndian, compactors[h])
+		if err != nil {
+			return errors.Wrapf(err, "error decoding values of level %d", h)
+		}
+
+		size += int(length)
+		weight += int64(length) << uint(h)
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded KLL has %d trailing bytes", r.Len())
+	} else if weight != count {
+		return errors.Errorf("encoded KLL has a count of %d but a total weight of %d", count, weight)
+	}
+
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	s.k = int(k)
+	s.compactors = compactors
+	s.resize()
+	s.size = size
+	s.count = int(count)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package kll
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	t.Run("pass: restored sketch matches original", func(t *testing.T) {
+		sketch, err := New(KOption(50), RandOption(rand.New(rand.NewSource(1))))
+		require.NoError(t, err)
+
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			sketch.Add(rng.NormFloat64())
+		}
+
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+
+		restored := &KLL{}
+		err = restored.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, sketch.k, restored.k)
+		assert.Equal(t, sketch.compactors, restored.compactors)
+		assert.Equal(t, sketch.size, restored.size)
+		assert.Equal(t, sketch.maxSize, restored.maxSize)
+		assert.Equal(t, sketch.Size(), restored.Size())
+		for _, q := range []float64{0, 0.1, 0.5, 0.9, 1} {
+			expected, err := sketch.Value(q)
+			require.NoError(t, err)
+			actual, err := restored.Value(q)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+
+		// the restored sketch can keep accepting values
+		restored.Add(1)
+		assert.Equal(t, 5001, restored.Size())
+	})
+
+	t.Run("fail: unsupported version is invalid", func(t *testing.T) {
+		err := (&KLL{}).UnmarshalBinary([]byte{0})
+		testutil.ContainsError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: mismatched count is invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+		sketch.Add(1)
+
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+		// the count is encoded right after the version and k
+		data[16]++
+
+		err = (&KLL{}).UnmarshalBinary(data)
+		testutil.ContainsError(t, err, "encoded KLL has a count of 2 but a total weight of 1")
+	})
+
+	t.Run("fail: trailing bytes are invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+
+		err = (&KLL{}).UnmarshalBinary(append(data, 0))
+		testutil.ContainsError(t, err, "encoded KLL has 1 trailing bytes")
+	})
+}
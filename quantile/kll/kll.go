@@ -0,0 +1,286 @@
+package kll
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+const (
+	// DefaultK is the default parameter k for a KLL sketch
+	DefaultK int = 200
+	// capacityRatio is the ratio between the capacities of consecutive compactors
+	capacityRatio float64 = 2. / 3.
+)
+
+// Node represents an approximate value in a KLL sketch.
+type Node float64
+
+// Value returns the value stored at the node.
+func (n Node) Value() float64 {
+	return float64(n)
+}
+
+// KLL implements a KLL sketch, and also satisfies the
+// order.Statistic interface.
+type KLL struct {
+	k          int
+	rand       *rand.Rand
+	compactors [][]float64
+	size       int
+	maxSize    int
+	count      int
+}
+
+// New instantiates a KLL struct.
+func New(options ...order.Option) (*KLL, error) {
+	s := &KLL{
+		k:    DefaultK,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, option := range options {
+		err := option(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting option")
+		}
+	}
+
+	s.Clear()
+	return s, nil
+}
+
+// K returns the parameter k of the sketch.
+func (s *KLL) K() int {
+	return s.k
+}
+
+// capacity returns the capacity of the compactor at level h; the capacities
+// shrink geometrically with depth below the top level.
+func (s *KLL) capacity(h int) int {
+	depth := len(s.compactors) - h - 1
+	return int(math.Ceil(math.Pow(capacityRatio, float64(depth))*float64(s.k))) + 1
+}
+
+func (s *KLL) grow() {
+	s.compactors = append(s.compactors, nil)
+	s.resize()
+}
+
+// resize recomputes the maximum size of the sketch from the capacities of its compactors.
+func (s *KLL) resize() {
+	s.maxSize = 0
+	for h := range s.compactors {
+		s.maxSize += s.capacity(h)
+	}
+}
+
+// Size returns the number of values seen by the sketch.
+func (s *KLL) Size() int {
+	return s.count
+}
+
+// Clear resets the sketch.
+func (s *KLL) Clear() {
+	s.compactors = nil
+	s.size = 0
+	s.count = 0
+	s.grow()
+}
+
+// Add inserts a value into the sketch.
+func (s *KLL) Add(val float64) {
+	s.compactors[0] = append(s.compactors[0], val)
+	s.size++
+	s.count++
+
+	if s.size >= s.maxSize {
+		s.compress()
+	}
+}
+
+// Remove is a no-op, since values cannot be removed from a KLL sketch.
+func (s *KLL) Remove(val float64) {}
+
+func (s *KLL) compress() {
+	for h := 0; h < len(s.compactors); h++ {
+		if len(s.compactors[h]) >= s.capacity(h) {
+			if h+1 >= len(s.compactors) {
+				s.grow()
+			}
+			s.compact(h)
+
+			s.size = 0
+			for _, compactor := range s.compactors {
+				s.size += len(compactor)
+			}
+			if s.size < s.maxSize {
+				break
+			}
+		}
+	}
+}
+
+// compact sorts the compactor at level h and promotes either its odd or even
+// values to the next level, which preserves the total weight of the sketch;
+// if the compactor has an odd number of values, its smallest value is kept.
+func (s *KLL) compact(h int) {
+	values := s.compactors[h]
+	sort.Float64s(values)
+
+	var kept []float64
+	if len(values)%2 == 1 {
+		kept = append(kept, values[0])
+		values = values[1:]
+	}
+
+	for i := s.rand.Intn(2); i < len(values); i += 2 {
+		s.compactors[h+1] = append(s.compactors[h+1], values[i])
+	}
+	s.compactors[h] = kept
+}
+
+// cumulative returns the values in the sketch in sorted order, along with
+// the cumulative weights of the values up to and including each value.
+func (s *KLL) cumulative() (values []float64, weights []int) {
+	type weighted struct {
+		val    float64
+		weight int
+	}
+
+	items := make([]weighted, 0, s.size)
+	for h, compactor := range s.compactors {
+		for _, val := range compactor {
+			items = append(items, weighted{val: val, weight: 1 << uint(h)})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].val < items[j].val
+	})
+
+	values = make([]float64, len(items))
+	weights = make([]int, len(items))
+	total := 0
+	for i, item := range items {
+		total += item.weight
+		values[i] = item.val
+		weights[i] = total
+	}
+	return values, weights
+}
+
+// selectFrom returns the kth smallest value from the cumulative weights of the sketch.
+func selectFrom(values []float64, weights []int, k int) float64 {
+	i := sort.Search(len(weights), func(i int) bool {
+		return weights[i] > k
+	})
+	return values[i]
+}
+
+// Select returns a node whose value approximates the kth smallest value in the sketch.
+func (s *KLL) Select(k int) order.Node {
+	if k < 0 || k >= s.count {
+		return nil
+	}
+
+	values, weights := s.cumulative()
+	return Node(selectFrom(values, weights, k))
+}
+
+// Rank returns the approximate number of values strictly less than the given value.
+func (s *KLL) Rank(val float64) int {
+	rank := 0
+	for h, compactor := range s.compactors {
+		for _, x := range compactor {
+			if x < val {
+				rank += 1 << uint(h)
+			}
+		}
+	}
+
+	return rank
+}
+
+// Value returns the approximate value of the quantile q of the sketch, linearly
+// interpolating between the two nearest values if q lies between them.
+func (s *KLL) Value(q float64) (float64, error) {
+	values, err := s.Values(q)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// Values returns the approximate values of multiple quantiles of the sketch, in the
+// order that they were provided; this only sorts the values of the sketch once, and
+// so is more efficient than calling Value for each quantile.
+func (s *KLL) Values(qs ...float64) ([]float64, error) {
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			return nil, errors.Errorf("quantile %f not in [0, 1]", q)
+		}
+	}
+
+	if s.count == 0 {
+		return nil, errors.New("no values seen yet")
+	}
+
+	values, weights := s.cumulative()
+	result := make([]float64, len(qs))
+	for i, q := range qs {
+		idx := q * float64(s.count-1)
+		lower := selectFrom(values, weights, int(math.Floor(idx)))
+		upper := selectFrom(values, weights, int(math.Ceil(idx)))
+		frac := idx - math.Floor(idx)
+		result[i] = (1-frac)*lower + frac*upper
+	}
+
+	return result, nil
+}
+
+// CDF returns the approximate fraction of values in the sketch that are less than
+// or equal to the given value.
+func (s *KLL) CDF(val float64) (float64, error) {
+	if s.count == 0 {
+		return 0, errors.New("no values seen yet")
+	}
+
+	return float64(s.Rank(math.Nextafter(val, math.Inf(1)))) / float64(s.count), nil
+}
+
+// Merge merges the values of another sketch into the sketch, so that the sketch
+// summarizes the values of both sketches; the other sketch is unchanged.
+// Both sketches must have the same k.
+func (s *KLL) Merge(other *KLL) error {
+	if s.k != other.k {
+		return errors.Errorf("cannot merge KLL sketch with k of %d into KLL sketch with k of %d", other.k, s.k)
+	}
+
+	// copy the other sketch's compactors first, which allows for a sketch to be merged with itself
+	compactors := make([][]float64, len(other.compactors))
+	for h, compactor := range other.compactors {
+		compactors[h] = append([]float64{}, compactor...)
+	}
+	count := other.count
+
+	for len(s.compactors) < len(compactors) {
+		s.grow()
+	}
+	for h, compactor := range compactors {
+		s.compactors[h] = append(s.compactors[h], compactor...)
+		s.size += len(compactor)
+	}
+	s.count += count
+
+	for s.size >= s.maxSize {
+		s.compress()
+	}
+
+	return nil
+}
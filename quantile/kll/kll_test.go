@@ -0,0 +1,193 @@
+package kll
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
+		_, err := New(KOption(-1))
+		testutil.ContainsError(t, err, "error setting option")
+	})
+
+	t.Run("pass: no options is valid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultK, sketch.K())
+	})
+}
+
+func TestExact(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	// values are exact until the first compaction
+	for _, x := range []float64{5, 1, 3, 2, 4} {
+		sketch.Add(x)
+	}
+
+	assert.Equal(t, 5, sketch.Size())
+	for k := 0; k < 5; k++ {
+		assert.Equal(t, float64(k+1), sketch.Select(k).Value())
+		assert.Equal(t, k, sketch.Rank(float64(k+1)))
+	}
+	assert.Nil(t, sketch.Select(5))
+
+	// removing values is unsupported
+	sketch.Remove(1)
+	assert.Equal(t, 5, sketch.Size())
+
+	sketch.Clear()
+	assert.Equal(t, 0, sketch.Size())
+	assert.Nil(t, sketch.Select(0))
+}
+
+func TestAccuracy(t *testing.T) {
+	sketch, err := New(RandOption(rand.New(rand.NewSource(1))))
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	n := 100000
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = rng.NormFloat64()
+		sketch.Add(xs[i])
+	}
+	sort.Float64s(xs)
+
+	// the sketch uses O(k) space, and its total weight is preserved
+	assert.True(t, sketch.size < 4*DefaultK)
+	assert.Equal(t, n, sketch.Rank(xs[n-1]+1))
+
+	for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+		k := int(q * float64(n))
+		rank := sort.SearchFloat64s(xs, sketch.Select(k).Value())
+		assert.InDelta(t, q, float64(rank)/float64(n), 0.0165, "quantile %v", q)
+		assert.InDelta(t, q, float64(sketch.Rank(xs[k]))/float64(n), 0.0165, "rank of quantile %v", q)
+	}
+}
+
+func TestValue(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	_, err = sketch.Value(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for i := 1; i <= 5; i++ {
+		sketch.Add(float64(i))
+	}
+
+	_, err = sketch.Value(2)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", 2.))
+
+	for q, expected := range map[float64]float64{0: 1, 0.5: 3, 0.625: 3.5, 1: 5} {
+		val, err := sketch.Value(q)
+		require.NoError(t, err)
+		assert.Equal(t, expected, val)
+	}
+}
+
+func TestCDF(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	_, err = sketch.CDF(0)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for _, x := range []float64{1, 2, 2, 3, 4} {
+		sketch.Add(x)
+	}
+
+	for x, expected := range map[float64]float64{0: 0, 1: 0.2, 2: 0.6, 3.5: 0.8, 4: 1, 5: 1} {
+		cdf, err := sketch.CDF(x)
+		require.NoError(t, err)
+		assert.Equal(t, expected, cdf, "CDF of %v", x)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("fail: differing k cannot be merged", func(t *testing.T) {
+		s1, err := New()
+		require.NoError(t, err)
+		s2, err := New(KOption(100))
+		require.NoError(t, err)
+
+		err = s1.Merge(s2)
+		testutil.ContainsError(t, err, "cannot merge KLL sketch with k of 100 into KLL sketch with k of 200")
+	})
+
+	t.Run("pass: merged sketch approximates all values", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		n := 25000
+		xs := make([]float64, 0, 4*n)
+		merged, err := New(RandOption(rand.New(rand.NewSource(1))))
+		require.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			shard, err := New(RandOption(rand.New(rand.NewSource(int64(i)))))
+			require.NoError(t, err)
+			for j := 0; j < n; j++ {
+				x := rng.NormFloat64() + float64(i)
+				shard.Add(x)
+				xs = append(xs, x)
+			}
+			require.NoError(t, merged.Merge(shard))
+		}
+		sort.Float64s(xs)
+
+		assert.Equal(t, len(xs), merged.Size())
+		assert.True(t, merged.size < merged.maxSize)
+		assert.Equal(t, len(xs), merged.Rank(xs[len(xs)-1]+1))
+		for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+			val, err := merged.Value(q)
+			require.NoError(t, err)
+			rank := float64(sort.SearchFloat64s(xs, val)) / float64(len(xs))
+			assert.InDelta(t, q, rank, 0.0165, "quantile %v", q)
+		}
+	})
+
+	t.Run("pass: sketch can be merged with itself", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+		sketch.Add(1)
+		sketch.Add(2)
+
+		require.NoError(t, sketch.Merge(sketch))
+		assert.Equal(t, 4, sketch.Size())
+		assert.Equal(t, 2, sketch.Rank(2))
+	})
+}
+
+func TestValues(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	_, err = sketch.Values(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		sketch.Add(rng.Float64())
+	}
+
+	_, err = sketch.Values(0.5, -1)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", -1.))
+
+	qs := []float64{0.999, 0.5, 0.9, 0.95, 0.99}
+	values, err := sketch.Values(qs...)
+	require.NoError(t, err)
+	for i, q := range qs {
+		expected, err := sketch.Value(q)
+		require.NoError(t, err)
+		assert.Equal(t, expected, values[i])
+	}
+}
@@ -0,0 +1,42 @@
+package kll
+
+import (
+	"math/rand"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+// KOption creates an option that sets the parameter k for a KLL sketch,
+// which controls the size of its compactors; higher values of k are more accurate.
+func KOption(k int) order.Option {
+	return func(s order.Statistic) error {
+		var (
+			sketch *KLL
+			ok     bool
+		)
+		if sketch, ok = s.(*KLL); !ok {
+			return errors.New("attempted to set k on a non-KLL sketch")
+		} else if k < 8 {
+			return errors.Errorf("attempted to set k %d less than 8", k)
+		}
+		sketch.k = k
+		return nil
+	}
+}
+
+// RandOption creates an option that sets the rand source for the KLL sketch.
+func RandOption(r *rand.Rand) order.Option {
+	return func(s order.Statistic) error {
+		var (
+			sketch *KLL
+			ok     bool
+		)
+		if sketch, ok = s.(*KLL); !ok {
+			return errors.New("attempted to set rand source on a non-KLL sketch")
+		}
+		sketch.rand = r
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package kll
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream/quantile/ost/rb"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestKOption(t *testing.T) {
+	t.Run("fail: non-KLL sketch is invalid", func(t *testing.T) {
+		err := KOption(100)(&rb.Tree{})
+		testutil.ContainsError(t, err, "attempted to set k on a non-KLL sketch")
+	})
+
+	t.Run("fail: k < 8 is invalid", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = KOption(7)(sketch)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set k %d less than 8", 7))
+	})
+
+	t.Run("pass: valid k is set", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		err = KOption(100)(sketch)
+		require.NoError(t, err)
+
+		assert.Equal(t, 100, sketch.k)
+	})
+}
+
+func TestRandOption(t *testing.T) {
+	t.Run("fail: non-KLL sketch is invalid", func(t *testing.T) {
+		err := RandOption(rand.New(rand.NewSource(1)))(&rb.Tree{})
+		testutil.ContainsError(t, err, "attempted to set rand source on a non-KLL sketch")
+	})
+
+	t.Run("pass: rand is set", func(t *testing.T) {
+		sketch, err := New()
+		require.NoError(t, err)
+
+		rand := rand.New(rand.NewSource(1))
+
+		err = RandOption(rand)(sketch)
+		require.NoError(t, err)
+
+		assert.Equal(t, rand, sketch.rand)
+	})
+}
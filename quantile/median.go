@@ -2,6 +2,7 @@ package quantile
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -45,9 +46,29 @@ func (m *Median) Push(x float64) error {
 	return nil
 }
 
+// PushAt adds a number for calculating the median, which was observed
+// at the provided time; see Quantile.PushAt for details.
+func (m *Median) PushAt(x float64, t time.Time) error {
+	err := m.quantile.PushAt(x, t)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantile", x)
+	}
+	return nil
+}
+
 // Value returns the value of the median.
 func (m *Median) Value() (float64, error) {
-	value, err := m.quantile.Value(0.5)
+	s := m.quantile.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	return m.value(s)
+}
+
+// value returns the value of the median from the provided source of its
+// Quantile, without locking.
+func (m *Median) value(s *Quantile) (float64, error) {
+	value, err := m.quantile.value(s, 0.5)
 	if err != nil {
 		return 0, errors.Wrap(err, "error retrieving quantile value")
 	}
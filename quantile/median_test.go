@@ -3,6 +3,7 @@ package quantile
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,6 +91,25 @@ func TestMedianPush(t *testing.T) {
 	})
 }
 
+func TestMedianPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := testutil.NewClock(start.Add(12 * time.Second))
+	median, err := NewGlobalMedian(DurationOption(10*time.Second), ClockOption(clock))
+	require.NoError(t, err)
+
+	for i, x := range []float64{100, 1, 2, 3} {
+		err := median.PushAt(x, start.Add(time.Duration(4*i)*time.Second))
+		require.NoError(t, err)
+	}
+
+	value, err := median.Value()
+	require.NoError(t, err)
+	testutil.Approx(t, 2., value)
+
+	err = median.PushAt(4, start)
+	testutil.ContainsError(t, err, "error pushing 4.000000 to Quantile")
+}
+
 func TestMedianValue(t *testing.T) {
 	t.Run("pass: if number of values is even, return average of middle two", func(t *testing.T) {
 		median, err := NewMedian(4)
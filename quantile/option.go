@@ -1,8 +1,12 @@
 package quantile
 
 import (
-	"github.com/alexander-yu/stream/quantile/order"
+	"time"
+
 	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/quantile/order"
 )
 
 // Option is an optional argument for creating quantile-based metrics,
@@ -10,7 +14,9 @@ import (
 type Option func(*Quantile) error
 
 // ImplOption creates an option that sets the implementation for the
-// underlying data structure.
+// underlying data structure. The sketch implementations (TDigest, KLL and
+// DDSketch) return approximate quantiles using bounded memory; TDigest and
+// KLL cannot remove values, and so cannot be used with a window or duration.
 func ImplOption(impl Impl, options ...order.Option) Option {
 	return func(q *Quantile) error {
 		if !impl.Valid() {
@@ -18,6 +24,8 @@ func ImplOption(impl Impl, options ...order.Option) Option {
 		}
 
 		var err error
+		q.impl = impl
+		q.implOptions = len(options) > 0
 		q.statistic, err = impl.init(options...)
 		return errors.Wrap(err, "error setting Impl")
 	}
@@ -35,3 +43,34 @@ func InterpolationOption(i Interpolation) Option {
 		return nil
 	}
 }
+
+// DurationOption creates an option that tracks values over a time-based
+// window instead, where values are removed once they are at least the
+// provided duration older than the current time of the Quantile's Clock.
+// This can only be set for a Quantile with a window of 0.
+func DurationOption(d time.Duration) Option {
+	return func(q *Quantile) error {
+		if d <= 0 {
+			return errors.Errorf("attempted to set nonpositive duration of %v", d)
+		} else if q.window != 0 {
+			return errors.Errorf("attempted to set duration of %v with a nonzero window of %d", d, q.window)
+		}
+
+		q.duration = d
+		return nil
+	}
+}
+
+// ClockOption creates an option that sets the Clock used to timestamp
+// values pushed without an explicit time, and to remove expired values
+// when the Quantile is read; this defaults to stream.SystemClock.
+func ClockOption(clock stream.Clock) Option {
+	return func(q *Quantile) error {
+		if clock == nil {
+			return errors.New("attempted to set nil Clock")
+		}
+
+		q.clock = clock
+		return nil
+	}
+}
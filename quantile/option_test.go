@@ -3,7 +3,9 @@ package quantile
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/alexander-yu/stream/quantile/skiplist"
@@ -40,3 +42,37 @@ func TestInterpolationOption(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestDurationOption(t *testing.T) {
+	t.Run("fail: nonpositive duration is invalid", func(t *testing.T) {
+		err := DurationOption(0)(&Quantile{})
+		testutil.ContainsError(t, err, "attempted to set nonpositive duration of 0s")
+	})
+
+	t.Run("fail: duration with nonzero window is invalid", func(t *testing.T) {
+		err := DurationOption(time.Second)(&Quantile{window: 3})
+		testutil.ContainsError(t, err, "attempted to set duration of 1s with a nonzero window of 3")
+	})
+
+	t.Run("pass: valid DurationOption is valid", func(t *testing.T) {
+		quantile := &Quantile{}
+		err := DurationOption(time.Second)(quantile)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, quantile.duration)
+	})
+}
+
+func TestClockOption(t *testing.T) {
+	t.Run("fail: nil Clock is invalid", func(t *testing.T) {
+		err := ClockOption(nil)(&Quantile{})
+		testutil.ContainsError(t, err, "attempted to set nil Clock")
+	})
+
+	t.Run("pass: valid ClockOption is valid", func(t *testing.T) {
+		clock := testutil.NewClock(time.Unix(0, 0))
+		quantile := &Quantile{}
+		err := ClockOption(clock)(quantile)
+		require.NoError(t, err)
+		assert.Equal(t, clock, quantile.clock)
+	})
+}
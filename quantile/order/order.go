@@ -1,18 +1,25 @@
 package order
 
 // Node is an interface that acts as a container for a value.
-type Node interface {
-	Value() float64
-}
+type Node = TypedNode[float64]
 
 // Statistic is the interface required for any data structure that
 // can provide order statistics.
-type Statistic interface {
-	Add(float64)
-	Remove(float64)
+type Statistic = TypedStatistic[float64]
+
+// TypedNode is the generic counterpart of Node, for containers of values of type T.
+type TypedNode[T any] interface {
+	Value() T
+}
+
+// TypedStatistic is the generic counterpart of Statistic, for data structures
+// that provide order statistics over values of type T.
+type TypedStatistic[T any] interface {
+	Add(T)
+	Remove(T)
 	Size() int
-	Select(int) Node
-	Rank(float64) int
+	Select(int) TypedNode[T]
+	Rank(T) int
 	Clear()
 }
 
@@ -5,14 +5,18 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/alexander-yu/stream"
 	"github.com/alexander-yu/stream/quantile/order"
 )
 
-// Node represents a node in an AVL tree.
-type Node struct {
-	left   *Node
-	right  *Node
-	val    float64
+// Node represents a node in an AVL tree of float64 values.
+type Node = TypedNode[float64]
+
+// TypedNode represents a node in an AVL tree of values of type T.
+type TypedNode[T stream.Ordered] struct {
+	left   *TypedNode[T]
+	right  *TypedNode[T]
+	val    T
 	height int
 	size   int
 }
@@ -26,7 +30,12 @@ func max(x int, y int) int {
 
 // NewNode instantiates a Node struct with a a provided value.
 func NewNode(val float64) *Node {
-	return &Node{
+	return NewTypedNode(val)
+}
+
+// NewTypedNode instantiates a TypedNode struct with a a provided value.
+func NewTypedNode[T stream.Ordered](val T) *TypedNode[T] {
+	return &TypedNode[T]{
 		val:    val,
 		height: 0,
 		size:   1,
@@ -34,7 +43,7 @@ func NewNode(val float64) *Node {
 }
 
 // Left returns the left child of the node.
-func (n *Node) Left() (order.Node, error) {
+func (n *TypedNode[T]) Left() (order.TypedNode[T], error) {
 	if n == nil {
 		return nil, errors.New("tried to retrieve child of nil node")
 	}
@@ -42,7 +51,7 @@ func (n *Node) Left() (order.Node, error) {
 }
 
 // Right returns the right child of the node.
-func (n *Node) Right() (order.Node, error) {
+func (n *TypedNode[T]) Right() (order.TypedNode[T], error) {
 	if n == nil {
 		return nil, errors.New("tried to retrieve child of nil node")
 	}
@@ -50,7 +59,7 @@ func (n *Node) Right() (order.Node, error) {
 }
 
 // Height returns the height of the subtree rooted at the node.
-func (n *Node) Height() int {
+func (n *TypedNode[T]) Height() int {
 	if n == nil {
 		return -1
 	}
@@ -58,7 +67,7 @@ func (n *Node) Height() int {
 }
 
 // Size returns the size of the subtree rooted at the node.
-func (n *Node) Size() int {
+func (n *TypedNode[T]) Size() int {
 	if n == nil {
 		return 0
 	}
@@ -66,21 +75,21 @@ func (n *Node) Size() int {
 }
 
 // Value returns the value stored at the node.
-func (n *Node) Value() float64 {
+func (n *TypedNode[T]) Value() T {
 	return n.val
 }
 
 // TreeString returns the string representation of the subtree rooted at the node.
-func (n *Node) TreeString() string {
+func (n *TypedNode[T]) TreeString() string {
 	if n == nil {
 		return ""
 	}
 	return n.treeString("", "", true)
 }
 
-func (n *Node) add(val float64) *Node {
+func (n *TypedNode[T]) add(val T) *TypedNode[T] {
 	if n == nil {
-		return NewNode(val)
+		return NewTypedNode(val)
 	} else if val <= n.val {
 		n.left = n.left.add(val)
 	} else {
@@ -92,7 +101,7 @@ func (n *Node) add(val float64) *Node {
 	return n.balance()
 }
 
-func (n *Node) remove(val float64) *Node {
+func (n *TypedNode[T]) remove(val T) *TypedNode[T] {
 	// this case occurs if we attempt to remove a value
 	// that does not exist in the subtree; this will
 	// result in remove() being a no-op
@@ -121,7 +130,7 @@ func (n *Node) remove(val float64) *Node {
 	return root.balance()
 }
 
-func (n *Node) min() *Node {
+func (n *TypedNode[T]) min() *TypedNode[T] {
 	if n.left == nil {
 		return n
 	}
@@ -129,7 +138,7 @@ func (n *Node) min() *Node {
 	return n.left.min()
 }
 
-func (n *Node) removeMin() *Node {
+func (n *TypedNode[T]) removeMin() *TypedNode[T] {
 	if n.left == nil {
 		return n.right
 	}
@@ -144,7 +153,7 @@ func (n *Node) removeMin() *Node {
  * Rotations
  *****************/
 
-func (n *Node) balance() *Node {
+func (n *TypedNode[T]) balance() *TypedNode[T] {
 	if n.heightDiff() < -1 {
 		// Since we've entered this block, we already
 		// know that the right child is not nil
@@ -164,11 +173,11 @@ func (n *Node) balance() *Node {
 	return n
 }
 
-func (n *Node) heightDiff() int {
+func (n *TypedNode[T]) heightDiff() int {
 	return n.left.Height() - n.right.Height()
 }
 
-func (n *Node) rotateLeft() *Node {
+func (n *TypedNode[T]) rotateLeft() *TypedNode[T] {
 	m := n.right
 	n.right = m.left
 	m.left = n
@@ -184,7 +193,7 @@ func (n *Node) rotateLeft() *Node {
 	return m
 }
 
-func (n *Node) rotateRight() *Node {
+func (n *TypedNode[T]) rotateRight() *TypedNode[T] {
 	m := n.left
 	n.left = m.right
 	m.right = n
@@ -206,7 +215,7 @@ func (n *Node) rotateRight() *Node {
 
 // Select returns the node with the kth smallest value in the
 // subtree rooted at the node..
-func (n *Node) Select(k int) order.Node {
+func (n *TypedNode[T]) Select(k int) order.TypedNode[T] {
 	if n == nil {
 		return nil
 	}
@@ -223,7 +232,7 @@ func (n *Node) Select(k int) order.Node {
 
 // Rank returns the number of nodes strictly less than the value that
 // are contained in the subtree rooted at the node.
-func (n *Node) Rank(val float64) int {
+func (n *TypedNode[T]) Rank(val T) int {
 	if n == nil {
 		return 0
 	} else if val < n.val {
@@ -231,7 +240,8 @@ func (n *Node) Rank(val float64) int {
 	} else if val > n.val {
 		return 1 + n.left.Size() + n.right.Rank(val)
 	}
-	return n.left.Size()
+	// duplicates of val may have been rotated into the left subtree
+	return n.left.Rank(val)
 }
 
 /*******************
@@ -247,7 +257,7 @@ func (n *Node) Rank(val float64) int {
 //     └── 2.000000
 //         └── 1.000000
 //             └── 1.000000
-func (n *Node) treeString(prefix string, result string, isTail bool) string {
+func (n *TypedNode[T]) treeString(prefix string, result string, isTail bool) string {
 	// isTail indicates whether or not the current node's parent branch needs to be represented
 	// as a "tail", i.e. its branch needs to hang in the string representation, rather than branch upwards.
 	if isTail {
@@ -258,7 +268,7 @@ func (n *Node) treeString(prefix string, result string, isTail bool) string {
 		if n.right != nil {
 			result = n.right.treeString(fmt.Sprintf("%s│   ", prefix), result, false)
 		}
-		result = fmt.Sprintf("%s%s└── %f\n", result, prefix, n.val)
+		result = fmt.Sprintf("%s%s└── %s\n", result, prefix, stream.FormatValue(n.val))
 		if n.left != nil {
 			result = n.left.treeString(fmt.Sprintf("%s    ", prefix), result, true)
 		}
@@ -270,7 +280,7 @@ func (n *Node) treeString(prefix string, result string, isTail bool) string {
 		if n.right != nil {
 			result = n.right.treeString(fmt.Sprintf("%s    ", prefix), result, false)
 		}
-		result = fmt.Sprintf("%s%s┌── %f\n", result, prefix, n.val)
+		result = fmt.Sprintf("%s%s┌── %s\n", result, prefix, stream.FormatValue(n.val))
 		if n.left != nil {
 			result = n.left.treeString(fmt.Sprintf("%s│   ", prefix), result, true)
 		}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -153,6 +154,16 @@ func (s *TreeSuite) TestRank() {
 
 	rank = s.tree.Rank(-1)
 	s.Equal(0, rank)
+
+	// duplicates are not counted, regardless of where they are in the tree
+	for i := 0; i < 10; i++ {
+		s.tree.Add(2)
+	}
+	rank = s.tree.Rank(2)
+	s.Equal(2, rank)
+
+	rank = s.tree.Rank(3)
+	s.Equal(13, rank)
 }
 
 func (s *TreeSuite) TestSelect() {
@@ -170,3 +181,26 @@ func (s *TreeSuite) TestClear() {
 	s.tree.Clear()
 	s.Equal(&Tree{}, s.tree)
 }
+
+func TestTypedTree(t *testing.T) {
+	tree := &TypedTree[int64]{}
+	// values above 2^53 are not exactly representable as float64
+	base := int64(1) << 60
+	for _, offset := range []int64{3, 1, 2, 1} {
+		tree.Add(base + offset)
+	}
+
+	assert.Equal(t, 4, tree.Size())
+	assert.Equal(t, base+1, tree.Select(1).Value())
+	assert.Equal(t, base+3, tree.Select(3).Value())
+	assert.Equal(t, 2, tree.Rank(base+2))
+	assert.Equal(t, 3, tree.Rank(base+3))
+
+	tree.Remove(base + 1)
+	assert.Equal(t, 1, tree.Rank(base+2))
+	assert.Equal(t, strings.Join([]string{
+		"│   ┌── 1152921504606846979",
+		"└── 1152921504606846978",
+		"    └── 1152921504606846977",
+	}, "\n")+"\n", tree.String())
+}
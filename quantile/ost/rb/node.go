@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/alexander-yu/stream"
 	"github.com/alexander-yu/stream/quantile/order"
 )
 
@@ -26,18 +27,26 @@ func (c Color) String() string {
 	}
 }
 
-// Node represents a node in a red black tree.
-type Node struct {
-	left  *Node
-	right *Node
-	val   float64
+// Node represents a node in a red black tree of float64 values.
+type Node = TypedNode[float64]
+
+// TypedNode represents a node in a red black tree of values of type T.
+type TypedNode[T stream.Ordered] struct {
+	left  *TypedNode[T]
+	right *TypedNode[T]
+	val   T
 	color Color
 	size  int
 }
 
 // NewNode instantiates a Node struct with a a provided value.
 func NewNode(val float64) *Node {
-	return &Node{
+	return NewTypedNode(val)
+}
+
+// NewTypedNode instantiates a TypedNode struct with a a provided value.
+func NewTypedNode[T stream.Ordered](val T) *TypedNode[T] {
+	return &TypedNode[T]{
 		val:   val,
 		color: Red,
 		size:  1,
@@ -45,7 +54,7 @@ func NewNode(val float64) *Node {
 }
 
 // Left returns the left child of the node.
-func (n *Node) Left() (order.Node, error) {
+func (n *TypedNode[T]) Left() (order.TypedNode[T], error) {
 	if n == nil {
 		return nil, errors.New("tried to retrieve child of nil node")
 	}
@@ -53,7 +62,7 @@ func (n *Node) Left() (order.Node, error) {
 }
 
 // Right returns the right child of the node.
-func (n *Node) Right() (order.Node, error) {
+func (n *TypedNode[T]) Right() (order.TypedNode[T], error) {
 	if n == nil {
 		return nil, errors.New("tried to retrieve child of nil node")
 	}
@@ -61,7 +70,7 @@ func (n *Node) Right() (order.Node, error) {
 }
 
 // Size returns the size of the subtree rooted at the node.
-func (n *Node) Size() int {
+func (n *TypedNode[T]) Size() int {
 	if n == nil {
 		return 0
 	}
@@ -69,13 +78,13 @@ func (n *Node) Size() int {
 }
 
 // Value returns the value stored at the node.
-func (n *Node) Value() float64 {
+func (n *TypedNode[T]) Value() T {
 	return n.val
 }
 
 // Color returns the color of the node.
 // By default, nil nodes are black.
-func (n *Node) Color() Color {
+func (n *TypedNode[T]) Color() Color {
 	if n == nil {
 		return Black
 	}
@@ -83,16 +92,16 @@ func (n *Node) Color() Color {
 }
 
 // TreeString returns the string representation of the subtree rooted at the node.
-func (n *Node) TreeString() string {
+func (n *TypedNode[T]) TreeString() string {
 	if n == nil {
 		return ""
 	}
 	return n.treeString("", "", true)
 }
 
-func (n *Node) add(val float64) *Node {
+func (n *TypedNode[T]) add(val T) *TypedNode[T] {
 	if n == nil {
-		return NewNode(val)
+		return NewTypedNode(val)
 	} else if val <= n.val {
 		n.left = n.left.add(val)
 	} else {
@@ -101,7 +110,7 @@ func (n *Node) add(val float64) *Node {
 	return n.addBalance()
 }
 
-func (n *Node) remove(val float64) *Node {
+func (n *TypedNode[T]) remove(val T) *TypedNode[T] {
 	if !n.contains(val) {
 		return n
 	}
@@ -133,7 +142,7 @@ func (n *Node) remove(val float64) *Node {
 	return n.removeBalance()
 }
 
-func (n *Node) removeMin() *Node {
+func (n *TypedNode[T]) removeMin() *TypedNode[T] {
 	if n.left == nil {
 		return nil
 	}
@@ -145,14 +154,14 @@ func (n *Node) removeMin() *Node {
 	return n.removeBalance()
 }
 
-func (n *Node) min() *Node {
+func (n *TypedNode[T]) min() *TypedNode[T] {
 	if n.left == nil {
 		return n
 	}
 	return n.left.min()
 }
 
-func (n *Node) contains(val float64) bool {
+func (n *TypedNode[T]) contains(val T) bool {
 	for n != nil {
 		if val == n.val {
 			return true
@@ -169,7 +178,7 @@ func (n *Node) contains(val float64) bool {
  * Rotations
  *****************/
 
-func (n *Node) addBalance() *Node {
+func (n *TypedNode[T]) addBalance() *TypedNode[T] {
 	if n.left.Color() == Black && n.right.Color() == Red {
 		n = n.rotateLeft()
 	}
@@ -184,7 +193,7 @@ func (n *Node) addBalance() *Node {
 	return n
 }
 
-func (n *Node) removeBalance() *Node {
+func (n *TypedNode[T]) removeBalance() *TypedNode[T] {
 	if n.right.Color() == Red {
 		n = n.rotateLeft()
 	}
@@ -199,7 +208,7 @@ func (n *Node) removeBalance() *Node {
 	return n
 }
 
-func (n *Node) rotateLeft() *Node {
+func (n *TypedNode[T]) rotateLeft() *TypedNode[T] {
 	x := n.right
 	n.right = x.left
 	x.left = n
@@ -210,7 +219,7 @@ func (n *Node) rotateLeft() *Node {
 	return x
 }
 
-func (n *Node) rotateRight() *Node {
+func (n *TypedNode[T]) rotateRight() *TypedNode[T] {
 	x := n.left
 	n.left = x.right
 	x.right = n
@@ -221,13 +230,13 @@ func (n *Node) rotateRight() *Node {
 	return x
 }
 
-func (n *Node) flipColors() {
+func (n *TypedNode[T]) flipColors() {
 	n.color = !n.color
 	n.left.color = !n.left.color
 	n.right.color = !n.right.color
 }
 
-func (n *Node) moveRedLeft() *Node {
+func (n *TypedNode[T]) moveRedLeft() *TypedNode[T] {
 	n.flipColors()
 	if n.right.left.Color() == Red {
 		n.right = n.right.rotateRight()
@@ -237,7 +246,7 @@ func (n *Node) moveRedLeft() *Node {
 	return n
 }
 
-func (n *Node) moveRedRight() *Node {
+func (n *TypedNode[T]) moveRedRight() *TypedNode[T] {
 	n.flipColors()
 	if n.left.left.Color() == Red {
 		n = n.rotateRight()
@@ -252,7 +261,7 @@ func (n *Node) moveRedRight() *Node {
 
 // Select returns the node with the kth smallest value in the
 // subtree rooted at the node..
-func (n *Node) Select(k int) order.Node {
+func (n *TypedNode[T]) Select(k int) order.TypedNode[T] {
 	if n == nil {
 		return nil
 	}
@@ -269,7 +278,7 @@ func (n *Node) Select(k int) order.Node {
 
 // Rank returns the number of nodes strictly less than the value that
 // are contained in the subtree rooted at the node.
-func (n *Node) Rank(val float64) int {
+func (n *TypedNode[T]) Rank(val T) int {
 	if n == nil {
 		return 0
 	} else if val < n.val {
@@ -277,7 +286,8 @@ func (n *Node) Rank(val float64) int {
 	} else if val > n.val {
 		return 1 + n.left.Size() + n.right.Rank(val)
 	}
-	return n.left.Size()
+	// duplicates of val may have been rotated into the left subtree
+	return n.left.Rank(val)
 }
 
 /*******************
@@ -293,7 +303,7 @@ func (n *Node) Rank(val float64) int {
 //     └── 2.000000
 //         └── 1.000000
 //             └── 1.000000
-func (n *Node) treeString(prefix string, result string, isTail bool) string {
+func (n *TypedNode[T]) treeString(prefix string, result string, isTail bool) string {
 	// isTail indicates whether or not the current node's parent branch needs to be represented
 	// as a "tail", i.e. its branch needs to hang in the string representation, rather than branch upwards.
 	if isTail {
@@ -304,7 +314,7 @@ func (n *Node) treeString(prefix string, result string, isTail bool) string {
 		if n.right != nil {
 			result = n.right.treeString(fmt.Sprintf("%s│   ", prefix), result, false)
 		}
-		result = fmt.Sprintf("%s%s└── %f\n", result, prefix, n.val)
+		result = fmt.Sprintf("%s%s└── %s\n", result, prefix, stream.FormatValue(n.val))
 		if n.left != nil {
 			result = n.left.treeString(fmt.Sprintf("%s    ", prefix), result, true)
 		}
@@ -316,7 +326,7 @@ func (n *Node) treeString(prefix string, result string, isTail bool) string {
 		if n.right != nil {
 			result = n.right.treeString(fmt.Sprintf("%s    ", prefix), result, false)
 		}
-		result = fmt.Sprintf("%s%s┌── %f\n", result, prefix, n.val)
+		result = fmt.Sprintf("%s%s┌── %s\n", result, prefix, stream.FormatValue(n.val))
 		if n.left != nil {
 			result = n.left.treeString(fmt.Sprintf("%s│   ", prefix), result, true)
 		}
@@ -1,45 +1,52 @@
 package rb
 
-import "github.com/alexander-yu/stream/quantile/order"
+import (
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/quantile/order"
+)
 
 // Tree implements a red-black tree data structure,
 // and also satisfies the st.Tree interface,
 // as well as the order.Statistic interface.
-type Tree struct {
-	root *Node
+type Tree = TypedTree[float64]
+
+// TypedTree is the generic counterpart of Tree, for values of any ordered type T;
+// it satisfies the order.TypedStatistic interface.
+type TypedTree[T stream.Ordered] struct {
+	root *TypedNode[T]
 }
 
 // Size returns the size of the tree.
-func (t *Tree) Size() int {
+func (t *TypedTree[T]) Size() int {
 	return t.root.Size()
 }
 
 // Add inserts a value into the tree.
-func (t *Tree) Add(val float64) {
+func (t *TypedTree[T]) Add(val T) {
 	t.root = t.root.add(val)
 }
 
 // Remove deletes a value from the tree.
-func (t *Tree) Remove(val float64) {
+func (t *TypedTree[T]) Remove(val T) {
 	t.root = t.root.remove(val)
 }
 
 // Select returns the node with the kth smallest value in the tree.
-func (t *Tree) Select(k int) order.Node {
+func (t *TypedTree[T]) Select(k int) order.TypedNode[T] {
 	return t.root.Select(k)
 }
 
 // Rank returns the number of nodes strictly less than the value.
-func (t *Tree) Rank(val float64) int {
+func (t *TypedTree[T]) Rank(val T) int {
 	return t.root.Rank(val)
 }
 
 // String returns the string representation of the tree.
-func (t *Tree) String() string {
+func (t *TypedTree[T]) String() string {
 	return t.root.TreeString()
 }
 
 // Clear resets the tree.
-func (t *Tree) Clear() {
-	*t = Tree{}
+func (t *TypedTree[T]) Clear() {
+	*t = TypedTree[T]{}
 }
@@ -3,7 +3,9 @@ package rb
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -157,6 +159,16 @@ func (s *TreeSuite) TestRank() {
 
 	rank = s.tree.Rank(-1)
 	s.Equal(0, rank)
+
+	// duplicates are not counted, regardless of where they are in the tree
+	for i := 0; i < 10; i++ {
+		s.tree.Add(2)
+	}
+	rank = s.tree.Rank(2)
+	s.Equal(2, rank)
+
+	rank = s.tree.Rank(3)
+	s.Equal(13, rank)
 }
 
 func (s *TreeSuite) TestSelect() {
@@ -174,3 +186,24 @@ func (s *TreeSuite) TestClear() {
 	s.tree.Clear()
 	s.Equal(&Tree{}, s.tree)
 }
+
+func TestTypedTree(t *testing.T) {
+	tree := &TypedTree[time.Duration]{}
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second, time.Second} {
+		tree.Add(d)
+	}
+
+	assert.Equal(t, 4, tree.Size())
+	assert.Equal(t, time.Second, tree.Select(1).Value())
+	assert.Equal(t, 3*time.Second, tree.Select(3).Value())
+	assert.Equal(t, 2, tree.Rank(2*time.Second))
+	assert.Equal(t, 3, tree.Rank(3*time.Second))
+
+	tree.Remove(time.Second)
+	assert.Equal(t, 1, tree.Rank(2*time.Second))
+	assert.Equal(t, strings.Join([]string{
+		"│   ┌── 3s",
+		"└── 2s",
+		"    └── 1s",
+	}, "\n")+"\n", tree.String())
+}
@@ -5,19 +5,45 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/alexander-yu/stream/quantile/order"
+	"github.com/gammazero/deque"
 	"github.com/pkg/errors"
 	"github.com/workiva/go-datastructures/queue"
+
+	"github.com/alexander-yu/stream"
+	"github.com/alexander-yu/stream/quantile/order"
 )
 
 // Quantile keeps track of the quantile of a stream using order statistics.
-type Quantile struct {
+type Quantile = TypedQuantile[float64]
+
+// TypedQuantile keeps track of the quantile of a stream of values of any numeric
+// type T, e.g. int64 or time.Duration, which avoids converting such values to float64.
+type TypedQuantile[T stream.Number] struct {
 	window        int
+	duration      time.Duration
+	clock         stream.Clock
 	interpolation Interpolation
-	queue         *queue.RingBuffer
-	statistic     order.Statistic
-	mux           sync.RWMutex
+	impl          Impl
+	// Set if the Impl was configured with order.Options, which cannot be
+	// compared, so the order statistic is never shared (see Aggregate)
+	implOptions bool
+	queue       *queue.RingBuffer
+	timed       *deque.Deque[timedValue[T]]
+	latest      time.Time
+	statistic   order.TypedStatistic[T]
+	mux         sync.RWMutex
+	// Used if the window and order statistic are shared with another
+	// TypedQuantile (see Aggregate), in which case the fields above
+	// other than the interpolation are unused; this is guarded by mux
+	shared *TypedQuantile[T]
+}
+
+// timedValue is a value in a time-based window, along with the time it was pushed at.
+type timedValue[T stream.Number] struct {
+	x T
+	t time.Time
 }
 
 // New instantiates a Quantile struct.
@@ -33,8 +59,10 @@ func New(window int, options ...Option) (*Quantile, error) {
 
 	quantile := &Quantile{
 		window:        window,
+		clock:         stream.SystemClock,
 		interpolation: Linear,
 		queue:         queue.NewRingBuffer(uint64(window)),
+		timed:         new(deque.Deque[timedValue[float64]]),
 		statistic:     avl,
 	}
 
@@ -45,6 +73,10 @@ func New(window int, options ...Option) (*Quantile, error) {
 		}
 	}
 
+	if (quantile.window != 0 || quantile.duration != 0) && !quantile.impl.removable() {
+		return nil, errors.Errorf("Impl %d does not support removing values, so it cannot be used with a window", quantile.impl)
+	}
+
 	return quantile, nil
 }
 
@@ -54,35 +86,115 @@ func NewGlobalQuantile(options ...Option) (*Quantile, error) {
 	return New(0, options...)
 }
 
+// NewTyped instantiates a TypedQuantile struct. The options are the same as
+// for New; however, only the order statistic tree implementations (AVL and
+// RedBlack) support values of types other than float64.
+func NewTyped[T stream.Number](window int, options ...Option) (*TypedQuantile[T], error) {
+	config, err := New(window, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	statistic, err := initTyped[T](config.impl, config.statistic)
+	if err != nil {
+		return nil, errors.Wrap(err, "error instantiating order.Statistic")
+	}
+
+	return &TypedQuantile[T]{
+		window:        config.window,
+		duration:      config.duration,
+		clock:         config.clock,
+		interpolation: config.interpolation,
+		impl:          config.impl,
+		queue:         config.queue,
+		timed:         new(deque.Deque[timedValue[T]]),
+		statistic:     statistic,
+	}, nil
+}
+
+// NewGlobalTypedQuantile instantiates a global TypedQuantile struct.
+// This is equivalent to calling NewTyped[T](0, options...).
+func NewGlobalTypedQuantile[T stream.Number](options ...Option) (*TypedQuantile[T], error) {
+	return NewTyped[T](0, options...)
+}
+
 // String returns a string representation of the metric.
-func (q *Quantile) String() string {
+func (q *TypedQuantile[T]) String() string {
 	name := "quantile.Quantile"
 	params := []string{
 		fmt.Sprintf("window:%v", q.window),
 		fmt.Sprintf("interpolation:%v", q.interpolation),
 	}
+	if q.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", q.duration))
+	}
 	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
 }
 
-// Push adds a number for calculating the quantile.
-func (q *Quantile) Push(x float64) error {
-	q.mux.Lock()
-	defer q.mux.Unlock()
+// source returns the TypedQuantile whose window and order statistic are used,
+// which is itself unless they are shared with another TypedQuantile. This locks
+// the TypedQuantile, so it must be called before locking the source, which is
+// then passed to any unlocked helpers.
+func (q *TypedQuantile[T]) source() *TypedQuantile[T] {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+	if q.shared != nil {
+		return q.shared
+	}
+	return q
+}
+
+// Push adds a number for calculating the quantile. If the Quantile tracks
+// values over a time-based window, the value is timestamped with the
+// current time of its Clock.
+func (q *TypedQuantile[T]) Push(x T) error {
+	s := q.source()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var t time.Time
+	if s.duration != 0 {
+		t = s.clock.Now()
+	}
+	return s.push(x, t)
+}
+
+// PushAt adds a number for calculating the quantile, which was observed at
+// the provided time. This is only meaningful if the Quantile tracks values
+// over a time-based window (see DurationOption), in which case values at or
+// before t - duration are removed from the window; otherwise the time is ignored.
+// Values must be pushed in chronological order, and since reads remove values
+// relative to the Quantile's Clock, t must be on the Clock's timeline (see stream.Clock).
+func (q *TypedQuantile[T]) PushAt(x T, t time.Time) error {
+	s := q.source()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.push(x, t)
+}
+
+func (q *TypedQuantile[T]) push(x T, t time.Time) error {
+	if q.duration != 0 {
+		if t.Before(q.latest) {
+			return errors.Errorf("time %v is before the latest time %v", t, q.latest)
+		}
 
-	if q.window != 0 {
+		q.expire(t)
+		q.timed.PushBack(timedValue[T]{x: x, t: t})
+		q.latest = t
+	} else if q.window != 0 {
 		if q.queue.Len() == uint64(q.window) {
 			val, err := q.queue.Get()
 			if err != nil {
 				return errors.Wrap(err, "error popping item from queue")
 			}
 
-			y := val.(float64)
+			y := val.(T)
 			q.statistic.Remove(y)
 		}
 
 		err := q.queue.Put(x)
 		if err != nil {
-			return errors.Wrapf(err, "error pushing %f to queue", x)
+			return errors.Wrapf(err, "error pushing %s to queue", stream.FormatValue(x))
 		}
 	}
 
@@ -90,18 +202,83 @@ func (q *Quantile) Push(x float64) error {
 	return nil
 }
 
-// Value returns the value of the quantile.
-func (q *Quantile) Value(quantile float64) (float64, error) {
-	if quantile <= 0 || quantile >= 1 {
-		return 0, errors.Errorf("quantile %f not in (0, 1)", quantile)
+// expire removes all values at or before t - duration from a time-based window.
+func (q *TypedQuantile[T]) expire(t time.Time) {
+	cutoff := t.Add(-q.duration)
+	for q.timed.Len() > 0 && !q.timed.Front().t.After(cutoff) {
+		q.statistic.Remove(q.timed.PopFront().x)
 	}
+}
 
+// rlock locks the quantile for reading, after removing any values that have
+// fallen out of a time-based window under the write lock.
+func (q *TypedQuantile[T]) rlock() {
 	q.mux.RLock()
-	defer q.mux.RUnlock()
+	if q.duration == 0 {
+		return
+	}
+	q.mux.RUnlock()
+
+	q.mux.Lock()
+	q.expire(q.clock.Now())
+	q.mux.Unlock()
+	q.mux.RLock()
+}
+
+// Value returns the value of the quantile. If the quantile tracks values over
+// a time-based window, expired values are removed first.
+func (q *TypedQuantile[T]) Value(quantile float64) (T, error) {
+	if quantile <= 0 || quantile >= 1 {
+		var zero T
+		return zero, errors.Errorf("quantile %f not in (0, 1)", quantile)
+	}
+
+	s := q.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	return q.value(s, quantile)
+}
+
+// Values returns the values of multiple quantiles, in the order that they were
+// provided. Since the values are all read under a single lock, they are
+// consistent with each other even if values are being pushed concurrently.
+func (q *TypedQuantile[T]) Values(quantiles ...float64) ([]T, error) {
+	s := q.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	return q.values(s, quantiles...)
+}
+
+// values returns the values of multiple quantiles from the source of the
+// TypedQuantile, without locking.
+func (q *TypedQuantile[T]) values(s *TypedQuantile[T], quantiles ...float64) ([]T, error) {
+	for _, quantile := range quantiles {
+		if quantile <= 0 || quantile >= 1 {
+			return nil, errors.Errorf("quantile %f not in (0, 1)", quantile)
+		}
+	}
+
+	values := make([]T, len(quantiles))
+	for i, quantile := range quantiles {
+		val, err := q.value(s, quantile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving quantile %f", quantile)
+		}
+		values[i] = val
+	}
 
-	size := int(q.statistic.Size())
+	return values, nil
+}
+
+// value returns the value of the quantile from the source of the TypedQuantile,
+// without locking.
+func (q *TypedQuantile[T]) value(s *TypedQuantile[T], quantile float64) (T, error) {
+	size := int(s.statistic.Size())
 	if size == 0 {
-		return 0, errors.New("no values seen yet")
+		var zero T
+		return zero, errors.New("no values seen yet")
 	}
 
 	idxRaw := quantile * float64(size-1)
@@ -110,53 +287,141 @@ func (q *Quantile) Value(quantile float64) (float64, error) {
 	// if the estimated index is actually an integer,
 	// no interpolation needed
 	if idxRaw == idxTrunc {
-		return q.statistic.Select(idx).Value(), nil
+		return s.statistic.Select(idx).Value(), nil
 	}
 
 	delta := idxRaw - idxTrunc
 	switch q.interpolation {
 	case Linear:
-		lo := q.statistic.Select(idx).Value()
-		hi := q.statistic.Select(idx + 1).Value()
-		return (1-delta)*lo + delta*hi, nil
+		lo := s.statistic.Select(idx).Value()
+		hi := s.statistic.Select(idx + 1).Value()
+		return interpolate(lo, hi, delta), nil
 	case Lower:
-		return q.statistic.Select(idx).Value(), nil
+		return s.statistic.Select(idx).Value(), nil
 	case Higher:
-		return q.statistic.Select(idx + 1).Value(), nil
+		return s.statistic.Select(idx + 1).Value(), nil
 	case Nearest:
 		switch {
 		case delta == 0.5:
 			if idx%2 == 0 {
-				return q.statistic.Select(idx).Value(), nil
+				return s.statistic.Select(idx).Value(), nil
 			}
-			return q.statistic.Select(idx + 1).Value(), nil
+			return s.statistic.Select(idx + 1).Value(), nil
 		case delta < 0.5:
-			return q.statistic.Select(idx).Value(), nil
+			return s.statistic.Select(idx).Value(), nil
 		default:
-			return q.statistic.Select(idx + 1).Value(), nil
+			return s.statistic.Select(idx + 1).Value(), nil
 		}
 	default:
-		lo := q.statistic.Select(idx).Value()
-		hi := q.statistic.Select(idx + 1).Value()
-		return (lo + hi) / 2., nil
+		lo := s.statistic.Select(idx).Value()
+		hi := s.statistic.Select(idx + 1).Value()
+		return midpoint(lo, hi), nil
+	}
+}
+
+// Rank returns the number of values strictly less than x.
+func (q *TypedQuantile[T]) Rank(x T) int {
+	s := q.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	return s.statistic.Rank(x)
+}
+
+// CountBetween returns the number of values that lie in the closed interval [lo, hi].
+func (q *TypedQuantile[T]) CountBetween(lo T, hi T) (int, error) {
+	if lo > hi {
+		return 0, errors.Errorf(
+			"lower bound %s is greater than upper bound %s",
+			stream.FormatValue(lo),
+			stream.FormatValue(hi),
+		)
+	}
+
+	s := q.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	return s.rankAtMost(hi) - s.statistic.Rank(lo), nil
+}
+
+// rankAtMost returns the number of values that are at most x, without locking.
+func (q *TypedQuantile[T]) rankAtMost(x T) int {
+	next, ok := successor(x)
+	if !ok {
+		return q.statistic.Size()
+	}
+	return q.statistic.Rank(next)
+}
+
+// CDF returns the inverse of Value for x; in particular, it returns the largest
+// quantile φ in [0, 1] such that the φ-quantile (computed with the same
+// Interpolation as Value) is at most x. This uses the same convention as Value,
+// where the ith smallest of n values is the i/(n - 1) quantile; for example, the
+// CDF of a value that appears in the window is the quantile of the last copy of it.
+// If x is less than every value, then 0 is returned, and if x is at least every
+// value, then 1 is returned.
+//
+// Note that this is not the empirical CDF (i.e. the fraction of values that are
+// at most x); for example, under Linear interpolation the CDF of the smallest
+// value is 0, even though that value is in the window. Use Rank or CountBetween
+// to count the values below a bound instead.
+func (q *TypedQuantile[T]) CDF(x T) (float64, error) {
+	s := q.source()
+	s.rlock()
+	defer s.mux.RUnlock()
+
+	size := s.statistic.Size()
+	if size == 0 {
+		return 0, errors.New("no values seen yet")
+	}
+
+	// idx is the index of the last value that is at most x
+	idx := s.rankAtMost(x) - 1
+	if idx < 0 {
+		return 0, nil
+	} else if idx >= size-1 {
+		return 1, nil
+	}
+
+	lo := s.statistic.Select(idx).Value()
+	hi := s.statistic.Select(idx + 1).Value()
+	position := float64(idx)
+	switch q.interpolation {
+	case Linear:
+		position += distance(lo, x) / distance(lo, hi)
+	case Lower:
+		position++
+	case Higher:
+	case Nearest:
+		position += 0.5
+	default:
+		if midpoint(lo, hi) <= x {
+			position++
+		}
 	}
+
+	return position / float64(size-1), nil
 }
 
 // Clear resets the metric.
-func (q *Quantile) Clear() {
-	q.mux.Lock()
-	defer q.mux.Unlock()
-	q.queue.Dispose()
-	q.queue = queue.NewRingBuffer(uint64(q.window))
-	q.statistic.Clear()
+func (q *TypedQuantile[T]) Clear() {
+	s := q.source()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.queue.Dispose()
+	s.queue = queue.NewRingBuffer(uint64(s.window))
+	s.timed.Clear()
+	s.latest = time.Time{}
+	s.statistic.Clear()
 }
 
 // RLock locks the quantile for reading.
-func (q *Quantile) RLock() {
-	q.mux.RLock()
+func (q *TypedQuantile[T]) RLock() {
+	q.source().mux.RLock()
 }
 
 // RUnlock undoes an RLock call.
-func (q *Quantile) RUnlock() {
-	q.mux.RUnlock()
+func (q *TypedQuantile[T]) RUnlock() {
+	q.source().mux.RUnlock()
 }
@@ -2,11 +2,16 @@ package quantile
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/alexander-yu/stream/quantile/kll"
 	"github.com/alexander-yu/stream/quantile/skiplist"
 	testutil "github.com/alexander-yu/stream/util/test"
 )
@@ -26,6 +31,19 @@ func TestNew(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, Nearest, quantile.interpolation)
 	})
+
+	t.Run("fail: sketches that cannot remove values cannot have a window", func(t *testing.T) {
+		_, err := New(3, ImplOption(TDigest))
+		testutil.ContainsError(t, err, fmt.Sprintf("Impl %d does not support removing values, so it cannot be used with a window", TDigest))
+
+		_, err = NewGlobalQuantile(ImplOption(KLL), DurationOption(time.Minute))
+		testutil.ContainsError(t, err, fmt.Sprintf("Impl %d does not support removing values, so it cannot be used with a window", KLL))
+	})
+
+	t.Run("pass: DDSketch can have a window", func(t *testing.T) {
+		_, err := New(3, ImplOption(DDSketch))
+		require.NoError(t, err)
+	})
 }
 
 func TestNewGlobalQuantile(t *testing.T) {
@@ -47,6 +65,15 @@ func TestQuantileString(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, expectedString, quantile.String())
+
+	expectedString = fmt.Sprintf(
+		"quantile.Quantile_{window:0,interpolation:%d,duration:1m0s}",
+		Linear,
+	)
+	quantile, err = NewGlobalQuantile(DurationOption(time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedString, quantile.String())
 }
 
 func TestQuantilePush(t *testing.T) {
@@ -96,6 +123,95 @@ func TestQuantilePush(t *testing.T) {
 	})
 }
 
+func TestQuantilePushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start.Add(12 * time.Second))
+		quantile, err := NewGlobalQuantile(DurationOption(10*time.Second), ClockOption(clock))
+		require.NoError(t, err)
+
+		offsets := []int{0, 2, 3, 6, 12}
+		for i, offset := range offsets {
+			err := quantile.PushAt(float64(i), start.Add(time.Duration(offset)*time.Second))
+			require.NoError(t, err)
+		}
+
+		// the values at 0s and 2s are at or before the cutoff of 12s - 10s
+		assert.Equal(t, 3, quantile.statistic.Size())
+		assert.Equal(t, 3, quantile.timed.Len())
+		value, err := quantile.Value(0.5)
+		require.NoError(t, err)
+		testutil.Approx(t, 3., value)
+	})
+
+	t.Run("pass: Push uses the configured clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		quantile, err := NewGlobalQuantile(DurationOption(time.Minute), ClockOption(clock))
+		require.NoError(t, err)
+
+		for i := 0.; i < 4; i++ {
+			err := quantile.Push(i)
+			require.NoError(t, err)
+			clock.Advance(20 * time.Second)
+		}
+
+		// only the values pushed at 40s and 60s remain
+		value, err := quantile.Value(0.5)
+		require.NoError(t, err)
+		testutil.Approx(t, 2.5, value)
+		assert.Equal(t, 2, quantile.statistic.Size())
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		quantile, err := NewGlobalQuantile(DurationOption(time.Minute), ClockOption(clock))
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err := quantile.Push(x)
+			require.NoError(t, err)
+			clock.Advance(20 * time.Second)
+		}
+
+		clock.Advance(30 * time.Second)
+		value, err := quantile.Value(0.5)
+		require.NoError(t, err)
+		testutil.Approx(t, 3., value)
+		assert.Equal(t, 1, quantile.Rank(4))
+
+		clock.Advance(time.Hour)
+		_, err = quantile.Value(0.5)
+		assert.EqualError(t, err, "no values seen yet")
+		_, err = quantile.CDF(1)
+		assert.EqualError(t, err, "no values seen yet")
+		assert.Equal(t, 0, quantile.Rank(4))
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		quantile, err := NewGlobalQuantile(DurationOption(time.Minute))
+		require.NoError(t, err)
+
+		err = quantile.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = quantile.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+
+	t.Run("pass: time is ignored without a duration", func(t *testing.T) {
+		quantile, err := New(2)
+		require.NoError(t, err)
+
+		for i := 0.; i < 3; i++ {
+			err := quantile.PushAt(i, start.Add(-time.Duration(i)*time.Hour))
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 2, quantile.statistic.Size())
+	})
+}
+
 func TestQuantileValue(t *testing.T) {
 	t.Run("pass: returns quantile for exact index", func(t *testing.T) {
 		quantile, err := New(5)
@@ -243,6 +359,235 @@ func TestQuantileValue(t *testing.T) {
 	})
 }
 
+func TestQuantileValues(t *testing.T) {
+	t.Run("pass: returns values in the order provided", func(t *testing.T) {
+		quantile, err := New(6, InterpolationOption(Nearest))
+		require.NoError(t, err)
+
+		for i := 0.; i < 10; i++ {
+			err = quantile.Push(i * i)
+			require.NoError(t, err)
+		}
+
+		values, err := quantile.Values(0.9, 0.25, 0.5)
+		require.NoError(t, err)
+
+		for i, p := range []float64{0.9, 0.25, 0.5} {
+			expected, err := quantile.Value(p)
+			require.NoError(t, err)
+			testutil.Approx(t, expected, values[i])
+		}
+	})
+
+	t.Run("pass: no quantiles returns no values", func(t *testing.T) {
+		quantile, err := New(3)
+		require.NoError(t, err)
+
+		values, err := quantile.Values()
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("fail: quantile not in (0, 1) fails", func(t *testing.T) {
+		quantile, err := New(3)
+		require.NoError(t, err)
+
+		_, err = quantile.Values(0.5, 1)
+		testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in (0, 1)", 1.))
+	})
+
+	t.Run("fail: if no values seen, return error", func(t *testing.T) {
+		quantile, err := New(3)
+		require.NoError(t, err)
+
+		_, err = quantile.Values(0.5)
+		testutil.ContainsError(t, err, "no values seen yet")
+	})
+}
+
+func TestQuantileRank(t *testing.T) {
+	quantile, err := New(5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, quantile.Rank(1))
+
+	for _, x := range []float64{0, 1, 2, 2, 3, 5} {
+		err = quantile.Push(x)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, quantile.Rank(1))
+	assert.Equal(t, 1, quantile.Rank(2))
+	assert.Equal(t, 3, quantile.Rank(2.5))
+	assert.Equal(t, 5, quantile.Rank(6))
+}
+
+func TestQuantileCountBetween(t *testing.T) {
+	quantile, err := New(5)
+	require.NoError(t, err)
+
+	for _, x := range []float64{0, 1, 2, 2, 3, 5} {
+		err = quantile.Push(x)
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		lo, hi   float64
+		expected int
+	}{
+		{2, 3, 3},
+		{2, 2, 2},
+		{2.5, 2.6, 0},
+		{0, 10, 5},
+		{-1, 0.5, 0},
+	} {
+		count, err := quantile.CountBetween(tc.lo, tc.hi)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, count, "count between %v and %v", tc.lo, tc.hi)
+	}
+
+	_, err = quantile.CountBetween(3, 2)
+	testutil.ContainsError(t, err, fmt.Sprintf("lower bound %f is greater than upper bound %f", 3., 2.))
+}
+
+func TestQuantileCDF(t *testing.T) {
+	t.Run("pass: returns CDF for each interpolation", func(t *testing.T) {
+		expected := map[Interpolation]map[float64]float64{
+			Linear:   {0: 0, 1: 0, 2: 0.5, 4: 0.875, 5: 1, 6: 1},
+			Lower:    {0: 0, 1: 0.25, 2: 0.75, 4: 1, 5: 1, 6: 1},
+			Higher:   {0: 0, 1: 0, 2: 0.5, 4: 0.75, 5: 1, 6: 1},
+			Nearest:  {0: 0, 1: 0.125, 2: 0.625, 4: 0.875, 5: 1, 6: 1},
+			Midpoint: {0: 0, 1: 0, 2: 0.5, 4: 1, 5: 1, 6: 1},
+		}
+
+		for interpolation, cdfs := range expected {
+			quantile, err := NewGlobalQuantile(InterpolationOption(interpolation))
+			require.NoError(t, err)
+
+			for _, x := range []float64{1, 2, 2, 3, 5} {
+				err = quantile.Push(x)
+				require.NoError(t, err)
+			}
+
+			for x, cdf := range cdfs {
+				actual, err := quantile.CDF(x)
+				require.NoError(t, err)
+				testutil.Approx(t, cdf, actual, "CDF of %v with interpolation %v", x, interpolation)
+			}
+		}
+	})
+
+	t.Run("pass: CDF is the inverse of Value", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		for _, interpolation := range []Interpolation{Linear, Lower, Higher, Nearest, Midpoint} {
+			quantile, err := NewGlobalQuantile(InterpolationOption(interpolation))
+			require.NoError(t, err)
+
+			for i := 0; i < 50; i++ {
+				err = quantile.Push(float64(rng.Intn(20)))
+				require.NoError(t, err)
+			}
+
+			for p := 0.01; p < 1; p += 0.01 {
+				x, err := quantile.Value(p)
+				require.NoError(t, err)
+
+				cdf, err := quantile.CDF(x)
+				require.NoError(t, err)
+				assert.True(t, cdf >= p-1e-9, "CDF of %v-quantile with interpolation %v is %v", p, interpolation, cdf)
+			}
+		}
+	})
+
+	t.Run("pass: endpoints are the quantiles of the smallest and largest values", func(t *testing.T) {
+		quantile, err := NewGlobalQuantile()
+		require.NoError(t, err)
+
+		for _, x := range []float64{1, 2, 3} {
+			err = quantile.Push(x)
+			require.NoError(t, err)
+		}
+
+		// unlike the empirical CDF, the smallest value is the 0-quantile
+		cdf, err := quantile.CDF(1)
+		require.NoError(t, err)
+		assert.Equal(t, 0., cdf)
+
+		cdf, err = quantile.CDF(3)
+		require.NoError(t, err)
+		assert.Equal(t, 1., cdf)
+
+		// the empirical fraction of values at most x is available through Rank
+		assert.Equal(t, 0, quantile.Rank(1))
+		count, err := quantile.CountBetween(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("pass: single value has a step CDF", func(t *testing.T) {
+		quantile, err := NewGlobalQuantile()
+		require.NoError(t, err)
+
+		err = quantile.Push(1)
+		require.NoError(t, err)
+
+		cdf, err := quantile.CDF(0)
+		require.NoError(t, err)
+		assert.Equal(t, 0., cdf)
+
+		cdf, err = quantile.CDF(1)
+		require.NoError(t, err)
+		assert.Equal(t, 1., cdf)
+	})
+
+	t.Run("fail: if no values seen, return error", func(t *testing.T) {
+		quantile, err := NewGlobalQuantile()
+		require.NoError(t, err)
+
+		_, err = quantile.CDF(0)
+		testutil.ContainsError(t, err, "no values seen yet")
+	})
+}
+
+func TestQuantileSketches(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 20000
+	exact, err := NewGlobalQuantile(ImplOption(AVL))
+	require.NoError(t, err)
+
+	digest, err := NewGlobalQuantile(ImplOption(TDigest))
+	require.NoError(t, err)
+	sketch, err := NewGlobalQuantile(ImplOption(KLL, kll.RandOption(rand.New(rand.NewSource(1)))))
+	require.NoError(t, err)
+	dd, err := NewGlobalQuantile(ImplOption(DDSketch))
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		x := math.Exp(rng.NormFloat64())
+		for _, q := range []*Quantile{exact, digest, sketch, dd} {
+			require.NoError(t, q.Push(x))
+		}
+	}
+
+	for _, p := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+		expected, err := exact.Value(p)
+		require.NoError(t, err)
+
+		// DDSketch has a relative error bound on values
+		actual, err := dd.Value(p)
+		require.NoError(t, err)
+		assert.InEpsilon(t, expected, actual, 0.01, "DDSketch quantile %v", p)
+
+		// t-digests and KLL sketches have error bounds on ranks
+		for name, q := range map[string]*Quantile{"t-digest": digest, "KLL": sketch} {
+			actual, err := q.Value(p)
+			require.NoError(t, err)
+
+			rank := float64(exact.statistic.Rank(actual)) / float64(n)
+			assert.InDelta(t, p, rank, 0.0165, "%s quantile %v", name, p)
+		}
+	}
+}
+
 func TestQuantileClear(t *testing.T) {
 	quantile, err := New(3)
 	require.NoError(t, err)
@@ -289,3 +634,191 @@ func TestQuantileRLock(t *testing.T) {
 	require.NoError(t, err)
 	testutil.Approx(t, 2., val)
 }
+
+func TestQuantileConcurrency(t *testing.T) {
+	for name, options := range map[string][]Option{
+		"window":   nil,
+		"duration": {DurationOption(time.Minute)},
+	} {
+		t.Run("pass: concurrent pushes and reads do not deadlock for "+name, func(t *testing.T) {
+			window := 0
+			if options == nil {
+				window = 10
+			}
+			quantile, err := New(window, options...)
+			require.NoError(t, err)
+
+			err = quantile.Push(0)
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 16; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						x := float64(i*100 + j)
+						assert.NoError(t, quantile.Push(x))
+
+						_, err := quantile.Value(0.5)
+						assert.NoError(t, err)
+						_, err = quantile.Values(0.25, 0.75)
+						assert.NoError(t, err)
+						_, err = quantile.CDF(x)
+						assert.NoError(t, err)
+						_, err = quantile.CountBetween(0, x)
+						assert.NoError(t, err)
+						quantile.Rank(x)
+					}
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestTypedQuantile(t *testing.T) {
+	// values above 2^53 are not exactly representable as float64
+	base := int64(1) << 60
+
+	t.Run("pass: int64 values are tracked exactly", func(t *testing.T) {
+		quantile, err := NewTyped[int64](3)
+		require.NoError(t, err)
+
+		for _, offset := range []int64{1, 2, 4, 8} {
+			err = quantile.Push(base + offset)
+			require.NoError(t, err)
+		}
+
+		values, err := quantile.Values(0.25, 0.5, 0.75)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{base + 3, base + 4, base + 6}, values)
+
+		assert.Equal(t, 1, quantile.Rank(base+4))
+		count, err := quantile.CountBetween(base+2, base+4)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		cdf, err := quantile.CDF(base + 3)
+		require.NoError(t, err)
+		testutil.Approx(t, 0.25, cdf)
+	})
+
+	t.Run("pass: integer interpolation is rounded", func(t *testing.T) {
+		quantile, err := NewGlobalTypedQuantile[int64]()
+		require.NoError(t, err)
+		midpoint, err := NewGlobalTypedQuantile[int64](InterpolationOption(Midpoint))
+		require.NoError(t, err)
+
+		for _, x := range []int64{0, 3} {
+			err = quantile.Push(x)
+			require.NoError(t, err)
+			err = midpoint.Push(x)
+			require.NoError(t, err)
+		}
+
+		values, err := quantile.Values(0.1, 0.5, 0.9)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{0, 2, 3}, values)
+
+		value, err := midpoint.Value(0.5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), value)
+
+		cdf, err := midpoint.CDF(1)
+		require.NoError(t, err)
+		testutil.Approx(t, 1., cdf)
+	})
+
+	t.Run("pass: inclusive bounds handle the maximum int64 value", func(t *testing.T) {
+		quantile, err := NewGlobalTypedQuantile[int64]()
+		require.NoError(t, err)
+
+		for _, x := range []int64{0, math.MaxInt64} {
+			err = quantile.Push(x)
+			require.NoError(t, err)
+		}
+
+		count, err := quantile.CountBetween(0, math.MaxInt64)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		cdf, err := quantile.CDF(math.MaxInt64)
+		require.NoError(t, err)
+		testutil.Approx(t, 1., cdf)
+	})
+
+	t.Run("pass: interpolation handles the int64 extremes", func(t *testing.T) {
+		quantile, err := NewGlobalTypedQuantile[int64]()
+		require.NoError(t, err)
+		midpoint, err := NewGlobalTypedQuantile[int64](InterpolationOption(Midpoint))
+		require.NoError(t, err)
+
+		for _, x := range []int64{math.MinInt64, math.MaxInt64} {
+			err = quantile.Push(x)
+			require.NoError(t, err)
+			err = midpoint.Push(x)
+			require.NoError(t, err)
+		}
+
+		values, err := quantile.Values(0.25, 0.5, 0.75)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{-1 << 62, 0, 1 << 62}, values)
+
+		value, err := midpoint.Value(0.5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(-1), value)
+
+		cdf, err := quantile.CDF(0)
+		require.NoError(t, err)
+		testutil.Approx(t, 0.5, cdf)
+	})
+
+	t.Run("pass: integer midpoints are rounded down", func(t *testing.T) {
+		assert.Equal(t, int64(0), midpoint[int64](-3, 4))
+		assert.Equal(t, int64(-1), midpoint[int64](-1, 0))
+		assert.Equal(t, int64(-2), midpoint[int64](-3, 0))
+		assert.Equal(t, int64(1), midpoint[int64](0, 3))
+		assert.Equal(t, int64(-1), midpoint[int64](math.MinInt64, math.MaxInt64))
+		assert.Equal(t, int64(math.MaxInt64-1), midpoint[int64](math.MaxInt64-1, math.MaxInt64))
+		assert.Equal(t, uint64(math.MaxUint64-1), midpoint[uint64](math.MaxUint64-1, math.MaxUint64))
+	})
+
+	t.Run("pass: time.Duration values are tracked over a window", func(t *testing.T) {
+		start := time.Unix(1000, 0)
+		quantile, err := NewGlobalTypedQuantile[time.Duration](
+			ImplOption(RedBlack),
+			DurationOption(time.Minute),
+			ClockOption(testutil.NewClock(start.Add(90*time.Second))),
+		)
+		require.NoError(t, err)
+
+		latencies := []time.Duration{time.Second, 5 * time.Second, 2 * time.Second, 3 * time.Second}
+		for i, latency := range latencies {
+			err = quantile.PushAt(latency, start.Add(time.Duration(i)*30*time.Second))
+			require.NoError(t, err)
+		}
+
+		value, err := quantile.Value(0.5)
+		require.NoError(t, err)
+		assert.Equal(t, 2500*time.Millisecond, value)
+	})
+
+	t.Run("pass: float64 supports every Impl", func(t *testing.T) {
+		quantile, err := NewTyped[float64](3, ImplOption(SkipList))
+		require.NoError(t, err)
+
+		_, ok := quantile.statistic.(*skiplist.SkipList)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail: only trees support types other than float64", func(t *testing.T) {
+		_, err := NewTyped[int64](3, ImplOption(SkipList))
+		testutil.ContainsError(t, err, fmt.Sprintf("Impl %d only supports float64 values", SkipList))
+	})
+
+	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
+		_, err := NewTyped[int64](3, ImplOption(-1))
+		testutil.ContainsError(t, err, "error setting option")
+	})
+}
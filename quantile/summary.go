@@ -0,0 +1,108 @@
+package quantile
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Summary keeps track of a fixed set of quantiles of a stream using order statistics,
+// and satisfies the stream.AggregateMetric interface.
+type Summary struct {
+	quantiles []float64
+	quantile  *Quantile
+}
+
+// NewSummary instantiates a Summary struct that tracks the provided quantiles.
+func NewSummary(window int, quantiles []float64, options ...Option) (*Summary, error) {
+	if len(quantiles) == 0 {
+		return nil, errors.New("no quantiles provided")
+	}
+
+	for _, quantile := range quantiles {
+		if quantile <= 0 || quantile >= 1 {
+			return nil, errors.Errorf("quantile %f not in (0, 1)", quantile)
+		}
+	}
+
+	quantile, err := New(window, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating Quantile")
+	}
+
+	return &Summary{
+		quantiles: append([]float64{}, quantiles...),
+		quantile:  quantile,
+	}, nil
+}
+
+// NewGlobalSummary instantiates a global Summary struct.
+// This is equivalent to calling NewSummary(0, quantiles, options...).
+func NewGlobalSummary(quantiles []float64, options ...Option) (*Summary, error) {
+	return NewSummary(0, quantiles, options...)
+}
+
+// String returns a string representation of the metric.
+func (s *Summary) String() string {
+	name := "quantile.Summary"
+	quantiles := fmt.Sprintf("quantiles:%v", s.quantiles)
+	quantile := fmt.Sprintf("quantile:%v", s.quantile.String())
+	return fmt.Sprintf("%s_{%s,%s}", name, quantiles, quantile)
+}
+
+// Quantiles returns the quantiles tracked by the summary.
+func (s *Summary) Quantiles() []float64 {
+	return append([]float64{}, s.quantiles...)
+}
+
+// Push adds a number for calculating the quantiles.
+func (s *Summary) Push(x float64) error {
+	err := s.quantile.Push(x)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantile", x)
+	}
+	return nil
+}
+
+// PushAt adds a number for calculating the quantiles, which was observed
+// at the provided time; see Quantile.PushAt for details.
+func (s *Summary) PushAt(x float64, t time.Time) error {
+	err := s.quantile.PushAt(x, t)
+	if err != nil {
+		return errors.Wrapf(err, "error pushing %f to Quantile", x)
+	}
+	return nil
+}
+
+// Values returns the values of the quantiles, which are all read under a single lock;
+// in particular, it returns a map of strings to values, where the strings are the
+// quantiles formatted in their shortest representation (e.g. "0.99").
+func (s *Summary) Values() (map[string]interface{}, error) {
+	source := s.quantile.source()
+	source.rlock()
+	defer source.mux.RUnlock()
+
+	return s.values(source)
+}
+
+// values returns the values of the quantiles from the provided source of its
+// Quantile, without locking.
+func (s *Summary) values(source *Quantile) (map[string]interface{}, error) {
+	values, err := s.quantile.values(source, s.quantiles...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving quantile values")
+	}
+
+	result := make(map[string]interface{}, len(values))
+	for i, quantile := range s.quantiles {
+		result[strconv.FormatFloat(quantile, 'g', -1, 64)] = values[i]
+	}
+	return result, nil
+}
+
+// Clear resets the metric.
+func (s *Summary) Clear() {
+	s.quantile.Clear()
+}
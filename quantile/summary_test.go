@@ -0,0 +1,120 @@
+package quantile
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewSummary(t *testing.T) {
+	t.Run("pass: valid quantiles are valid", func(t *testing.T) {
+		quantiles := []float64{0.5, 0.99}
+		summary, err := NewSummary(5, quantiles, InterpolationOption(Lower))
+		require.NoError(t, err)
+		assert.Equal(t, 5, summary.quantile.window)
+		assert.Equal(t, Lower, summary.quantile.interpolation)
+		assert.Equal(t, quantiles, summary.Quantiles())
+
+		// the quantiles are copied
+		quantiles[0] = 0.1
+		assert.Equal(t, []float64{0.5, 0.99}, summary.Quantiles())
+	})
+
+	t.Run("fail: no quantiles is invalid", func(t *testing.T) {
+		_, err := NewSummary(5, nil)
+		testutil.ContainsError(t, err, "no quantiles provided")
+	})
+
+	t.Run("fail: quantile not in (0, 1) is invalid", func(t *testing.T) {
+		_, err := NewSummary(5, []float64{0.5, 0})
+		testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in (0, 1)", 0.))
+	})
+
+	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
+		_, err := NewSummary(3, []float64{0.5}, ImplOption(-1))
+		testutil.ContainsError(t, err, "error creating Quantile")
+	})
+}
+
+func TestNewGlobalSummary(t *testing.T) {
+	summary, err := NewSummary(0, []float64{0.5})
+	require.NoError(t, err)
+
+	globalSummary, err := NewGlobalSummary([]float64{0.5})
+	require.NoError(t, err)
+
+	assert.Equal(t, summary, globalSummary)
+}
+
+func TestSummaryString(t *testing.T) {
+	expectedString := fmt.Sprintf(
+		"quantile.Summary_{quantiles:[0.5 0.99],quantile:quantile.Quantile_{window:3,interpolation:%d}}",
+		Linear,
+	)
+	summary, err := NewSummary(3, []float64{0.5, 0.99})
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedString, summary.String())
+}
+
+func TestSummaryValues(t *testing.T) {
+	t.Run("pass: returns values keyed by quantile", func(t *testing.T) {
+		summary, err := NewGlobalSummary([]float64{0.25, 0.5, 0.999})
+		require.NoError(t, err)
+		assert.Implements(t, (*stream.AggregateMetric)(nil), summary)
+
+		for i := 0.; i < 5; i++ {
+			err := summary.Push(i)
+			require.NoError(t, err)
+		}
+
+		values, err := summary.Values()
+		require.NoError(t, err)
+		assert.Len(t, values, 3)
+		testutil.Approx(t, 1., values["0.25"].(float64))
+		testutil.Approx(t, 2., values["0.5"].(float64))
+		testutil.Approx(t, 3.996, values["0.999"].(float64))
+	})
+
+	t.Run("pass: pushes values at times", func(t *testing.T) {
+		start := time.Unix(0, 0)
+		clock := testutil.NewClock(start.Add(time.Second))
+		summary, err := NewGlobalSummary([]float64{0.5}, DurationOption(time.Second), ClockOption(clock))
+		require.NoError(t, err)
+
+		require.NoError(t, summary.PushAt(1, start))
+		require.NoError(t, summary.PushAt(3, start.Add(time.Second)))
+
+		values, err := summary.Values()
+		require.NoError(t, err)
+		testutil.Approx(t, 3., values["0.5"].(float64))
+	})
+
+	t.Run("fail: if no values seen, return error", func(t *testing.T) {
+		summary, err := NewGlobalSummary([]float64{0.5})
+		require.NoError(t, err)
+
+		_, err = summary.Values()
+		testutil.ContainsError(t, err, "no values seen yet")
+	})
+}
+
+func TestSummaryClear(t *testing.T) {
+	summary, err := NewSummary(3, []float64{0.5})
+	require.NoError(t, err)
+
+	for i := 0.; i < 10; i++ {
+		err = summary.Push(i * i)
+		require.NoError(t, err)
+	}
+
+	summary.Clear()
+	assert.Equal(t, uint64(0), summary.quantile.queue.Len())
+	assert.Equal(t, 0, summary.quantile.statistic.Size())
+}
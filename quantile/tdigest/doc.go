@@ -0,0 +1,13 @@
+// Package tdigest provides the implementation for t-digests, a quantile
+// sketch that is most accurate for quantiles near the tails.
+//
+// A t-digest clusters values into centroids whose sizes are bounded by a scale
+// function of their quantile; centroids near the median may contain many values,
+// while centroids near the extremes contain only a few. The number of centroids
+// is O(compression), and while there is no strict error guarantee, the error of
+// a quantile q is empirically proportional to q(1-q)/compression, so the
+// extreme quantiles are nearly exact. The minimum and maximum are tracked
+// exactly. Values cannot be removed from a t-digest, so it cannot be used over windows.
+//
+// See https://arxiv.org/abs/1902.04023 for more details.
+package tdigest
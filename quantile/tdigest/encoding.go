@@ -0,0 +1,127 @@
+package tdigest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// encodingVersion is the version of the binary format produced by
+// MarshalBinary; it is written as the first byte of the encoding so that
+// the format can evolve without silently misreading older t-digests.
+const encodingVersion uint8 = 1
+
+// encodedCentroid is the binary representation of a centroid.
+type encodedCentroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// MarshalBinary encodes the t-digest into a compact binary form, so that it
+// can be shipped elsewhere and restored with UnmarshalBinary (and potentially
+// merged into other t-digests). Any buffered values are merged into the
+// encoded centroids, without modifying the t-digest.
+// This satisfies the encoding.BinaryMarshaler interface.
+func (t *TDigest) MarshalBinary() ([]byte, error) {
+	cs := t.merged()
+	centroids := make([]encodedCentroid, len(cs))
+	for i, c := range cs {
+		centroids[i] = encodedCentroid{Mean: c.mean, Weight: c.weight}
+	}
+
+	buf := &bytes.Buffer{}
+	for _, field := range []interface{}{
+		encodingVersion,
+		t.compression,
+		int64(t.count),
+		t.min,
+		t.max,
+		uint32(len(centroids)),
+		centroids,
+	} {
+		err := binary.Write(buf, binary.BigEndian, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "error encoding TDigest")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the t-digest from data produced by MarshalBinary,
+// replacing any state the t-digest currently has.
+// This satisfies the encoding.BinaryUnmarshaler interface.
+func (t *TDigest) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return errors.Wrap(err, "error decoding encoding version")
+	} else if version != encodingVersion {
+		return errors.Errorf("unsupported encoding version %d", version)
+	}
+
+	var (
+		compression  float64
+		count        int64
+		min          float64
+		max          float64
+		numCentroids uint32
+	)
+	for _, field := range []interface{}{&compression, &count, &min, &max, &numCentroids} {
+		err := binary.Read(r, binary.BigEndian, field)
+		if err != nil {
+			return errors.Wrap(err, "error decoding TDigest")
+		}
+	}
+
+	if compression < 1 {
+		return errors.Errorf("encoded TDigest has compression %f less than 1", compression)
+	} else if 16*int(numCentroids) > r.Len() {
+		return errors.Errorf("encoded TDigest has %d centroids but only %d bytes remaining", numCentroids, r.Len())
+	}
+
+	encoded := make([]encodedCentroid, numCentroids)
+	err = binary.Read(r, binary.BigEndian, encoded)
+	if err != nil {
+		return errors.Wrap(err, "error decoding centroids")
+	}
+
+	if r.Len() != 0 {
+		return errors.Errorf("encoded TDigest has %d trailing bytes", r.Len())
+	}
+
+	var (
+		centroids []centroid
+		total     float64
+	)
+	for i, c := range encoded {
+		if c.Weight <= 0 {
+			return errors.Errorf("encoded TDigest has a nonpositive centroid weight of %f", c.Weight)
+		} else if i > 0 && c.Mean < encoded[i-1].Mean {
+			return errors.New("encoded TDigest has unsorted centroids")
+		}
+		centroids = append(centroids, centroid{mean: c.Mean, weight: c.Weight})
+		total += c.Weight
+	}
+
+	if total != float64(count) {
+		return errors.Errorf("encoded TDigest has a count of %d but a total weight of %f", count, total)
+	}
+
+	t.compression = compression
+	t.centroids = centroids
+	t.buffer = make([]float64, 0, t.bufferSize())
+	t.count = int(count)
+	t.min = math.Inf(1)
+	t.max = math.Inf(-1)
+	if count > 0 {
+		t.min = min
+		t.max = max
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package tdigest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	t.Run("pass: restored t-digest matches original", func(t *testing.T) {
+		digest, err := New(CompressionOption(50))
+		require.NoError(t, err)
+
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 1234; i++ {
+			digest.Add(rng.NormFloat64())
+		}
+
+		data, err := digest.MarshalBinary()
+		require.NoError(t, err)
+
+		restored := &TDigest{}
+		err = restored.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, digest.compression, restored.compression)
+		assert.Equal(t, digest.merged(), restored.centroids)
+		assert.Equal(t, digest.Size(), restored.Size())
+		assert.Equal(t, digest.min, restored.min)
+		assert.Equal(t, digest.max, restored.max)
+		for _, q := range []float64{0, 0.1, 0.5, 0.9, 1} {
+			expected, err := digest.Value(q)
+			require.NoError(t, err)
+			actual, err := restored.Value(q)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+
+		// marshaling should not merge the buffered values of the original
+		assert.NotEmpty(t, digest.buffer)
+	})
+
+	t.Run("pass: empty t-digest can be restored", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+
+		data, err := digest.MarshalBinary()
+		require.NoError(t, err)
+
+		restored := &TDigest{}
+		err = restored.UnmarshalBinary(data)
+		require.NoError(t, err)
+
+		restored.Add(1)
+		assert.Equal(t, 1., restored.Select(0).Value())
+	})
+
+	t.Run("fail: unsupported version is invalid", func(t *testing.T) {
+		err := (&TDigest{}).UnmarshalBinary([]byte{0})
+		testutil.ContainsError(t, err, "unsupported encoding version 0")
+	})
+
+	t.Run("fail: mismatched count is invalid", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+		digest.Add(1)
+
+		data, err := digest.MarshalBinary()
+		require.NoError(t, err)
+		// the count is encoded right after the version and compression
+		data[16]++
+
+		err = (&TDigest{}).UnmarshalBinary(data)
+		testutil.ContainsError(t, err, "encoded TDigest has a count of 2 but a total weight of 1")
+	})
+
+	t.Run("fail: trailing bytes are invalid", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+
+		data, err := digest.MarshalBinary()
+		require.NoError(t, err)
+
+		err = (&TDigest{}).UnmarshalBinary(append(data, 0))
+		testutil.ContainsError(t, err, "encoded TDigest has 1 trailing bytes")
+	})
+}
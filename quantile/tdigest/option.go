@@ -0,0 +1,25 @@
+package tdigest
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+// CompressionOption creates an option that sets the compression for a t-digest,
+// which bounds the number of centroids kept; higher compressions are more accurate.
+func CompressionOption(compression float64) order.Option {
+	return func(s order.Statistic) error {
+		var (
+			digest *TDigest
+			ok     bool
+		)
+		if digest, ok = s.(*TDigest); !ok {
+			return errors.New("attempted to set compression on a non-t-digest")
+		} else if compression < 1 {
+			return errors.Errorf("attempted to set compression %f less than 1", compression)
+		}
+		digest.compression = compression
+		return nil
+	}
+}
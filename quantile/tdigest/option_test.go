@@ -0,0 +1,37 @@
+package tdigest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexander-yu/stream/quantile/ost/rb"
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestCompressionOption(t *testing.T) {
+	t.Run("fail: non-t-digest is invalid", func(t *testing.T) {
+		err := CompressionOption(100)(&rb.Tree{})
+		testutil.ContainsError(t, err, "attempted to set compression on a non-t-digest")
+	})
+
+	t.Run("fail: compression < 1 is invalid", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+
+		err = CompressionOption(0.5)(digest)
+		testutil.ContainsError(t, err, fmt.Sprintf("attempted to set compression %f less than 1", 0.5))
+	})
+
+	t.Run("pass: valid compression is set", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+
+		err = CompressionOption(200)(digest)
+		require.NoError(t, err)
+
+		assert.Equal(t, 200., digest.compression)
+	})
+}
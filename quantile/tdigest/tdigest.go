@@ -0,0 +1,297 @@
+package tdigest
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream/quantile/order"
+)
+
+// DefaultCompression is the default compression for a t-digest.
+const DefaultCompression float64 = 100
+
+// Node represents an approximate value in a t-digest.
+type Node float64
+
+// Value returns the value stored at the node.
+func (n Node) Value() float64 {
+	return float64(n)
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest implements a merging t-digest, and also satisfies the
+// order.Statistic interface.
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []float64
+	count       int
+	min         float64
+	max         float64
+}
+
+// New instantiates a TDigest struct.
+func New(options ...order.Option) (*TDigest, error) {
+	t := &TDigest{
+		compression: DefaultCompression,
+	}
+
+	for _, option := range options {
+		err := option(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting option")
+		}
+	}
+
+	t.Clear()
+	return t, nil
+}
+
+// Compression returns the compression of the t-digest.
+func (t *TDigest) Compression() float64 {
+	return t.compression
+}
+
+func (t *TDigest) bufferSize() int {
+	return int(math.Ceil(5 * t.compression))
+}
+
+// Size returns the number of values in the t-digest.
+func (t *TDigest) Size() int {
+	return t.count
+}
+
+// Clear resets the t-digest.
+func (t *TDigest) Clear() {
+	t.centroids = nil
+	t.buffer = make([]float64, 0, t.bufferSize())
+	t.count = 0
+	t.min = math.Inf(1)
+	t.max = math.Inf(-1)
+}
+
+// Add inserts a value into the t-digest.
+func (t *TDigest) Add(val float64) {
+	t.buffer = append(t.buffer, val)
+	t.count++
+	t.min = math.Min(t.min, val)
+	t.max = math.Max(t.max, val)
+
+	if len(t.buffer) >= t.bufferSize() {
+		t.centroids = t.merged()
+		t.buffer = t.buffer[:0]
+	}
+}
+
+// Remove is a no-op, since values cannot be removed from a t-digest.
+func (t *TDigest) Remove(val float64) {}
+
+// merged returns the centroids of the t-digest after merging in the buffered
+// values, without modifying the t-digest.
+func (t *TDigest) merged() []centroid {
+	if len(t.buffer) == 0 {
+		return t.centroids
+	}
+
+	cs := make([]centroid, 0, len(t.centroids)+len(t.buffer))
+	cs = append(cs, t.centroids...)
+	for _, x := range t.buffer {
+		cs = append(cs, centroid{mean: x, weight: 1})
+	}
+	return t.compress(cs)
+}
+
+// compress sorts the given centroids and merges adjacent centroids for as long
+// as the scale function allows it.
+func (t *TDigest) compress(cs []centroid) []centroid {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].mean < cs[j].mean
+	})
+
+	total := 0.
+	for _, c := range cs {
+		total += c.weight
+	}
+
+	result := make([]centroid, 0, len(cs))
+	curr := cs[0]
+	weightSoFar := 0.
+	limit := t.kInverse(t.k(0) + 1)
+	for _, c := range cs[1:] {
+		q := (weightSoFar + curr.weight + c.weight) / total
+		if q <= limit {
+			curr.weight += c.weight
+			curr.mean += (c.mean - curr.mean) * c.weight / curr.weight
+		} else {
+			weightSoFar += curr.weight
+			result = append(result, curr)
+			limit = t.kInverse(t.k(weightSoFar/total) + 1)
+			curr = c
+		}
+	}
+
+	return append(result, curr)
+}
+
+// k is the scale function k_1(q) = delta / (2pi) * asin(2q - 1), which bounds
+// the size of each centroid so that k changes by at most 1 across a centroid.
+func (t *TDigest) k(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (t *TDigest) kInverse(k float64) float64 {
+	return (math.Sin(k*2*math.Pi/t.compression) + 1) / 2
+}
+
+// points returns the piecewise linear approximation of the distribution, as pairs
+// of positions and values; the kth smallest value is located at position k + 0.5,
+// and each centroid is located at the center of the values it contains.
+func (t *TDigest) points() (positions []float64, values []float64) {
+	cs := t.merged()
+	positions = make([]float64, 0, len(cs)+2)
+	values = make([]float64, 0, len(cs)+2)
+
+	positions = append(positions, 0.5)
+	values = append(values, t.min)
+
+	cumulative := 0.
+	for _, c := range cs {
+		positions = append(positions, cumulative+c.weight/2)
+		values = append(values, c.mean)
+		cumulative += c.weight
+	}
+
+	positions = append(positions, cumulative-0.5)
+	values = append(values, t.max)
+	return positions, values
+}
+
+// Select returns a node whose value approximates the kth smallest value in the t-digest.
+func (t *TDigest) Select(k int) order.Node {
+	if k < 0 || k >= t.count {
+		return nil
+	}
+
+	positions, values := t.points()
+	return Node(t.interpolate(positions, values, k))
+}
+
+// interpolate returns the approximate kth smallest value from the
+// piecewise linear approximation of the distribution.
+func (t *TDigest) interpolate(positions []float64, values []float64, k int) float64 {
+	target := float64(k) + 0.5
+	i := sort.SearchFloat64s(positions, target)
+	if i == len(positions) {
+		return t.max
+	} else if positions[i] == target || i == 0 {
+		return values[i]
+	}
+
+	ratio := (target - positions[i-1]) / (positions[i] - positions[i-1])
+	return values[i-1] + ratio*(values[i]-values[i-1])
+}
+
+// Rank returns the approximate number of values strictly less than the given value.
+func (t *TDigest) Rank(val float64) int {
+	if t.count == 0 || val <= t.min {
+		return 0
+	} else if val > t.max {
+		return t.count
+	}
+
+	positions, values := t.points()
+	i := sort.SearchFloat64s(values, val)
+	position := positions[i]
+	if values[i] != val {
+		ratio := (val - values[i-1]) / (values[i] - values[i-1])
+		position = positions[i-1] + ratio*(positions[i]-positions[i-1])
+	}
+
+	rank := int(math.Ceil(position - 0.5))
+	if rank < 0 {
+		return 0
+	} else if rank > t.count {
+		return t.count
+	}
+	return rank
+}
+
+// Value returns the approximate value of the quantile q of the t-digest, linearly
+// interpolating between the two nearest values if q lies between them.
+func (t *TDigest) Value(q float64) (float64, error) {
+	values, err := t.Values(q)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// Values returns the approximate values of multiple quantiles of the t-digest, in the
+// order that they were provided; this only merges the buffered values once, and so is
+// more efficient than calling Value for each quantile.
+func (t *TDigest) Values(qs ...float64) ([]float64, error) {
+	for _, q := range qs {
+		if q < 0 || q > 1 {
+			return nil, errors.Errorf("quantile %f not in [0, 1]", q)
+		}
+	}
+
+	if t.count == 0 {
+		return nil, errors.New("no values seen yet")
+	}
+
+	positions, values := t.points()
+	result := make([]float64, len(qs))
+	for i, q := range qs {
+		idx := q * float64(t.count-1)
+		lower := t.interpolate(positions, values, int(math.Floor(idx)))
+		upper := t.interpolate(positions, values, int(math.Ceil(idx)))
+		frac := idx - math.Floor(idx)
+		result[i] = (1-frac)*lower + frac*upper
+	}
+
+	return result, nil
+}
+
+// CDF returns the approximate fraction of values in the t-digest that are less than
+// or equal to the given value.
+func (t *TDigest) CDF(val float64) (float64, error) {
+	if t.count == 0 {
+		return 0, errors.New("no values seen yet")
+	}
+
+	return float64(t.Rank(math.Nextafter(val, math.Inf(1)))) / float64(t.count), nil
+}
+
+// Merge merges the values of another t-digest into the t-digest, so that the t-digest
+// summarizes the values of both t-digests; the other t-digest is unchanged. The
+// t-digests may have differing compressions, in which case the merged t-digest
+// keeps its own compression.
+func (t *TDigest) Merge(other *TDigest) error {
+	if other.count == 0 {
+		return nil
+	}
+
+	// copy the other t-digest's centroids first, which allows for a t-digest to be merged with itself
+	otherCentroids := other.merged()
+	cs := make([]centroid, 0, len(t.centroids)+len(t.buffer)+len(otherCentroids))
+	cs = append(cs, t.centroids...)
+	for _, x := range t.buffer {
+		cs = append(cs, centroid{mean: x, weight: 1})
+	}
+	cs = append(cs, otherCentroids...)
+
+	t.centroids = t.compress(cs)
+	t.buffer = t.buffer[:0]
+	t.count += other.count
+	t.min = math.Min(t.min, other.min)
+	t.max = math.Max(t.max, other.max)
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package tdigest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("fail: invalid Option is invalid", func(t *testing.T) {
+		_, err := New(CompressionOption(-1))
+		testutil.ContainsError(t, err, "error setting option")
+	})
+
+	t.Run("pass: no options is valid", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultCompression, digest.Compression())
+	})
+}
+
+func TestExact(t *testing.T) {
+	digest, err := New()
+	require.NoError(t, err)
+
+	// values are exact until the buffer is merged
+	for _, x := range []float64{5, 1, 3, 2, 4} {
+		digest.Add(x)
+	}
+
+	assert.Equal(t, 5, digest.Size())
+	for k := 0; k < 5; k++ {
+		assert.Equal(t, float64(k+1), digest.Select(k).Value())
+		assert.Equal(t, k, digest.Rank(float64(k+1)))
+	}
+	assert.Equal(t, 5, digest.Rank(6))
+	assert.Nil(t, digest.Select(5))
+
+	// removing values is unsupported
+	digest.Remove(1)
+	assert.Equal(t, 5, digest.Size())
+
+	digest.Clear()
+	assert.Equal(t, 0, digest.Size())
+	assert.Equal(t, 0, digest.Rank(1))
+	assert.Nil(t, digest.Select(0))
+}
+
+func TestAccuracy(t *testing.T) {
+	digest, err := New()
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	n := 100000
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = rng.NormFloat64()
+		digest.Add(xs[i])
+	}
+	sort.Float64s(xs)
+
+	assert.True(t, len(digest.centroids) < 10*int(DefaultCompression))
+	assert.Equal(t, xs[0], digest.Select(0).Value())
+	assert.Equal(t, xs[n-1], digest.Select(n-1).Value())
+
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999} {
+		k := int(q * float64(n))
+		// compare in rank space, where the error is bounded by q(1-q) / compression
+		rank := sort.SearchFloat64s(xs, digest.Select(k).Value())
+		bound := math.Max(4*q*(1-q)/DefaultCompression, 20/float64(n))
+		assert.InDelta(t, q, float64(rank)/float64(n), bound, "quantile %v", q)
+		assert.InDelta(t, q, float64(digest.Rank(xs[k]))/float64(n), bound, "rank of quantile %v", q)
+	}
+}
+
+func TestValue(t *testing.T) {
+	digest, err := New()
+	require.NoError(t, err)
+
+	_, err = digest.Value(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for i := 1; i <= 5; i++ {
+		digest.Add(float64(i))
+	}
+
+	_, err = digest.Value(-0.5)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", -0.5))
+
+	for q, expected := range map[float64]float64{0: 1, 0.5: 3, 0.625: 3.5, 1: 5} {
+		val, err := digest.Value(q)
+		require.NoError(t, err)
+		assert.Equal(t, expected, val)
+	}
+}
+
+func TestCDF(t *testing.T) {
+	digest, err := New()
+	require.NoError(t, err)
+
+	_, err = digest.CDF(0)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	for _, x := range []float64{1, 2, 2, 3, 4} {
+		digest.Add(x)
+	}
+
+	for x, expected := range map[float64]float64{0: 0, 1: 0.2, 2: 0.6, 3.5: 0.8, 4: 1, 5: 1} {
+		cdf, err := digest.CDF(x)
+		require.NoError(t, err)
+		assert.Equal(t, expected, cdf, "CDF of %v", x)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("pass: merged t-digest approximates all values", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		n := 10000
+		xs := make([]float64, 0, 4*n)
+		merged, err := New()
+		require.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			shard, err := New()
+			require.NoError(t, err)
+			for j := 0; j < n; j++ {
+				x := rng.NormFloat64() + float64(i)
+				shard.Add(x)
+				xs = append(xs, x)
+			}
+			require.NoError(t, merged.Merge(shard))
+		}
+		sort.Float64s(xs)
+
+		assert.Equal(t, len(xs), merged.Size())
+		assert.Equal(t, xs[0], merged.Select(0).Value())
+		assert.Equal(t, xs[len(xs)-1], merged.Select(len(xs)-1).Value())
+		for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+			val, err := merged.Value(q)
+			require.NoError(t, err)
+			rank := float64(sort.SearchFloat64s(xs, val)) / float64(len(xs))
+			assert.InDelta(t, q, rank, math.Max(4*q*(1-q)/DefaultCompression, 0.001), "quantile %v", q)
+		}
+	})
+
+	t.Run("pass: t-digest can be merged with itself", func(t *testing.T) {
+		digest, err := New()
+		require.NoError(t, err)
+		digest.Add(1)
+		digest.Add(2)
+
+		require.NoError(t, digest.Merge(digest))
+		assert.Equal(t, 4, digest.Size())
+		assert.Equal(t, 2, digest.Rank(2))
+		assert.Equal(t, 1., digest.Select(1).Value())
+	})
+}
+
+func TestValues(t *testing.T) {
+	sketch, err := New()
+	require.NoError(t, err)
+
+	_, err = sketch.Values(0.5)
+	testutil.ContainsError(t, err, "no values seen yet")
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		sketch.Add(rng.Float64())
+	}
+
+	_, err = sketch.Values(0.5, -1)
+	testutil.ContainsError(t, err, fmt.Sprintf("quantile %f not in [0, 1]", -1.))
+
+	qs := []float64{0.999, 0.5, 0.9, 0.95, 0.99}
+	values, err := sketch.Values(qs...)
+	require.NoError(t, err)
+	for i, q := range qs {
+		expected, err := sketch.Value(q)
+		require.NoError(t, err)
+		assert.Equal(t, expected, values[i])
+	}
+}
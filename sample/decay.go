@@ -0,0 +1,145 @@
+package sample
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// DecayReservoir is a struct for performing biased reservoir sampling on a stream
+// with forward decay (Cormode et al.), where more recent values are more likely to
+// be sampled. In particular, each value is weighted by 2^((t - L) / h), where t is
+// the time it was pushed at, L is the time of the first value, and h is the
+// half-life; values are then sampled without replacement in proportion to their
+// weights, as in a WeightedReservoir. This means that a value is twice as likely
+// to be sampled as a value pushed one half-life before it. It satisfies the
+// Sampler interface.
+type DecayReservoir[T any] struct {
+	size     int
+	halfLife time.Duration
+	clock    stream.Clock
+	heap     keyHeap[T]
+	count    int
+	landmark time.Time
+	rand     *rand.Rand
+	mux      sync.Mutex
+}
+
+// NewDecayReservoir instantiates a DecayReservoir struct that keeps a sample of
+// at most size values, whose weights double for every half-life. Values pushed
+// without an explicit time are timestamped with the provided Clock, which defaults
+// to stream.SystemClock if nil. Samples are drawn with the provided random number
+// generator, which defaults to one seeded with the current time if nil.
+func NewDecayReservoir[T any](
+	size int,
+	halfLife time.Duration,
+	clock stream.Clock,
+	rng *rand.Rand,
+) (*DecayReservoir[T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	} else if halfLife <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive half-life", halfLife)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &DecayReservoir[T]{
+		size:     size,
+		halfLife: halfLife,
+		clock:    clock,
+		heap:     make(keyHeap[T], 0, size),
+		rand:     rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *DecayReservoir[T]) String() string {
+	return fmt.Sprintf("sample.DecayReservoir_{size:%d,halfLife:%v}", r.size, r.halfLife)
+}
+
+// Size returns the maximum size of the sample.
+func (r *DecayReservoir[T]) Size() int {
+	return r.size
+}
+
+// Push consumes a value to perform sampling, which is timestamped with the
+// current time of its Clock.
+func (r *DecayReservoir[T]) Push(x T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.push(x, r.clock.Now())
+}
+
+// PushAt consumes a value to perform sampling, which was observed at the
+// provided time. Unlike windowed samplers, values do not need to be pushed
+// in chronological order.
+func (r *DecayReservoir[T]) PushAt(x T, t time.Time) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.push(x, t)
+}
+
+func (r *DecayReservoir[T]) push(x T, t time.Time) {
+	if r.count == 0 {
+		r.landmark = t
+	}
+	r.count++
+
+	// the key of a value with weight w is log(w) - log(-log(u)), which orders values
+	// in the same way as u^(1/w) does for weighted sampling, but does not overflow
+	// as the weights grow exponentially
+	u := r.rand.Float64()
+	for u == 0 {
+		u = r.rand.Float64()
+	}
+	key := math.Ln2*float64(t.Sub(r.landmark))/float64(r.halfLife) - math.Log(-math.Log(u))
+
+	if len(r.heap) < r.size {
+		heap.Push(&r.heap, keyed[T]{val: x, key: key})
+	} else if key > r.heap[0].key {
+		r.heap[0] = keyed[T]{val: x, key: key}
+		heap.Fix(&r.heap, 0)
+	}
+}
+
+// Sample returns a copied slice of the obtained sample, in no particular order.
+func (r *DecayReservoir[T]) Sample() []T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	sample := make([]T, len(r.heap))
+	for i, item := range r.heap {
+		sample[i] = item.val
+	}
+	return sample
+}
+
+// Count returns the number of values seen by the sampler.
+func (r *DecayReservoir[T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *DecayReservoir[T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.heap = make(keyHeap[T], 0, r.size)
+	r.count = 0
+	r.landmark = time.Time{}
+}
@@ -0,0 +1,89 @@
+package sample
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewDecayReservoir(t *testing.T) {
+	t.Run("pass: valid DecayReservoir is valid", func(t *testing.T) {
+		r, err := NewDecayReservoir[int](3, time.Minute, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.clock)
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, "sample.DecayReservoir_{size:3,halfLife:1m0s}", r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewDecayReservoir[int](0, time.Minute, nil, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive size")
+	})
+
+	t.Run("fail: nonpositive half-life returns error", func(t *testing.T) {
+		_, err := NewDecayReservoir[int](3, 0, nil, nil)
+		testutil.ContainsError(t, err, "0s is a nonpositive half-life")
+	})
+}
+
+func TestDecayReservoirInclusionProbabilities(t *testing.T) {
+	start := time.Unix(1000, 0)
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]int, 3)
+	trials := 20000
+	for i := 0; i < trials; i++ {
+		r, err := NewDecayReservoir[int](1, time.Minute, nil, rng)
+		require.NoError(t, err)
+
+		// the values have weights of 1, 2 and 4 respectively
+		for j := 0; j < 3; j++ {
+			r.PushAt(j, start.Add(time.Duration(j)*time.Minute))
+		}
+
+		for _, x := range r.Sample() {
+			counts[x]++
+		}
+	}
+
+	for x, count := range counts {
+		expected := float64(int(1)<<uint(x)) / 7
+		assert.InDelta(t, expected, float64(count)/float64(trials), 0.02)
+	}
+}
+
+func TestDecayReservoirLongStreams(t *testing.T) {
+	clock := testutil.NewClock(time.Unix(1000, 0))
+	r, err := NewDecayReservoir[int](10, time.Second, clock, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	// the weights span far more than the range of a float64,
+	// so only the most recent values should be sampled
+	for i := 0; i < 10000; i++ {
+		r.Push(i)
+		clock.Advance(time.Minute)
+	}
+
+	assert.ElementsMatch(t, []int{9990, 9991, 9992, 9993, 9994, 9995, 9996, 9997, 9998, 9999}, r.Sample())
+	assert.Equal(t, 10000, r.Count())
+}
+
+func TestDecayReservoirClear(t *testing.T) {
+	r, err := NewDecayReservoir[int](3, time.Minute, nil, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		r.Push(i)
+	}
+
+	r.Clear()
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, []int{}, r.Sample())
+	assert.Equal(t, time.Time{}, r.landmark)
+}
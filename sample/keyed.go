@@ -0,0 +1,166 @@
+package sample
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// keyedEntry is the reservoir of a single key in a KeyedReservoir.
+type keyedEntry[K comparable, T any] struct {
+	key       K
+	reservoir *Reservoir[T]
+}
+
+// KeyedReservoir is a struct for performing stratified reservoir sampling on a
+// stream, where a separate uniform sample of a fixed size is kept for each key
+// (e.g. per customer or per endpoint). The total number of sampled values across
+// all keys is bounded by a fixed capacity; once this is exceeded, the keys that
+// were least recently pushed to are evicted, along with their samples.
+type KeyedReservoir[K comparable, T any] struct {
+	size     int
+	capacity int
+	entries  map[K]*list.Element
+	lru      *list.List
+	total    int
+	count    int
+	rand     *rand.Rand
+	mux      sync.Mutex
+}
+
+// NewKeyedReservoir instantiates a KeyedReservoir struct that keeps a sample of
+// at most size values for each key, and at most capacity values across all keys;
+// the capacity must be at least the size. Samples are drawn with the provided
+// random number generator, which defaults to one seeded with the current time if nil.
+func NewKeyedReservoir[K comparable, T any](size int, capacity int, rng *rand.Rand) (*KeyedReservoir[K, T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	} else if capacity < size {
+		return nil, errors.Errorf("capacity %d is less than size %d", capacity, size)
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &KeyedReservoir[K, T]{
+		size:     size,
+		capacity: capacity,
+		entries:  map[K]*list.Element{},
+		lru:      list.New(),
+		rand:     rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *KeyedReservoir[K, T]) String() string {
+	return fmt.Sprintf("sample.KeyedReservoir_{size:%d,capacity:%d}", r.size, r.capacity)
+}
+
+// Size returns the maximum size of the sample of each key.
+func (r *KeyedReservoir[K, T]) Size() int {
+	return r.size
+}
+
+// Capacity returns the maximum number of sampled values across all keys.
+func (r *KeyedReservoir[K, T]) Capacity() int {
+	return r.capacity
+}
+
+// Push consumes a value for the provided key to perform reservoir sampling.
+func (r *KeyedReservoir[K, T]) Push(key K, x T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.count++
+
+	element, ok := r.entries[key]
+	if ok {
+		r.lru.MoveToFront(element)
+	} else {
+		// the reservoirs of each key share the random number generator, and grow
+		// their samples as needed rather than allocating them up front
+		element = r.lru.PushFront(&keyedEntry[K, T]{
+			key:       key,
+			reservoir: &Reservoir[T]{size: r.size, rand: r.rand},
+		})
+		r.entries[key] = element
+	}
+
+	reservoir := element.Value.(*keyedEntry[K, T]).reservoir
+	before := len(reservoir.sample)
+	reservoir.push(x)
+	r.total += len(reservoir.sample) - before
+
+	// the capacity is at least the size, so the key that was just pushed to is never evicted
+	for r.total > r.capacity {
+		r.evict(r.lru.Back())
+	}
+}
+
+func (r *KeyedReservoir[K, T]) evict(element *list.Element) {
+	entry := r.lru.Remove(element).(*keyedEntry[K, T])
+	delete(r.entries, entry.key)
+	r.total -= len(entry.reservoir.sample)
+}
+
+// Sample returns a snapshot of the samples of each key, where each sample is a copied slice.
+func (r *KeyedReservoir[K, T]) Sample() map[K][]T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	samples := make(map[K][]T, len(r.entries))
+	for key, element := range r.entries {
+		samples[key] = copySample(element.Value.(*keyedEntry[K, T]).reservoir.sample)
+	}
+	return samples
+}
+
+// SampleOf returns a copied slice of the sample of the provided key, along with
+// whether or not the key is currently being sampled. This does not count as a use
+// of the key for the purposes of eviction.
+func (r *KeyedReservoir[K, T]) SampleOf(key K) ([]T, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return copySample(element.Value.(*keyedEntry[K, T]).reservoir.sample), true
+}
+
+func copySample[T any](sample []T) []T {
+	result := make([]T, len(sample))
+	copy(result, sample)
+	return result
+}
+
+// Keys returns the number of keys currently being sampled.
+func (r *KeyedReservoir[K, T]) Keys() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return len(r.entries)
+}
+
+// Count returns the number of values seen by the sampler across all keys,
+// including those of keys that have since been evicted.
+func (r *KeyedReservoir[K, T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *KeyedReservoir[K, T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.entries = map[K]*list.Element{}
+	r.lru.Init()
+	r.total = 0
+	r.count = 0
+}
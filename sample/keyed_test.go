@@ -0,0 +1,114 @@
+package sample
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewKeyedReservoir(t *testing.T) {
+	t.Run("pass: valid KeyedReservoir is valid", func(t *testing.T) {
+		r, err := NewKeyedReservoir[string, int](3, 10, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 10, r.Capacity())
+		assert.Equal(t, 0, r.Keys())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, "sample.KeyedReservoir_{size:3,capacity:10}", r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewKeyedReservoir[string, int](0, 10, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive size")
+	})
+
+	t.Run("fail: capacity less than size returns error", func(t *testing.T) {
+		_, err := NewKeyedReservoir[string, int](3, 2, nil)
+		testutil.ContainsError(t, err, "capacity 2 is less than size 3")
+	})
+}
+
+func TestKeyedReservoirPush(t *testing.T) {
+	r, err := NewKeyedReservoir[string, int](3, 100, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		r.Push("a", i)
+	}
+	r.Push("b", 1)
+	r.Push("b", 2)
+
+	// the sample of each key is the same as that of a Reservoir with the same random numbers
+	assert.Equal(t, map[string][]int{
+		"a": testReservoir(t).Sample(),
+		"b": {1, 2},
+	}, r.Sample())
+	assert.Equal(t, 2, r.Keys())
+	assert.Equal(t, 12, r.Count())
+
+	sample, ok := r.SampleOf("b")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 2}, sample)
+
+	_, ok = r.SampleOf("c")
+	assert.False(t, ok)
+}
+
+func TestKeyedReservoirEviction(t *testing.T) {
+	r, err := NewKeyedReservoir[string, int](2, 5, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	r.Push("a", 1)
+	r.Push("a", 2)
+	r.Push("b", 1)
+	r.Push("b", 2)
+	r.Push("c", 1)
+	assert.Equal(t, 3, r.Keys())
+
+	// "a" is used more recently than "b", so "b" is evicted once the capacity is exceeded
+	r.Push("a", 3)
+	r.Push("c", 2)
+	assert.Equal(t, 2, r.Keys())
+	_, ok := r.SampleOf("b")
+	assert.False(t, ok)
+	assert.Equal(t, 4, r.total)
+
+	// evicted keys start over with an empty sample
+	r.Push("b", 3)
+	sample, ok := r.SampleOf("b")
+	assert.True(t, ok)
+	assert.Equal(t, []int{3}, sample)
+	assert.Equal(t, 5, r.total)
+	assert.Equal(t, 3, r.Keys())
+}
+
+func TestKeyedReservoirSampleIsCopied(t *testing.T) {
+	r, err := NewKeyedReservoir[string, int](2, 5, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	r.Push("a", 1)
+	samples := r.Sample()
+	samples["a"][0] = 2
+
+	sample, _ := r.SampleOf("a")
+	assert.Equal(t, []int{1}, sample)
+}
+
+func TestKeyedReservoirClear(t *testing.T) {
+	r, err := NewKeyedReservoir[string, int](2, 5, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	r.Push("a", 1)
+	r.Push("b", 1)
+
+	r.Clear()
+	assert.Equal(t, 0, r.Keys())
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, 0, r.total)
+	assert.Equal(t, map[string][]int{}, r.Sample())
+}
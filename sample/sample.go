@@ -1,37 +1,101 @@
 package sample
 
 import (
+	"fmt"
 	"math/rand"
 	"sync"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
-// Reservoir is a struct for performing reservoir sampling on a stream.
-type Reservoir struct {
-	Size   int
-	sample []interface{}
+// Reservoir is a struct for performing uniform reservoir sampling on a stream
+// with Algorithm R, and satisfies the Sampler interface.
+type Reservoir[T any] struct {
+	size   int
+	sample []T
 	count  int
+	rand   *rand.Rand
 	mux    sync.Mutex
 }
 
+// NewReservoir instantiates a Reservoir struct that keeps a sample of at most
+// size values. Samples are drawn with the provided random number generator,
+// which defaults to one seeded with the current time if nil.
+func NewReservoir[T any](size int, rng *rand.Rand) (*Reservoir[T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &Reservoir[T]{
+		size:   size,
+		sample: make([]T, 0, size),
+		rand:   rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *Reservoir[T]) String() string {
+	return fmt.Sprintf("sample.Reservoir_{size:%d}", r.size)
+}
+
+// Size returns the maximum size of the sample.
+func (r *Reservoir[T]) Size() int {
+	return r.size
+}
+
 // Push consumes a value to perform reservoir sampling.
-func (r *Reservoir) Push(x interface{}) {
+func (r *Reservoir[T]) Push(x T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.push(x)
+}
+
+// PushMany consumes multiple values to perform reservoir sampling, in the
+// order that they were provided; this only locks the sampler once.
+func (r *Reservoir[T]) PushMany(xs ...T) {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 
+	for _, x := range xs {
+		r.push(x)
+	}
+}
+
+func (r *Reservoir[T]) push(x T) {
 	r.count++
-	if r.count <= r.Size {
+	if r.count <= r.size {
 		r.sample = append(r.sample, x)
-	} else if index := rand.Intn(r.count); index < r.Size {
+	} else if index := r.rand.Intn(r.count); index < r.size {
 		r.sample[index] = x
 	}
 }
 
 // Sample returns a copied slice of the obtained sample.
-func (r *Reservoir) Sample() []interface{} {
+func (r *Reservoir[T]) Sample() []T {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 
-	sample := make([]interface{}, len(r.sample))
+	sample := make([]T, len(r.sample))
 	copy(sample, r.sample)
 	return sample
 }
+
+// Count returns the number of values seen by the sampler.
+func (r *Reservoir[T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *Reservoir[T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.sample = make([]T, 0, r.size)
+	r.count = 0
+}
@@ -5,11 +5,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
 )
 
-func testReservoir() *Reservoir {
-	r := &Reservoir{Size: 3}
-	rand.Seed(1)
+func testReservoir(t *testing.T) *Reservoir[int] {
+	r, err := NewReservoir[int](3, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
 
 	for i := 0; i < 10; i++ {
 		r.Push(i)
@@ -18,18 +21,83 @@ func testReservoir() *Reservoir {
 	return r
 }
 
+func TestNewReservoir(t *testing.T) {
+	t.Run("pass: valid Reservoir is valid", func(t *testing.T) {
+		r, err := NewReservoir[string](3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, "sample.Reservoir_{size:3}", r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewReservoir[int](0, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive size")
+	})
+}
+
 func TestReservoirPush(t *testing.T) {
-	r := testReservoir()
-	assert.Equal(t, []interface{}{6, 7, 4}, r.sample)
+	r := testReservoir(t)
+	assert.Equal(t, []int{6, 7, 4}, r.sample)
+	assert.Equal(t, 10, r.Count())
+}
+
+func TestReservoirPushIsUniform(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]int, 10)
+	trials := 10000
+	for i := 0; i < trials; i++ {
+		r, err := NewReservoir[int](3, rng)
+		require.NoError(t, err)
+
+		for j := 0; j < 10; j++ {
+			r.Push(j)
+		}
+		for _, x := range r.Sample() {
+			counts[x]++
+		}
+	}
+
+	// each value should be sampled with probability 3/10
+	for _, count := range counts {
+		assert.InDelta(t, 0.3, float64(count)/float64(trials), 0.02)
+	}
 }
 
 func TestSample(t *testing.T) {
-	r := testReservoir()
+	r := testReservoir(t)
 	sample := r.Sample()
 
-	assert.Equal(t, []interface{}{6, 7, 4}, sample)
+	assert.Equal(t, []int{6, 7, 4}, sample)
 
 	sample[0] = 1
 
-	assert.Equal(t, []interface{}{6, 7, 4}, r.sample)
+	assert.Equal(t, []int{6, 7, 4}, r.sample)
+}
+
+func TestReservoirClear(t *testing.T) {
+	r := testReservoir(t)
+	r.Clear()
+
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, []int{}, r.Sample())
+
+	r.Push(1)
+	assert.Equal(t, []int{1}, r.Sample())
+}
+
+func TestReservoirIsSampler(t *testing.T) {
+	var _ Sampler[int] = testReservoir(t)
+}
+
+func TestReservoirPushMany(t *testing.T) {
+	r, err := NewReservoir[int](3, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	r.PushMany(0, 1, 2, 3, 4)
+	r.PushMany(5, 6, 7, 8, 9)
+
+	assert.Equal(t, testReservoir(t).Sample(), r.Sample())
+	assert.Equal(t, 10, r.Count())
 }
@@ -0,0 +1,11 @@
+package sample
+
+// Sampler is the interface shared by the samplers in this package, which
+// maintain a sample of the values of type T seen in a stream. Since samplers
+// differ in what they consume (e.g. weighted samplers also consume a weight
+// with each value), Push is left to the implementations.
+type Sampler[T any] interface {
+	Sample() []T
+	Count() int
+	Clear()
+}
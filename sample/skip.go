@@ -0,0 +1,155 @@
+package sample
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SkipReservoir is a struct for performing uniform reservoir sampling on a stream
+// with Algorithm L (Li, 1994), and satisfies the Sampler interface. Rather than
+// drawing a random number for every value as Reservoir does, it draws the number
+// of values to skip before the next value that is inserted into the sample, which
+// follows a geometric distribution; this means that only O(k log(n/k)) random
+// numbers are drawn for a stream of n values and a sample of size k.
+type SkipReservoir[T any] struct {
+	size   int
+	sample []T
+	count  int
+	// w is the largest of k random numbers drawn uniformly from (0, 1), which
+	// determines the distribution of the number of values to skip
+	w float64
+	// next is the count at which the next value is inserted into the sample
+	next int
+	rand *rand.Rand
+	mux  sync.Mutex
+}
+
+// NewSkipReservoir instantiates a SkipReservoir struct that keeps a sample of at
+// most size values. Samples are drawn with the provided random number generator,
+// which defaults to one seeded with the current time if nil.
+func NewSkipReservoir[T any](size int, rng *rand.Rand) (*SkipReservoir[T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &SkipReservoir[T]{
+		size:   size,
+		sample: make([]T, 0, size),
+		w:      1,
+		rand:   rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *SkipReservoir[T]) String() string {
+	return fmt.Sprintf("sample.SkipReservoir_{size:%d}", r.size)
+}
+
+// Size returns the maximum size of the sample.
+func (r *SkipReservoir[T]) Size() int {
+	return r.size
+}
+
+// uniform returns a number drawn uniformly from (0, 1).
+func (r *SkipReservoir[T]) uniform() float64 {
+	for {
+		if u := r.rand.Float64(); u > 0 {
+			return u
+		}
+	}
+}
+
+// advance draws the count at which the next value is inserted into the sample.
+func (r *SkipReservoir[T]) advance() {
+	r.w *= math.Exp(math.Log(r.uniform()) / float64(r.size))
+	skip := math.Floor(math.Log(r.uniform()) / math.Log1p(-r.w))
+	if skip >= float64(math.MaxInt64-r.count) {
+		r.next = math.MaxInt64
+		return
+	}
+	r.next = r.count + int(skip) + 1
+}
+
+// Push consumes a value to perform reservoir sampling.
+func (r *SkipReservoir[T]) Push(x T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.count++
+	if r.count <= r.size {
+		r.sample = append(r.sample, x)
+		if r.count == r.size {
+			r.advance()
+		}
+	} else if r.count == r.next {
+		r.sample[r.rand.Intn(r.size)] = x
+		r.advance()
+	}
+}
+
+// PushMany consumes multiple values to perform reservoir sampling, in the order
+// that they were provided. This only locks the sampler once, and jumps directly
+// to the values that are inserted into the sample, so its time is proportional
+// to the number of insertions rather than the number of values.
+func (r *SkipReservoir[T]) PushMany(xs ...T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for len(xs) > 0 && r.count < r.size {
+		r.sample = append(r.sample, xs[0])
+		r.count++
+		xs = xs[1:]
+		if r.count == r.size {
+			r.advance()
+		}
+	}
+
+	for len(xs) > 0 {
+		gap := r.next - r.count
+		if gap > len(xs) {
+			r.count += len(xs)
+			return
+		}
+
+		r.count += gap
+		r.sample[r.rand.Intn(r.size)] = xs[gap-1]
+		r.advance()
+		xs = xs[gap:]
+	}
+}
+
+// Sample returns a copied slice of the obtained sample.
+func (r *SkipReservoir[T]) Sample() []T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	sample := make([]T, len(r.sample))
+	copy(sample, r.sample)
+	return sample
+}
+
+// Count returns the number of values seen by the sampler.
+func (r *SkipReservoir[T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *SkipReservoir[T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.sample = make([]T, 0, r.size)
+	r.count = 0
+	r.w = 1
+	r.next = 0
+}
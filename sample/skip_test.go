@@ -0,0 +1,154 @@
+package sample
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewSkipReservoir(t *testing.T) {
+	t.Run("pass: valid SkipReservoir is valid", func(t *testing.T) {
+		r, err := NewSkipReservoir[int](3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, "sample.SkipReservoir_{size:3}", r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewSkipReservoir[int](0, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive size")
+	})
+}
+
+func TestSkipReservoirPushIsUniform(t *testing.T) {
+	for _, batched := range []bool{false, true} {
+		t.Run(fmt.Sprintf("pass: sample is uniform with batching %v", batched), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			xs := make([]int, 20)
+			for i := range xs {
+				xs[i] = i
+			}
+
+			counts := make([]int, len(xs))
+			trials := 10000
+			for i := 0; i < trials; i++ {
+				r, err := NewSkipReservoir[int](5, rng)
+				require.NoError(t, err)
+
+				if batched {
+					r.PushMany(xs[:7]...)
+					r.PushMany(xs[7:]...)
+				} else {
+					for _, x := range xs {
+						r.Push(x)
+					}
+				}
+
+				assert.Equal(t, len(xs), r.Count())
+				for _, x := range r.Sample() {
+					counts[x]++
+				}
+			}
+
+			// each value should be sampled with probability 5/20
+			for _, count := range counts {
+				assert.InDelta(t, 0.25, float64(count)/float64(trials), 0.02)
+			}
+		})
+	}
+}
+
+func TestSkipReservoirPushMany(t *testing.T) {
+	// Push and PushMany draw the same random numbers, and so produce the same sample
+	r, err := NewSkipReservoir[int](10, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	batched, err := NewSkipReservoir[int](10, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	xs := make([]int, 0, 1000)
+	for i := 0; i < 10000; i++ {
+		r.Push(i)
+		xs = append(xs, i)
+		if len(xs) == cap(xs) {
+			batched.PushMany(xs...)
+			xs = xs[:0]
+		}
+	}
+
+	assert.Equal(t, r.Sample(), batched.Sample())
+	assert.Equal(t, r.Count(), batched.Count())
+}
+
+func TestSkipReservoirClear(t *testing.T) {
+	r, err := NewSkipReservoir[int](3, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	r.PushMany(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	r.Clear()
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, []int{}, r.Sample())
+
+	r.Push(1)
+	assert.Equal(t, []int{1}, r.Sample())
+}
+
+func BenchmarkReservoirPush(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		rng := rand.New(rand.NewSource(1))
+
+		r, err := NewReservoir[int](size, rng)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("Algorithm R [%d]", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r.Push(i)
+			}
+		})
+
+		skip, err := NewSkipReservoir[int](size, rng)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("Algorithm L [%d]", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				skip.Push(i)
+			}
+		})
+	}
+}
+
+func BenchmarkReservoirPushMany(b *testing.B) {
+	batch := make([]int, 1024)
+	for i := range batch {
+		batch[i] = i
+	}
+
+	for _, size := range []int{10, 100, 1000} {
+		rng := rand.New(rand.NewSource(1))
+
+		r, err := NewReservoir[int](size, rng)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("Algorithm R [%d]", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r.PushMany(batch...)
+			}
+		})
+
+		skip, err := NewSkipReservoir[int](size, rng)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("Algorithm L [%d]", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				skip.PushMany(batch...)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package sample
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WeightedAlgorithm represents an enum that enumerates the supported
+// algorithms for weighted reservoir sampling.
+type WeightedAlgorithm int
+
+const (
+	// ARes represents the A-Res algorithm of Efraimidis and Spirakis, which
+	// draws a random key for every value.
+	ARes WeightedAlgorithm = iota
+	// AExpJ represents the A-ExpJ algorithm of Efraimidis and Spirakis, which
+	// draws exponential jumps over the total weight of values that are skipped,
+	// and so only draws random numbers for values that are inserted into the sample.
+	AExpJ
+)
+
+// Valid returns whether or not the WeightedAlgorithm value is a valid value.
+func (a WeightedAlgorithm) Valid() bool {
+	switch a {
+	case ARes, AExpJ:
+		return true
+	default:
+		return false
+	}
+}
+
+// keyed is a value in a weighted sample, along with its random key;
+// the values with the largest keys are sampled.
+type keyed[T any] struct {
+	val T
+	key float64
+}
+
+// keyHeap is a min-heap of keyed values, ordered by their keys.
+type keyHeap[T any] []keyed[T]
+
+func (h keyHeap[T]) Len() int            { return len(h) }
+func (h keyHeap[T]) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h keyHeap[T]) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap[T]) Push(x interface{}) { *h = append(*h, x.(keyed[T])) }
+func (h *keyHeap[T]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// WeightedReservoir is a struct for performing weighted reservoir sampling
+// without replacement on a stream, where the probability of a value being
+// sampled is proportional to its weight; in particular, a sample of size 1
+// contains each value with probability equal to its weight divided by the
+// total weight. It satisfies the Sampler interface.
+type WeightedReservoir[T any] struct {
+	size      int
+	algorithm WeightedAlgorithm
+	heap      keyHeap[T]
+	count     int
+	// remaining is the weight left to skip before the next insertion (A-ExpJ only)
+	remaining float64
+	rand      *rand.Rand
+	mux       sync.Mutex
+}
+
+// NewWeightedReservoir instantiates a WeightedReservoir struct that keeps a
+// sample of at most size values with the provided algorithm. Samples are drawn
+// with the provided random number generator, which defaults to one seeded with
+// the current time if nil.
+func NewWeightedReservoir[T any](size int, algorithm WeightedAlgorithm, rng *rand.Rand) (*WeightedReservoir[T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	} else if !algorithm.Valid() {
+		return nil, errors.Errorf("%d is not a valid WeightedAlgorithm", algorithm)
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &WeightedReservoir[T]{
+		size:      size,
+		algorithm: algorithm,
+		heap:      make(keyHeap[T], 0, size),
+		rand:      rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *WeightedReservoir[T]) String() string {
+	return fmt.Sprintf("sample.WeightedReservoir_{size:%d,algorithm:%d}", r.size, r.algorithm)
+}
+
+// Size returns the maximum size of the sample.
+func (r *WeightedReservoir[T]) Size() int {
+	return r.size
+}
+
+// uniform returns a number drawn uniformly from (0, 1).
+func (r *WeightedReservoir[T]) uniform() float64 {
+	for {
+		if u := r.rand.Float64(); u > 0 {
+			return u
+		}
+	}
+}
+
+// Push consumes a value with the provided weight to perform weighted reservoir
+// sampling; the weight must be positive and finite.
+func (r *WeightedReservoir[T]) Push(x T, weight float64) error {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return errors.Errorf("%f is not a positive finite weight", weight)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.count++
+	// in order to avoid underflow for small weights, the key u^(1/w) of a value
+	// with weight w is stored as log(u) / w, where u is drawn uniformly from (0, 1)
+	if len(r.heap) < r.size {
+		heap.Push(&r.heap, keyed[T]{val: x, key: math.Log(r.uniform()) / weight})
+		if len(r.heap) == r.size && r.algorithm == AExpJ {
+			r.jump()
+		}
+		return nil
+	}
+
+	switch r.algorithm {
+	case ARes:
+		if key := math.Log(r.uniform()) / weight; key > r.heap[0].key {
+			r.heap[0] = keyed[T]{val: x, key: key}
+			heap.Fix(&r.heap, 0)
+		}
+	case AExpJ:
+		r.remaining -= weight
+		if r.remaining <= 0 {
+			// the key of the inserted value is drawn conditioned on being greater than
+			// the minimum key, i.e. u^(1/w) is drawn uniformly from (t^w, 1)^(1/w),
+			// where t is the minimum key
+			t := math.Exp(r.heap[0].key * weight)
+			u := t + (1-t)*r.uniform()
+			r.heap[0] = keyed[T]{val: x, key: math.Log(u) / weight}
+			heap.Fix(&r.heap, 0)
+			r.jump()
+		}
+	}
+
+	return nil
+}
+
+// jump draws the total weight of the values to skip before the next insertion,
+// which is log(u) / log(t), where t is the minimum key.
+func (r *WeightedReservoir[T]) jump() {
+	r.remaining = math.Log(r.uniform()) / r.heap[0].key
+}
+
+// Sample returns a copied slice of the obtained sample, in no particular order.
+func (r *WeightedReservoir[T]) Sample() []T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	sample := make([]T, len(r.heap))
+	for i, item := range r.heap {
+		sample[i] = item.val
+	}
+	return sample
+}
+
+// Count returns the number of values seen by the sampler.
+func (r *WeightedReservoir[T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *WeightedReservoir[T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.heap = make(keyHeap[T], 0, r.size)
+	r.count = 0
+	r.remaining = 0
+}
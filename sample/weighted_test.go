@@ -0,0 +1,145 @@
+package sample
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewWeightedReservoir(t *testing.T) {
+	t.Run("pass: valid WeightedReservoir is valid", func(t *testing.T) {
+		r, err := NewWeightedReservoir[string](3, AExpJ, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, fmt.Sprintf("sample.WeightedReservoir_{size:3,algorithm:%d}", AExpJ), r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewWeightedReservoir[int](-1, ARes, nil)
+		testutil.ContainsError(t, err, "-1 is a nonpositive size")
+	})
+
+	t.Run("fail: invalid algorithm returns error", func(t *testing.T) {
+		_, err := NewWeightedReservoir[int](3, -1, nil)
+		testutil.ContainsError(t, err, "-1 is not a valid WeightedAlgorithm")
+	})
+}
+
+func TestWeightedReservoirPush(t *testing.T) {
+	for _, algorithm := range []WeightedAlgorithm{ARes, AExpJ} {
+		t.Run(fmt.Sprintf("fail: invalid weights return error for algorithm %d", algorithm), func(t *testing.T) {
+			r, err := NewWeightedReservoir[int](3, algorithm, nil)
+			require.NoError(t, err)
+
+			for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+				err = r.Push(1, weight)
+				testutil.ContainsError(t, err, "is not a positive finite weight")
+			}
+			assert.Equal(t, 0, r.Count())
+		})
+	}
+}
+
+// inclusionProbabilities returns the probabilities of each value being included
+// in a weighted sample of size 2 drawn without replacement.
+func inclusionProbabilities(weights []float64) []float64 {
+	total := 0.
+	for _, w := range weights {
+		total += w
+	}
+
+	probabilities := make([]float64, len(weights))
+	for i, w := range weights {
+		probabilities[i] = w / total
+		for j, v := range weights {
+			if j != i {
+				probabilities[i] += v / total * w / (total - v)
+			}
+		}
+	}
+	return probabilities
+}
+
+func TestWeightedReservoirInclusionProbabilities(t *testing.T) {
+	weights := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	expected := inclusionProbabilities(weights)
+	trials := 20000
+
+	for _, algorithm := range []WeightedAlgorithm{ARes, AExpJ} {
+		t.Run(fmt.Sprintf("pass: inclusion probabilities are correct for algorithm %d", algorithm), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			counts := make([]int, len(weights))
+			for i := 0; i < trials; i++ {
+				r, err := NewWeightedReservoir[int](2, algorithm, rng)
+				require.NoError(t, err)
+
+				for j, w := range weights {
+					err = r.Push(j, w)
+					require.NoError(t, err)
+				}
+
+				sample := r.Sample()
+				require.Len(t, sample, 2)
+				for _, j := range sample {
+					counts[j]++
+				}
+			}
+
+			for j, count := range counts {
+				// allow for roughly 4 standard deviations of error
+				p := expected[j]
+				tolerance := 4 * math.Sqrt(p*(1-p)/float64(trials))
+				assert.InDelta(t, p, float64(count)/float64(trials), tolerance)
+			}
+		})
+	}
+}
+
+func TestWeightedReservoirSkewedWeights(t *testing.T) {
+	for _, algorithm := range []WeightedAlgorithm{ARes, AExpJ} {
+		t.Run(fmt.Sprintf("pass: heavy values are sampled for algorithm %d", algorithm), func(t *testing.T) {
+			r, err := NewWeightedReservoir[int](5, algorithm, rand.New(rand.NewSource(1)))
+			require.NoError(t, err)
+
+			// values 0-4 have a combined weight that dwarfs the weight of the other values
+			for i := 0; i < 10000; i++ {
+				weight := 1.
+				if i%2000 == 0 {
+					weight = 1e9
+				}
+				err = r.Push(i, weight)
+				require.NoError(t, err)
+			}
+
+			assert.ElementsMatch(t, []int{0, 2000, 4000, 6000, 8000}, r.Sample())
+			assert.Equal(t, 10000, r.Count())
+		})
+	}
+}
+
+func TestWeightedReservoirClear(t *testing.T) {
+	r, err := NewWeightedReservoir[int](2, AExpJ, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		err = r.Push(i, 1)
+		require.NoError(t, err)
+	}
+
+	r.Clear()
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, []int{}, r.Sample())
+	assert.Equal(t, 0., r.remaining)
+
+	err = r.Push(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, r.Sample())
+}
@@ -0,0 +1,239 @@
+package sample
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/alexander-yu/stream"
+)
+
+// candidate is a value that may be sampled by a WindowReservoir, along with its
+// random priority, its position in the stream and the time it was pushed at.
+type candidate[T any] struct {
+	val       T
+	priority  float64
+	index     int
+	t         time.Time
+	dominated int
+}
+
+// WindowReservoir is a struct for performing uniform sampling over a rolling
+// window of a stream, either of the last n values or of the values seen over the
+// last duration of time, with the priority sampling algorithm of Babcock, Datar
+// and Motwani. Each value is assigned a random priority, and the sample consists
+// of the values in the window with the highest priorities; values are discarded
+// once enough later values have higher priorities that they can never be sampled.
+// It satisfies the Sampler interface.
+type WindowReservoir[T any] struct {
+	size       int
+	window     int
+	duration   time.Duration
+	clock      stream.Clock
+	candidates []candidate[T]
+	count      int
+	latest     time.Time
+	rand       *rand.Rand
+	mux        sync.Mutex
+}
+
+// NewWindowReservoir instantiates a WindowReservoir struct that keeps a sample
+// of at most size values over the last window values. Samples are drawn with
+// the provided random number generator, which defaults to one seeded with the
+// current time if nil.
+func NewWindowReservoir[T any](size int, window int, rng *rand.Rand) (*WindowReservoir[T], error) {
+	if window <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive window", window)
+	}
+
+	return newWindowReservoir[T](size, window, 0, nil, rng)
+}
+
+// NewTimedWindowReservoir instantiates a WindowReservoir struct that keeps a
+// sample of at most size values over a time-based window, where values are
+// removed once they are at least the provided duration older than the current
+// time of the provided Clock, which defaults to stream.SystemClock if nil.
+// Values pushed without an explicit time are timestamped with the Clock as well.
+func NewTimedWindowReservoir[T any](
+	size int,
+	duration time.Duration,
+	clock stream.Clock,
+	rng *rand.Rand,
+) (*WindowReservoir[T], error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("%v is a nonpositive duration", duration)
+	}
+
+	if clock == nil {
+		clock = stream.SystemClock
+	}
+
+	return newWindowReservoir[T](size, 0, duration, clock, rng)
+}
+
+func newWindowReservoir[T any](
+	size int,
+	window int,
+	duration time.Duration,
+	clock stream.Clock,
+	rng *rand.Rand,
+) (*WindowReservoir[T], error) {
+	if size <= 0 {
+		return nil, errors.Errorf("%d is a nonpositive size", size)
+	}
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &WindowReservoir[T]{
+		size:     size,
+		window:   window,
+		duration: duration,
+		clock:    clock,
+		rand:     rng,
+	}, nil
+}
+
+// String returns a string representation of the sampler.
+func (r *WindowReservoir[T]) String() string {
+	name := "sample.WindowReservoir"
+	params := []string{
+		fmt.Sprintf("size:%d", r.size),
+		fmt.Sprintf("window:%d", r.window),
+	}
+	if r.duration != 0 {
+		params = append(params, fmt.Sprintf("duration:%v", r.duration))
+	}
+	return fmt.Sprintf("%s_{%s}", name, strings.Join(params, ","))
+}
+
+// Size returns the maximum size of the sample.
+func (r *WindowReservoir[T]) Size() int {
+	return r.size
+}
+
+// Push consumes a value to perform sampling. If the WindowReservoir samples
+// over a time-based window, the value is timestamped with the current time
+// of its Clock.
+func (r *WindowReservoir[T]) Push(x T) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var t time.Time
+	if r.duration != 0 {
+		t = r.clock.Now()
+	}
+	return r.push(x, t)
+}
+
+// PushAt consumes a value to perform sampling, which was observed at the provided
+// time. This is only meaningful if the WindowReservoir samples over a time-based
+// window (see NewTimedWindowReservoir), in which case values at or before
+// t - duration are removed from the window; otherwise the time is ignored.
+// Values must be pushed in chronological order, on the timeline of the Clock
+// (see stream.Clock).
+func (r *WindowReservoir[T]) PushAt(x T, t time.Time) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.push(x, t)
+}
+
+func (r *WindowReservoir[T]) push(x T, t time.Time) error {
+	if r.duration != 0 {
+		if t.Before(r.latest) {
+			return errors.Errorf("time %v is before the latest time %v", t, r.latest)
+		}
+		r.expire(t)
+		r.latest = t
+	}
+
+	r.count++
+	priority := r.rand.Float64()
+
+	// remove the candidates that have left a count-based window, as well as
+	// those that now have at least size later values with higher priorities
+	candidates := r.candidates[:0]
+	for _, c := range r.candidates {
+		if r.window != 0 && c.index <= r.count-r.window {
+			continue
+		}
+
+		if priority > c.priority {
+			c.dominated++
+			if c.dominated >= r.size {
+				continue
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	r.candidates = append(candidates, candidate[T]{
+		val:      x,
+		priority: priority,
+		index:    r.count,
+		t:        t,
+	})
+	return nil
+}
+
+// expire removes the candidates at or before t - duration from a time-based window.
+func (r *WindowReservoir[T]) expire(t time.Time) {
+	cutoff := t.Add(-r.duration)
+	candidates := r.candidates[:0]
+	for _, c := range r.candidates {
+		if c.t.After(cutoff) {
+			candidates = append(candidates, c)
+		}
+	}
+	r.candidates = candidates
+}
+
+// Sample returns a copied slice of the obtained sample of the values in the window,
+// in no particular order. If the WindowReservoir samples over a time-based window,
+// expired values are removed first.
+func (r *WindowReservoir[T]) Sample() []T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.duration != 0 {
+		r.expire(r.clock.Now())
+	}
+
+	candidates := make([]candidate[T], len(r.candidates))
+	copy(candidates, r.candidates)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].priority > candidates[j].priority
+	})
+
+	if len(candidates) > r.size {
+		candidates = candidates[:r.size]
+	}
+
+	sample := make([]T, len(candidates))
+	for i, c := range candidates {
+		sample[i] = c.val
+	}
+	return sample
+}
+
+// Count returns the number of values seen by the sampler.
+func (r *WindowReservoir[T]) Count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.count
+}
+
+// Clear resets the sampler.
+func (r *WindowReservoir[T]) Clear() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.candidates = nil
+	r.count = 0
+	r.latest = time.Time{}
+}
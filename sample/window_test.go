@@ -0,0 +1,166 @@
+package sample
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/alexander-yu/stream/util/test"
+)
+
+func TestNewWindowReservoir(t *testing.T) {
+	t.Run("pass: valid WindowReservoir is valid", func(t *testing.T) {
+		r, err := NewWindowReservoir[int](3, 10, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.Size())
+		assert.Equal(t, 0, r.Count())
+		assert.NotNil(t, r.rand)
+		assert.Equal(t, "sample.WindowReservoir_{size:3,window:10}", r.String())
+	})
+
+	t.Run("fail: nonpositive size returns error", func(t *testing.T) {
+		_, err := NewWindowReservoir[int](0, 10, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive size")
+	})
+
+	t.Run("fail: nonpositive window returns error", func(t *testing.T) {
+		_, err := NewWindowReservoir[int](3, 0, nil)
+		testutil.ContainsError(t, err, "0 is a nonpositive window")
+	})
+}
+
+func TestNewTimedWindowReservoir(t *testing.T) {
+	t.Run("pass: valid WindowReservoir is valid", func(t *testing.T) {
+		r, err := NewTimedWindowReservoir[int](3, time.Minute, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, r.duration)
+		assert.NotNil(t, r.clock)
+		assert.Equal(t, "sample.WindowReservoir_{size:3,window:0,duration:1m0s}", r.String())
+	})
+
+	t.Run("fail: nonpositive duration returns error", func(t *testing.T) {
+		_, err := NewTimedWindowReservoir[int](3, -time.Second, nil, nil)
+		testutil.ContainsError(t, err, "-1s is a nonpositive duration")
+	})
+}
+
+func TestWindowReservoirPushIsUniform(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]int, 30)
+	trials := 10000
+	for i := 0; i < trials; i++ {
+		r, err := NewWindowReservoir[int](3, 10, rng)
+		require.NoError(t, err)
+
+		for j := 0; j < 30; j++ {
+			err = r.Push(j)
+			require.NoError(t, err)
+		}
+
+		sample := r.Sample()
+		require.Len(t, sample, 3)
+		for _, x := range sample {
+			counts[x]++
+		}
+	}
+
+	// only the last 10 values should be sampled, each with probability 3/10
+	for x, count := range counts {
+		if x < 20 {
+			assert.Equal(t, 0, count)
+		} else {
+			assert.InDelta(t, 0.3, float64(count)/float64(trials), 0.02)
+		}
+	}
+}
+
+func TestWindowReservoirCandidatesAreBounded(t *testing.T) {
+	r, err := NewWindowReservoir[int](10, 100000, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	for i := 0; i < 100000; i++ {
+		err = r.Push(i)
+		require.NoError(t, err)
+	}
+
+	// the expected number of candidates is O(k log(n/k)), i.e. far less than n
+	assert.True(t, len(r.candidates) < 1000)
+	assert.Len(t, r.Sample(), 10)
+	assert.Equal(t, 100000, r.Count())
+}
+
+func TestWindowReservoirPushAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("pass: values older than duration are removed", func(t *testing.T) {
+		clock := testutil.NewClock(start.Add(14 * time.Second))
+		r, err := NewTimedWindowReservoir[int](5, 10*time.Second, clock, rand.New(rand.NewSource(1)))
+		require.NoError(t, err)
+
+		offsets := []int{0, 2, 4, 10, 12, 14}
+		for i, offset := range offsets {
+			err = r.PushAt(i, start.Add(time.Duration(offset)*time.Second))
+			require.NoError(t, err)
+		}
+
+		assert.ElementsMatch(t, []int{3, 4, 5}, r.Sample())
+	})
+
+	t.Run("pass: Push uses the provided clock", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		r, err := NewTimedWindowReservoir[int](5, time.Minute, clock, rand.New(rand.NewSource(1)))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			err = r.Push(i)
+			require.NoError(t, err)
+			clock.Advance(30 * time.Second)
+		}
+
+		assert.Equal(t, []int{2}, r.Sample())
+	})
+
+	t.Run("pass: expired values are removed on read", func(t *testing.T) {
+		clock := testutil.NewClock(start)
+		r, err := NewTimedWindowReservoir[int](5, time.Minute, clock, rand.New(rand.NewSource(1)))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			err = r.Push(i)
+			require.NoError(t, err)
+		}
+		assert.ElementsMatch(t, []int{0, 1, 2}, r.Sample())
+
+		clock.Advance(time.Hour)
+		assert.Empty(t, r.Sample())
+		assert.Equal(t, 3, r.Count())
+	})
+
+	t.Run("fail: values must be pushed in chronological order", func(t *testing.T) {
+		r, err := NewTimedWindowReservoir[int](5, time.Minute, nil, nil)
+		require.NoError(t, err)
+
+		err = r.PushAt(1, start)
+		require.NoError(t, err)
+
+		err = r.PushAt(2, start.Add(-time.Second))
+		testutil.ContainsError(t, err, "is before the latest time")
+	})
+}
+
+func TestWindowReservoirClear(t *testing.T) {
+	r, err := NewWindowReservoir[int](3, 10, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		err = r.Push(i)
+		require.NoError(t, err)
+	}
+
+	r.Clear()
+	assert.Equal(t, 0, r.Count())
+	assert.Equal(t, []int{}, r.Sample())
+}
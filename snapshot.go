@@ -0,0 +1,11 @@
+package stream
+
+// Snapshot is a point-in-time snapshot of the values of an aggregate metric,
+// all of which reflect the same set of observations. Values maps the string
+// representation of each metric to its value, and Count is the number of
+// observations that the values reflect when the snapshot was taken; see the
+// Snapshot method of each aggregate metric for how this is counted.
+type Snapshot struct {
+	Values map[string]float64
+	Count  int
+}
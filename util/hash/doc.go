@@ -0,0 +1,3 @@
+// Package hash is a helper library for hashing values deterministically,
+// e.g. for sketches whose state needs to be merged across processes.
+package hash
@@ -0,0 +1,29 @@
+package hash
+
+const (
+	fnvOffset uint64 = 14695981039346656037
+	fnvPrime  uint64 = 1099511628211
+)
+
+// Bytes returns the 64-bit hash of a string or byte slice, which is the FNV-1a
+// hash of its bytes with its bits mixed by Mix.
+func Bytes[S string | []byte](s S) uint64 {
+	x := fnvOffset
+	for i := 0; i < len(s); i++ {
+		x ^= uint64(s[i])
+		x *= fnvPrime
+	}
+	return Mix(x)
+}
+
+// Mix is the finalizer of MurmurHash3, which ensures that every bit of the input
+// affects every bit of the output, so that any subset of the bits of the hash
+// (e.g. its leading bits) is uniformly distributed.
+func Mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
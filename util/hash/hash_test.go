@@ -0,0 +1,27 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, Bytes("stream"), Bytes([]byte("stream")))
+	assert.NotEqual(t, Bytes("stream"), Bytes("streams"))
+	assert.Equal(t, Mix(14695981039346656037), Bytes(""))
+}
+
+func TestMix(t *testing.T) {
+	assert.Equal(t, uint64(0), Mix(0))
+
+	// flipping a single bit of the input flips roughly half of the bits of the output
+	for i := uint(0); i < 64; i++ {
+		diff := Mix(1<<i) ^ Mix(0)
+		ones := 0
+		for ; diff != 0; diff &= diff - 1 {
+			ones++
+		}
+		assert.InDelta(t, 32, ones, 16)
+	}
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a manually controlled clock for testing metrics
+// that track values over a time-based window.
+type Clock struct {
+	now time.Time
+	mux sync.Mutex
+}
+
+// NewClock instantiates a Clock set to the provided time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the provided duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.now = c.now.Add(d)
+}